@@ -0,0 +1,74 @@
+package dagg
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Algorithm is a named analysis that can be run against a graph through Run.
+// Parameters and results are untyped so that algorithms can be exposed
+// generically, for example through a CLI or HTTP layer.
+type Algorithm[T Hashable] interface {
+	Run(g *AcyclicGraph[T], params map[string]interface{}) (interface{}, error)
+}
+
+// AlgorithmFunc is an adapter to allow the use of an ordinary function as
+// an Algorithm.
+type AlgorithmFunc[T Hashable] func(g *AcyclicGraph[T], params map[string]interface{}) (interface{}, error)
+
+// Run calls f(g, params).
+func (f AlgorithmFunc[T]) Run(g *AcyclicGraph[T], params map[string]interface{}) (interface{}, error) {
+	return f(g, params)
+}
+
+var (
+	algorithmsLock sync.RWMutex
+	algorithms     = make(map[string]interface{})
+)
+
+// RegisterAlgorithm registers an Algorithm under the given name, so it can
+// be called through Run. It is an error to register the same name twice.
+func RegisterAlgorithm[T Hashable](name string, a Algorithm[T]) error {
+	algorithmsLock.Lock()
+	defer algorithmsLock.Unlock()
+
+	if _, ok := algorithms[name]; ok {
+		return fmt.Errorf("algorithm %q already registered", name)
+	}
+	algorithms[name] = a
+	return nil
+}
+
+// Algorithms returns the sorted names of all registered algorithms.
+func Algorithms() []string {
+	algorithmsLock.RLock()
+	defer algorithmsLock.RUnlock()
+
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run runs the named algorithm against g. An error is returned if no
+// algorithm is registered under that name, or if the registered algorithm
+// does not handle vertices of type T.
+func Run[T Hashable](g *AcyclicGraph[T], name string, params map[string]interface{}) (interface{}, error) {
+	algorithmsLock.RLock()
+	raw, ok := algorithms[name]
+	algorithmsLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm %q", name)
+	}
+
+	a, ok := raw.(Algorithm[T])
+	if !ok {
+		var v T
+		return nil, fmt.Errorf("algorithm %q does not support vertices of type %T", name, v)
+	}
+
+	return a.Run(g, params)
+}
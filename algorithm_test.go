@@ -0,0 +1,43 @@
+package dagg
+
+import (
+	"testing"
+)
+
+func TestRunAlgorithm(t *testing.T) {
+	count := AlgorithmFunc[myint](func(g *AcyclicGraph[myint], params map[string]interface{}) (interface{}, error) {
+		return len(g.Vertices()) + params["extra"].(int), nil
+	})
+	if err := RegisterAlgorithm[myint]("test-count", count); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer func() {
+		algorithmsLock.Lock()
+		delete(algorithms, "test-count")
+		algorithmsLock.Unlock()
+	}()
+	if err := RegisterAlgorithm[myint]("test-count", count); err == nil {
+		t.Fatal("should error on duplicate registration")
+	}
+
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+
+	actual, err := Run(&g, "test-count", map[string]interface{}{"extra": 1})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual != 3 {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if _, err := Run(&g, "test-missing", nil); err == nil {
+		t.Fatal("should error on unknown algorithm")
+	}
+
+	var other AcyclicGraph[mystr]
+	if _, err := Run(&other, "test-count", nil); err == nil {
+		t.Fatal("should error on wrong vertex type")
+	}
+}
@@ -0,0 +1,262 @@
+package dagg
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+// bitmapMagic starts every serialized ReachabilityBitmap, along with the
+// version of the format.
+const bitmapMagic = "DAGGRB\x01\n"
+
+// ReachabilityBitmap is a precomputed index of which vertices can reach
+// which, by hashcode. It answers reachability queries without the graph, so
+// it can be built once, written alongside the graph with WriteTo, and
+// loaded by services which only query it with ReadReachabilityBitmap.
+//
+// The bitmap is a snapshot, and isn't updated when the graph changes. Hash
+// returns the ContentHash of the graph it was built from, so a stale
+// bitmap can be detected.
+type ReachabilityBitmap struct {
+	hash     string
+	hashcode []string
+	ids      map[string]int
+
+	// rows holds a bit for every vertex reachable from each vertex, in
+	// words of 64 vertices.
+	rows [][]uint64
+}
+
+// ReachabilityBitmap computes the ReachabilityBitmap of the graph. An error
+// is returned if the graph contains a cycle.
+//
+// Complexity: O(V*(V+E)/64) time, and O(V^2/64) space at most
+func (g *AcyclicGraph[T]) ReachabilityBitmap() (*ReachabilityBitmap, error) {
+	idx := g.index()
+	order, ok := idx.topological()
+	if !ok {
+		return nil, fmt.Errorf("graph contains a cycle")
+	}
+
+	// renumber the vertices by hashcode, so the bitmap of a graph is the
+	// same however it was built
+	byName := make([]int, len(idx.vertices))
+	for i := range byName {
+		byName[i] = i
+	}
+	sort.Slice(byName, func(i, j int) bool {
+		return idx.vertices[byName[i]].Hashcode() < idx.vertices[byName[j]].Hashcode()
+	})
+	id := make([]int, len(byName))
+	b := &ReachabilityBitmap{
+		hash:     g.ContentHash(),
+		hashcode: make([]string, len(byName)),
+		ids:      make(map[string]int, len(byName)),
+	}
+	for i, v := range byName {
+		id[v] = i
+		b.hashcode[i] = idx.vertices[v].Hashcode()
+		b.ids[b.hashcode[i]] = i
+	}
+
+	down := make([][]int, len(byName))
+	for u, targets := range idx.down {
+		for _, v := range targets {
+			down[id[u]] = append(down[id[u]], id[v])
+		}
+	}
+
+	// the targets of a vertex come after it in the order, so visit them
+	// first
+	reverse := make([]int, len(order))
+	for i, u := range order {
+		reverse[len(order)-1-i] = id[u]
+	}
+	b.rows = reachRows(reverse, down)
+	return b, nil
+}
+
+// reachRows returns a bitset for each vertex of the vertices which can be
+// reached from it by following adj. The vertices are visited in order,
+// which must have every vertex after those it leads to, so their rows are
+// ready.
+//
+// Complexity: O(V*(V+E)/64)
+func reachRows(order []int, adj [][]int) [][]uint64 {
+	words := (len(adj) + 63) / 64
+	rows := make([][]uint64, len(adj))
+	for _, u := range order {
+		row := make([]uint64, words)
+		for _, v := range adj[u] {
+			row[v/64] |= 1 << (uint(v) % 64)
+			for w, word := range rows[v] {
+				row[w] |= word
+			}
+		}
+		rows[u] = row
+	}
+	return rows
+}
+
+// Hash returns the ContentHash of the graph the bitmap was built from.
+func (b *ReachabilityBitmap) Hash() string {
+	return b.hash
+}
+
+// Len returns the number of vertices in the bitmap.
+func (b *ReachabilityBitmap) Len() int {
+	return len(b.hashcode)
+}
+
+// Reachable returns true if the vertex with the hashcode to can be reached
+// from the vertex with the hashcode from.
+//
+// Complexity: O(1)
+func (b *ReachabilityBitmap) Reachable(from, to string) (bool, error) {
+	i, ok := b.ids[from]
+	if !ok {
+		return false, fmt.Errorf("vertex %q not found", from)
+	}
+	j, ok := b.ids[to]
+	if !ok {
+		return false, fmt.Errorf("vertex %q not found", to)
+	}
+	return b.rows[i][j/64]&(1<<(uint(j)%64)) != 0, nil
+}
+
+// ReachableFrom returns the sorted hashcodes of the vertices which can be
+// reached from the vertex with the given hashcode.
+func (b *ReachabilityBitmap) ReachableFrom(from string) ([]string, error) {
+	i, ok := b.ids[from]
+	if !ok {
+		return nil, fmt.Errorf("vertex %q not found", from)
+	}
+
+	var result []string
+	for w, word := range b.rows[i] {
+		for word != 0 {
+			j := w*64 + bits.TrailingZeros64(word)
+			result = append(result, b.hashcode[j])
+			word &= word - 1
+		}
+	}
+	return result, nil
+}
+
+// WriteTo writes the bitmap to w. Only the non-zero words of each row are
+// written, so the bitmaps of sparse graphs stay small.
+func (b *ReachabilityBitmap) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	var buf [binary.MaxVarintLen64]byte
+	putUvarint := func(x uint64) {
+		n := binary.PutUvarint(buf[:], x)
+		bw.Write(buf[:n])
+	}
+	putString := func(s string) {
+		putUvarint(uint64(len(s)))
+		bw.WriteString(s)
+	}
+
+	bw.WriteString(bitmapMagic)
+	putString(b.hash)
+	putUvarint(uint64(len(b.hashcode)))
+	for _, h := range b.hashcode {
+		putString(h)
+	}
+	for _, row := range b.rows {
+		n := 0
+		for _, word := range row {
+			if word != 0 {
+				n++
+			}
+		}
+		putUvarint(uint64(n))
+
+		// each word is written with the distance from the previous one
+		last := 0
+		for w, word := range row {
+			if word != 0 {
+				putUvarint(uint64(w - last))
+				putUvarint(word)
+				last = w
+			}
+		}
+	}
+
+	// a bufio.Writer keeps the first error, so it's returned by Flush
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ReadReachabilityBitmap reads a bitmap written by WriteTo.
+func ReadReachabilityBitmap(r io.Reader) (*ReachabilityBitmap, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(bitmapMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != bitmapMagic {
+		return nil, errors.New("not a reachability bitmap")
+	}
+
+	readString := func() (string, error) {
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return "", err
+		}
+		s := make([]byte, n)
+		_, err = io.ReadFull(br, s)
+		return string(s), err
+	}
+
+	b := &ReachabilityBitmap{}
+	var err error
+	if b.hash, err = readString(); err != nil {
+		return nil, err
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	b.hashcode = make([]string, count)
+	b.ids = make(map[string]int, count)
+	for i := range b.hashcode {
+		if b.hashcode[i], err = readString(); err != nil {
+			return nil, err
+		}
+		b.ids[b.hashcode[i]] = i
+	}
+
+	words := (len(b.hashcode) + 63) / 64
+	b.rows = make([][]uint64, count)
+	for i := range b.rows {
+		b.rows[i] = make([]uint64, words)
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		w := 0
+		for ; n > 0; n-- {
+			delta, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, err
+			}
+			word, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, err
+			}
+			w += int(delta)
+			if w >= words {
+				return nil, fmt.Errorf("row %d: word %d out of range", i, w)
+			}
+			b.rows[i][w] = word
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,120 @@
+package dagg
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestAcyclicGraphReachabilityBitmap(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 5; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(1), myint(4)))
+
+	b, err := g.ReachabilityBitmap()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if b.Hash() != g.ContentHash() {
+		t.Fatalf("bad: %s", b.Hash())
+	}
+
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{"1", "3", true},
+		{"1", "4", true},
+		{"2", "3", true},
+		{"3", "1", false},
+		{"4", "3", false},
+		{"5", "1", false},
+		{"1", "1", false},
+	}
+	for _, tc := range cases {
+		ok, err := b.Reachable(tc.from, tc.to)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if ok != tc.want {
+			t.Fatalf("%s to %s: bad: %v", tc.from, tc.to, ok)
+		}
+	}
+
+	actual, err := b.ReachableFrom("1")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(actual, []string{"2", "3", "4"}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if _, err := b.Reachable("1", "6"); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestReachabilityBitmap_roundTrip(t *testing.T) {
+	// enough vertices to need several words per row
+	var g AcyclicGraph[myint]
+	for i := 0; i < 150; i++ {
+		g.Add(myint(i))
+		if i > 0 && i%7 != 0 {
+			g.Connect(BasicEdge(myint(i-1), myint(i)))
+		}
+	}
+
+	b, err := g.ReachabilityBitmap()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := b.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("bad: %d", n)
+	}
+
+	loaded, err := ReadReachabilityBitmap(&buf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if loaded.Hash() != b.Hash() || loaded.Len() != 150 {
+		t.Fatalf("bad: %s %d", loaded.Hash(), loaded.Len())
+	}
+	for i := 0; i < 150; i++ {
+		from := fmt.Sprint(i)
+		want, _ := b.ReachableFrom(from)
+		actual, err := loaded.ReachableFrom(from)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !reflect.DeepEqual(actual, want) {
+			t.Fatalf("%s: bad: %#v", from, actual)
+		}
+	}
+
+	if _, err := ReadReachabilityBitmap(bytes.NewBufferString("1\n  2\n")); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestAcyclicGraphReachabilityBitmap_cycle(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+
+	if _, err := g.ReachabilityBitmap(); err == nil {
+		t.Fatal("expect error")
+	}
+}
@@ -0,0 +1,540 @@
+package dagg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// BuildTarget is a vertex of a graph loaded from a Makefile or a Ninja
+// file. Prerequisites without a rule of their own, such as source files,
+// are targets with no Commands.
+type BuildTarget struct {
+	Name string
+
+	// Commands are the recipe lines of a Makefile rule, or the command of a
+	// Ninja build statement, with the variables known to the parser
+	// expanded.
+	Commands []string
+
+	// Phony is set for the targets of .PHONY in a Makefile, and for Ninja
+	// build statements using the phony rule.
+	Phony bool
+}
+
+func (t *BuildTarget) Hashcode() string {
+	return t.Name
+}
+
+// buildGraph collects the targets of a build file, in the order they're
+// first seen.
+type buildGraph struct {
+	g       *AcyclicGraph[*BuildTarget]
+	targets map[string]*BuildTarget
+}
+
+func newBuildGraph() *buildGraph {
+	return &buildGraph{
+		g:       &AcyclicGraph[*BuildTarget]{},
+		targets: make(map[string]*BuildTarget),
+	}
+}
+
+func (b *buildGraph) target(name string) *BuildTarget {
+	t, ok := b.targets[name]
+	if !ok {
+		t = &BuildTarget{Name: name}
+		b.targets[name] = t
+		b.g.Add(t)
+	}
+	return t
+}
+
+// depend adds an edge from the target to each prerequisite, since the
+// target depends on them.
+func (b *buildGraph) depend(t *BuildTarget, prereqs []string) {
+	for _, p := range prereqs {
+		if p != t.Name {
+			b.g.Connect(BasicEdge(t, b.target(p)))
+		}
+	}
+}
+
+func (b *buildGraph) validate() (*AcyclicGraph[*BuildTarget], error) {
+	if err := b.g.Validate(); err != nil {
+		return nil, err
+	}
+	return b.g, nil
+}
+
+var makeVar = regexp.MustCompile(`\$\(([^()$:]+)\)|\$\{([^{}$:]+)\}`)
+
+// ParseMakefile builds a graph of the rules in a Makefile, with an edge
+// from each target to each of its prerequisites, including order-only
+// prerequisites. Walking the graph visits targets in the order make would
+// build them.
+//
+// Variables set with =, :=, ::= or ?= are expanded in targets,
+// prerequisites and recipes; += appends to them. Automatic variables such
+// as $@ and $<, and functions such as $(wildcard ...), are left as they
+// are. Pattern rules and special targets other than .PHONY are ignored, as
+// are directives such as include and ifeq.
+func ParseMakefile(r io.Reader) (*AcyclicGraph[*BuildTarget], error) {
+	b := newBuildGraph()
+	vars := make(map[string]string)
+	var phony []string
+
+	expand := func(s string) string {
+		// expand repeatedly for variables referring to variables, up to a
+		// limit so recursive definitions can't loop forever.
+		for i := 0; i < 10 && makeVar.MatchString(s); i++ {
+			next := makeVar.ReplaceAllStringFunc(s, func(ref string) string {
+				name := strings.TrimSpace(ref[2 : len(ref)-1])
+				if v, ok := vars[name]; ok {
+					return v
+				}
+				if strings.ContainsAny(name, " \t,") {
+					// a function call
+					return ref
+				}
+				return ""
+			})
+			if next == s {
+				break
+			}
+			s = next
+		}
+		return s
+	}
+
+	// current is the targets of the rule being read, if any. A recipe of
+	// an ignored rule has no targets, but isn't an error.
+	var current []*BuildTarget
+	var inRule bool
+	lines, err := makeLines(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		text := l.text
+
+		if strings.HasPrefix(text, "\t") {
+			if !inRule {
+				if strings.TrimSpace(text) == "" {
+					continue
+				}
+				return nil, fmt.Errorf("line %d: recipe before the first rule", l.line)
+			}
+			cmd := expand(strings.TrimSpace(text))
+			if cmd != "" {
+				for _, t := range current {
+					t.Commands = append(t.Commands, cmd)
+				}
+			}
+			continue
+		}
+
+		text = stripMakeComment(text)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		// variable assignments
+		if name, op, value, ok := makeAssignment(text); ok {
+			current, inRule = nil, false
+			name = expand(name)
+			switch op {
+			case "?=":
+				if _, ok := vars[name]; !ok {
+					vars[name] = value
+				}
+			case "+=":
+				if v := vars[name]; v != "" && value != "" {
+					vars[name] = v + " " + value
+				} else if v == "" {
+					vars[name] = value
+				}
+			case ":=", "::=":
+				vars[name] = expand(value)
+			default:
+				vars[name] = value
+			}
+			continue
+		}
+
+		colon := strings.Index(text, ":")
+		if colon < 0 {
+			// a directive, or something we can't make sense of
+			current, inRule = nil, false
+			continue
+		}
+
+		targets := strings.Fields(expand(text[:colon]))
+		rest := strings.TrimPrefix(text[colon+1:], ":")
+		if _, _, _, ok := makeAssignment(rest); ok {
+			// a target-specific variable
+			current, inRule = nil, true
+			continue
+		}
+
+		// an inline recipe follows a semicolon
+		var inline string
+		if i := strings.Index(rest, ";"); i >= 0 {
+			rest, inline = rest[:i], strings.TrimSpace(rest[i+1:])
+		}
+		prereqs := strings.Fields(strings.Replace(expand(rest), "|", " ", 1))
+
+		current, inRule = nil, true
+		for _, name := range targets {
+			switch {
+			case name == ".PHONY":
+				phony = append(phony, prereqs...)
+				continue
+			case strings.HasPrefix(name, "."), strings.Contains(name, "%"):
+				continue
+			}
+
+			t := b.target(name)
+			b.depend(t, prereqs)
+			if inline != "" {
+				t.Commands = append(t.Commands, expand(inline))
+			}
+			current = append(current, t)
+		}
+	}
+
+	for _, name := range phony {
+		if t, ok := b.targets[name]; ok {
+			t.Phony = true
+		}
+	}
+
+	return b.validate()
+}
+
+// buildLine is a logical line of a build file, numbered by its first
+// physical line.
+type buildLine struct {
+	line int
+	text string
+}
+
+// makeLines reads the logical lines of a Makefile, joining lines ending in
+// a backslash.
+func makeLines(r io.Reader) ([]buildLine, error) {
+	var lines []buildLine
+	var cont *buildLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if cont != nil {
+			cont.text += " " + strings.TrimLeft(text, " \t")
+		} else {
+			lines = append(lines, buildLine{line: n, text: text})
+			cont = &lines[len(lines)-1]
+		}
+
+		if strings.HasSuffix(cont.text, "\\") {
+			cont.text = strings.TrimRight(strings.TrimSuffix(cont.text, "\\"), " \t")
+		} else {
+			cont = nil
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// stripMakeComment removes a trailing comment, unless the # is escaped.
+func stripMakeComment(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && (i == 0 || s[i-1] != '\\') {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+var makeAssign = regexp.MustCompile(`^\s*(?:export\s+|override\s+)?([^:#=\s]+)\s*(::=|:=|\?=|\+=|!=|=)\s*(.*)$`)
+
+// makeAssignment parses a variable assignment line.
+func makeAssignment(s string) (name, op, value string, ok bool) {
+	m := makeAssign.FindStringSubmatch(s)
+	if m == nil {
+		return "", "", "", false
+	}
+	return m[1], m[2], strings.TrimSpace(m[3]), true
+}
+
+// ParseNinja builds a graph of the build statements in a Ninja file, with
+// an edge from each output to each of its explicit, implicit and
+// order-only inputs. The first output of a build statement is given the
+// expanded command of its rule; any other outputs depend on the first,
+// so that walking the graph runs each command once.
+//
+// Variables are expanded from the build statement, the rule and the top
+// level of the file, along with $in and $out. The include and subninja
+// statements aren't followed.
+func ParseNinja(r io.Reader) (*AcyclicGraph[*BuildTarget], error) {
+	b := newBuildGraph()
+	globals := make(map[string]string)
+	rules := make(map[string]map[string]string)
+
+	lines, err := ninjaLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		if strings.HasPrefix(l.text, " ") || strings.HasPrefix(l.text, "\t") {
+			return nil, fmt.Errorf("line %d: unexpected indent", l.line)
+		}
+
+		// the indented variables following a statement
+		var scope []buildLine
+		for i+1 < len(lines) && (strings.HasPrefix(lines[i+1].text, " ") || strings.HasPrefix(lines[i+1].text, "\t")) {
+			i++
+			scope = append(scope, lines[i])
+		}
+
+		keyword, rest, _ := strings.Cut(l.text, " ")
+		switch keyword {
+		case "rule":
+			vars := make(map[string]string)
+			for _, s := range scope {
+				name, value, ok := ninjaAssignment(s.text)
+				if !ok {
+					return nil, fmt.Errorf("line %d: expected a variable", s.line)
+				}
+				// rule variables are expanded when used
+				vars[name] = value
+			}
+			rules[strings.TrimSpace(rest)] = vars
+
+		case "build":
+			if err := ninjaBuild(b, l, scope, globals, rules); err != nil {
+				return nil, err
+			}
+
+		case "default", "pool", "include", "subninja":
+
+		default:
+			name, value, ok := ninjaAssignment(l.text)
+			if !ok {
+				return nil, fmt.Errorf("line %d: unexpected %q", l.line, keyword)
+			}
+			globals[name] = ninjaExpand(value, ninjaLookup(globals))
+		}
+	}
+
+	return b.validate()
+}
+
+// ninjaBuild adds the targets of a build statement.
+func ninjaBuild(b *buildGraph, l buildLine, scope []buildLine, globals map[string]string, rules map[string]map[string]string) error {
+	outs, ins, ok := ninjaCut(strings.TrimPrefix(l.text, "build "), ':')
+	if !ok {
+		return fmt.Errorf("line %d: build statement without a rule", l.line)
+	}
+
+	outputs := ninjaPaths(outs, ninjaLookup(globals))
+	if len(outputs) == 0 {
+		return fmt.Errorf("line %d: build statement without an output", l.line)
+	}
+
+	inputs := ninjaPaths(ins, ninjaLookup(globals))
+	if len(inputs) == 0 {
+		return fmt.Errorf("line %d: build statement without a rule", l.line)
+	}
+	rule, inputs := inputs[0], inputs[1:]
+
+	// $in is only the explicit inputs
+	var explicit []string
+	for _, in := range inputs {
+		if in == "|" || in == "||" || in == "|@" {
+			break
+		}
+		explicit = append(explicit, in)
+	}
+	var prereqs []string
+	for _, in := range inputs {
+		if in != "|" && in != "||" && in != "|@" {
+			prereqs = append(prereqs, in)
+		}
+	}
+	var outputsOnly []string
+	for _, out := range outputs {
+		if out != "|" {
+			outputsOnly = append(outputsOnly, out)
+		}
+	}
+
+	ruleVars, ok := rules[rule]
+	if !ok && rule != "phony" {
+		return fmt.Errorf("line %d: unknown rule %q", l.line, rule)
+	}
+
+	// variables are looked up in the build statement, then the rule, then
+	// the top level of the file. Rule variables are expanded where they're
+	// used, with a limit in case they refer to each other.
+	vars := map[string]string{
+		"in":  strings.Join(explicit, " "),
+		"out": strings.Join(outputsOnly, " "),
+	}
+	depth := 0
+	var lookup func(string) string
+	lookup = func(name string) string {
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		if v, ok := ruleVars[name]; ok && depth < 10 {
+			depth++
+			defer func() { depth-- }()
+			return ninjaExpand(v, lookup)
+		}
+		return globals[name]
+	}
+	for _, s := range scope {
+		name, value, ok := ninjaAssignment(s.text)
+		if !ok {
+			return fmt.Errorf("line %d: expected a variable", s.line)
+		}
+		vars[name] = ninjaExpand(value, lookup)
+	}
+
+	first := b.target(outputsOnly[0])
+	first.Phony = rule == "phony"
+	if cmd := lookup("command"); cmd != "" {
+		first.Commands = append(first.Commands, cmd)
+	}
+	b.depend(first, prereqs)
+	for _, out := range outputsOnly[1:] {
+		b.depend(b.target(out), []string{first.Name})
+	}
+	return nil
+}
+
+// ninjaLines reads the logical lines of a Ninja file, joining lines ending
+// in $ and dropping comments and blank lines.
+func ninjaLines(r io.Reader) ([]buildLine, error) {
+	var lines []buildLine
+	var cont *buildLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if cont != nil {
+			cont.text += strings.TrimLeft(text, " ")
+		} else {
+			trimmed := strings.TrimSpace(text)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			lines = append(lines, buildLine{line: n, text: text})
+			cont = &lines[len(lines)-1]
+		}
+
+		// a line continues if it ends in an odd number of $
+		dollars := len(cont.text) - len(strings.TrimRight(cont.text, "$"))
+		if dollars%2 == 1 {
+			cont.text = cont.text[:len(cont.text)-1]
+		} else {
+			cont = nil
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// ninjaAssignment parses a "name = value" line.
+func ninjaAssignment(s string) (string, string, bool) {
+	name, value, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t$:") {
+		return "", "", false
+	}
+	return name, strings.TrimLeft(value, " \t"), true
+}
+
+// ninjaCut splits s at the first unescaped sep.
+func ninjaCut(s string, sep byte) (string, string, bool) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '$':
+			i++
+		case sep:
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+// ninjaPaths splits s into paths at unescaped spaces, expanding each one.
+// The | and || separators are returned as paths of their own.
+func ninjaPaths(s string, lookup func(string) string) []string {
+	var paths []string
+	start := -1
+	for i := 0; i <= len(s); i++ {
+		if i == len(s) || s[i] == ' ' {
+			if start >= 0 {
+				paths = append(paths, ninjaExpand(s[start:i], lookup))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		if s[i] == '$' {
+			i++
+		}
+	}
+	return paths
+}
+
+// ninjaLookup looks variables up in vars.
+func ninjaLookup(vars map[string]string) func(string) string {
+	return func(name string) string {
+		return vars[name]
+	}
+}
+
+// ninjaExpand expands the variables and escapes in s.
+func ninjaExpand(s string, lookup func(string) string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch c := s[i]; {
+		case c == '$' || c == ' ' || c == ':':
+			b.WriteByte(c)
+		case c == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				b.WriteString(s[i-1:])
+				return b.String()
+			}
+			b.WriteString(lookup(s[i+1 : i+end]))
+			i += end
+		default:
+			end := i
+			for end < len(s) && (s[end] == '_' || s[end] == '-' ||
+				'a' <= s[end] && s[end] <= 'z' || 'A' <= s[end] && s[end] <= 'Z' || '0' <= s[end] && s[end] <= '9') {
+				end++
+			}
+			if end == i {
+				b.WriteByte('$')
+				b.WriteByte(c)
+				continue
+			}
+			b.WriteString(lookup(s[i:end]))
+			i = end - 1
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,208 @@
+package dagg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMakefile(t *testing.T) {
+	g, err := ParseMakefile(strings.NewReader(testMakefileStr))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testMakefileGraphStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	targets := make(map[string]*BuildTarget)
+	for _, v := range g.Vertices() {
+		targets[v.Name] = v
+	}
+
+	expectedCmds := []string{"cc -O2 -o $@ main.o util.o", "strip app"}
+	if cmds := targets["app"].Commands; !reflect.DeepEqual(cmds, expectedCmds) {
+		t.Fatalf("bad: %#v", cmds)
+	}
+	if cmds := targets["main.o"].Commands; !reflect.DeepEqual(cmds, []string{"cc -O2 -c main.c"}) {
+		t.Fatalf("bad: %#v", cmds)
+	}
+	if !targets["all"].Phony || !targets["clean"].Phony || targets["app"].Phony {
+		t.Fatal("bad phony targets")
+	}
+	if len(targets["main.c"].Commands) != 0 {
+		t.Fatalf("bad: %#v", targets["main.c"].Commands)
+	}
+}
+
+func TestParseMakefile_invalid(t *testing.T) {
+	cases := map[string]string{
+		"recipe": "\techo hi\n",
+		"cycle":  "a: b\nb: a\n",
+	}
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseMakefile(strings.NewReader(src)); err == nil {
+				t.Fatal("should error")
+			}
+		})
+	}
+}
+
+func TestParseNinja(t *testing.T) {
+	g, err := ParseNinja(strings.NewReader(testNinjaStr))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testNinjaGraphStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	targets := make(map[string]*BuildTarget)
+	for _, v := range g.Vertices() {
+		targets[v.Name] = v
+	}
+
+	cases := map[string]string{
+		"main.o":       "cc -O2 -Iinclude -c main.c -o main.o",
+		"util.o":       "cc -O0 -Iinclude -c util.c -o util.o",
+		"app":          "cc main.o util.o -o app app.map",
+		"my file.o":    "cc -O2 -Iinclude -c my file.c -o my file.o",
+		"all":          "",
+		"app.map":      "",
+		"gen/config.h": "",
+	}
+	for name, cmd := range cases {
+		tgt, ok := targets[name]
+		if !ok {
+			t.Fatalf("missing %q", name)
+		}
+		actual := strings.Join(tgt.Commands, "\n")
+		if actual != cmd {
+			t.Fatalf("bad %q: %q", name, actual)
+		}
+	}
+	if !targets["all"].Phony || targets["app"].Phony {
+		t.Fatal("bad phony targets")
+	}
+}
+
+func TestParseNinja_invalid(t *testing.T) {
+	cases := map[string]string{
+		"rule":   "build a: missing b\n",
+		"indent": "  x = 1\n",
+		"output": "rule r\n  command = x\nbuild : r a\n",
+		"cycle":  "rule r\n  command = x\nbuild a: r b\nbuild b: r a\n",
+	}
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseNinja(strings.NewReader(src)); err == nil {
+				t.Fatal("should error")
+			}
+		})
+	}
+}
+
+const testMakefileStr = `
+CC = cc
+CFLAGS := -O2
+OBJS = main.o \
+	util.o
+OBJS += # nothing extra
+
+.PHONY: all clean
+
+all: app
+
+app: $(OBJS) | build-dir
+	$(CC) $(CFLAGS) -o $@ $(OBJS)
+	strip app
+
+main.o: main.c util.h ; $(CC) ${CFLAGS} -c main.c
+util.o: util.c util.h
+	$(CC) $(CFLAGS) -c util.c
+
+app: CFLAGS = -O3
+%.o: %.c
+	$(CC) -c $<
+
+build-dir:
+	mkdir -p build
+
+clean:
+	rm -f app $(OBJS)
+`
+
+const testMakefileGraphStr = `
+all
+  app
+app
+  build-dir
+  main.o
+  util.o
+build-dir
+clean
+main.c
+main.o
+  main.c
+  util.h
+util.c
+util.h
+util.o
+  util.c
+  util.h
+`
+
+const testNinjaStr = `
+# the compiler flags
+cflags = -O2
+incs = -Iinclude
+
+rule cc
+  command = cc $cflags $incs -c $in -o $out
+  description = CC $out
+
+rule link
+  command = cc $in -o $out
+
+build main.o: cc main.c || gen/config.h
+build util.o: cc util.c | util.h
+  cflags = -O0
+build my$ file.o: cc my$ file.c
+build app | app.map: link main.o util.o
+build gen/config.h: phony
+build all: phony app $
+    my$ file.o
+
+default all
+`
+
+const testNinjaGraphStr = `
+all
+  app
+  my file.o
+app
+  main.o
+  util.o
+app.map
+  app
+gen/config.h
+main.c
+main.o
+  gen/config.h
+  main.c
+my file.c
+my file.o
+  my file.c
+util.c
+util.h
+util.o
+  util.c
+  util.h
+`
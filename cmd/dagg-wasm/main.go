@@ -0,0 +1,66 @@
+//go:build js && wasm
+
+// Command dagg-wasm exposes the jsonapi package to JavaScript when built
+// for WebAssembly:
+//
+//	GOOS=js GOARCH=wasm go build -o dagg.wasm ./cmd/dagg-wasm
+//
+// Once loaded with wasm_exec.js, it sets a global "dagg" object with the
+// functions validate, order and walk. Each takes a graph as a JSON string,
+// in the form of jsonapi.Graph, and returns a jsonapi.Result as a JSON
+// string. walk also takes a function which is called with the name of each
+// vertex, and fails the vertex if it returns a string or throws.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"syscall/js"
+
+	"github.com/streemtech/dagg/jsonapi"
+)
+
+func main() {
+	js.Global().Set("dagg", js.ValueOf(map[string]interface{}{
+		"validate": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return string(jsonapi.Validate([]byte(arg(args, 0))))
+		}),
+		"order": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return string(jsonapi.Order([]byte(arg(args, 0))))
+		}),
+		"walk": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			if len(args) < 2 || args[1].Type() != js.TypeFunction {
+				return `{"error":"walk needs a graph and a function"}`
+			}
+			return string(jsonapi.Walk([]byte(arg(args, 0)), callback(args[1])))
+		}),
+	}))
+
+	// keep the functions available to JavaScript
+	select {}
+}
+
+// arg returns the string argument i, or "" if it wasn't given.
+func arg(args []js.Value, i int) string {
+	if i >= len(args) || args[i].Type() != js.TypeString {
+		return ""
+	}
+	return args[i].String()
+}
+
+// callback adapts a JavaScript function to a walk callback.
+func callback(fn js.Value) func(string) error {
+	return func(name string) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+
+		result := fn.Invoke(name)
+		if result.Type() == js.TypeString && result.String() != "" {
+			return errors.New(result.String())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+package dagg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Transformer is a single lowering step which modifies a graph in place.
+type Transformer[T Hashable] interface {
+	Transform(g *AcyclicGraph[T]) error
+}
+
+// TransformerFunc is an adapter to allow the use of an ordinary function as
+// a Transformer.
+type TransformerFunc[T Hashable] func(g *AcyclicGraph[T]) error
+
+// Transform calls f(g).
+func (f TransformerFunc[T]) Transform(g *AcyclicGraph[T]) error {
+	return f(g)
+}
+
+// Pass is a named Transformer, with optional checks run before and after
+// the transformation.
+type Pass[T Hashable] struct {
+	Name        string
+	Transformer Transformer[T]
+
+	// Before and After are called with the graph before and after the
+	// Transformer runs. Either may be nil.
+	Before func(g *AcyclicGraph[T]) error
+	After  func(g *AcyclicGraph[T]) error
+}
+
+// Compiler lowers a graph through a sequence of registered passes. Each
+// pass sees the output of the previous one, and the graph is checked for
+// cycles after every pass. Only cycles are an error between passes, so a
+// pass may leave the graph empty, or without roots, for a later pass to
+// fill in. FanOutPass, BarrierPass and ReductionPass are common passes.
+type Compiler[T Hashable] struct {
+	passes []Pass[T]
+}
+
+// Register appends a pass to the compiler.
+func (c *Compiler[T]) Register(p Pass[T]) {
+	c.passes = append(c.passes, p)
+}
+
+// Compile runs every registered pass in order over a copy of g, returning
+// the lowered graph. The input graph is never modified.
+func (c *Compiler[T]) Compile(g *AcyclicGraph[T]) (*AcyclicGraph[T], error) {
+	result := g.Copy()
+
+	for _, p := range c.passes {
+		if p.Before != nil {
+			if err := p.Before(result); err != nil {
+				return nil, fmt.Errorf("pass %q: before: %w", p.Name, err)
+			}
+		}
+
+		if err := p.Transformer.Transform(result); err != nil {
+			return nil, fmt.Errorf("pass %q: %w", p.Name, err)
+		}
+
+		if err := result.Validate(); err != nil {
+			var cErr *CycleError[T]
+			if errors.As(err, &cErr) {
+				return nil, fmt.Errorf("pass %q: invalid graph: %w", p.Name, err)
+			}
+		}
+
+		if p.After != nil {
+			if err := p.After(result); err != nil {
+				return nil, fmt.Errorf("pass %q: after: %w", p.Name, err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ReductionPass returns a Pass which performs a transitive reduction of the
+// graph.
+func ReductionPass[T Hashable]() Pass[T] {
+	return Pass[T]{
+		Name: "reduction",
+		Transformer: TransformerFunc[T](func(g *AcyclicGraph[T]) error {
+			g.TransitiveReduction()
+			return nil
+		}),
+	}
+}
+
+// FanOutPass returns a Pass which replaces each vertex for which expand
+// returns vertices by those vertices, to run in parallel. Each of them
+// depends on the dependencies of the vertex it replaces, and the vertices
+// which depended on it depend on each of them. Vertices for which expand
+// returns nothing are left as they are.
+func FanOutPass[T Hashable](expand func(v T) []T) Pass[T] {
+	return Pass[T]{
+		Name: "fan-out",
+		Transformer: TransformerFunc[T](func(g *AcyclicGraph[T]) error {
+			for _, v := range g.Vertices() {
+				shards := expand(v)
+				if len(shards) == 0 {
+					continue
+				}
+
+				deps, dependents := g.DownEdges(v), g.UpEdges(v)
+				g.Remove(v)
+				for _, shard := range shards {
+					g.Add(shard)
+					for _, dep := range deps {
+						g.Connect(BasicEdge(shard, dep))
+					}
+					for _, dependent := range dependents {
+						g.Connect(BasicEdge(dependent, shard))
+					}
+				}
+			}
+			return nil
+		}),
+	}
+}
+
+// BarrierPass returns a Pass which inserts a barrier before each vertex for
+// which barrier returns true: the vertex depends on the barrier alone, and
+// the barrier on every former dependency of the vertex. Vertices given the
+// same barrier share it, so it waits for all of their dependencies.
+func BarrierPass[T Hashable](barrier func(v T) (T, bool)) Pass[T] {
+	return Pass[T]{
+		Name: "barrier",
+		Transformer: TransformerFunc[T](func(g *AcyclicGraph[T]) error {
+			for _, v := range g.Vertices() {
+				b, ok := barrier(v)
+				if !ok {
+					continue
+				}
+
+				g.Add(b)
+				for _, dep := range g.DownEdges(v) {
+					g.RemoveEdge(BasicEdge(v, dep))
+					g.Connect(BasicEdge(b, dep))
+				}
+				g.Connect(BasicEdge(v, b))
+			}
+			return nil
+		}),
+	}
+}
@@ -0,0 +1,161 @@
+package dagg
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCompiler(t *testing.T) {
+	var g AcyclicGraph[mystr]
+	g.Add(mystr("a"))
+	g.Add(mystr("b"))
+	g.Connect(BasicEdge(mystr("b"), mystr("a")))
+
+	var c Compiler[mystr]
+
+	// expand b into two parallel shards
+	c.Register(Pass[mystr]{
+		Name: "fan-out",
+		Transformer: TransformerFunc[mystr](func(g *AcyclicGraph[mystr]) error {
+			for _, i := range []string{"1", "2"} {
+				shard := g.Add(mystr("b" + i))
+				g.Connect(BasicEdge(shard, mystr("a")))
+				g.Connect(BasicEdge(mystr("b"), shard))
+			}
+			return nil
+		}),
+		After: func(g *AcyclicGraph[mystr]) error {
+			if len(g.Vertices()) != 4 {
+				return fmt.Errorf("expected 4 vertices")
+			}
+			return nil
+		},
+	})
+	c.Register(ReductionPass[mystr]())
+
+	lowered, err := c.Compile(&g)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(lowered.String())
+	expected := strings.TrimSpace(testCompilerStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	if len(g.Vertices()) != 2 {
+		t.Fatalf("input graph modified: %s", g.String())
+	}
+}
+
+func TestCompiler_invalid(t *testing.T) {
+	var g AcyclicGraph[mystr]
+	g.Add(mystr("a"))
+	g.Add(mystr("b"))
+
+	var c Compiler[mystr]
+	c.Register(Pass[mystr]{
+		Name: "cycle",
+		Transformer: TransformerFunc[mystr](func(g *AcyclicGraph[mystr]) error {
+			g.Connect(BasicEdge(mystr("a"), mystr("b")))
+			g.Connect(BasicEdge(mystr("b"), mystr("a")))
+			return nil
+		}),
+	})
+
+	_, err := c.Compile(&g)
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), `pass "cycle"`) {
+		t.Fatalf("bad error: %s", err)
+	}
+}
+
+func TestCompiler_empty(t *testing.T) {
+	var g AcyclicGraph[mystr]
+	g.Add(mystr("a"))
+
+	// a pass may leave the graph empty for the next to fill in
+	var c Compiler[mystr]
+	c.Register(Pass[mystr]{
+		Name: "clear",
+		Transformer: TransformerFunc[mystr](func(g *AcyclicGraph[mystr]) error {
+			g.Remove(mystr("a"))
+			return nil
+		}),
+	})
+	c.Register(Pass[mystr]{
+		Name: "fill",
+		Transformer: TransformerFunc[mystr](func(g *AcyclicGraph[mystr]) error {
+			g.Add(mystr("b"))
+			return nil
+		}),
+	})
+
+	lowered, err := c.Compile(&g)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := strings.TrimSpace(lowered.String()); actual != "b" {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestCompiler_fanOutBarrier(t *testing.T) {
+	var g AcyclicGraph[mystr]
+	g.Add(mystr("a"))
+	g.Add(mystr("b"))
+	g.Add(mystr("c"))
+	g.Connect(BasicEdge(mystr("b"), mystr("a")))
+	g.Connect(BasicEdge(mystr("c"), mystr("b")))
+
+	// b is run as two shards, and c waits for both behind a barrier
+	var c Compiler[mystr]
+	c.Register(FanOutPass(func(v mystr) []mystr {
+		if v == "b" {
+			return []mystr{"b1", "b2"}
+		}
+		return nil
+	}))
+	c.Register(BarrierPass(func(v mystr) (mystr, bool) {
+		return v + "-barrier", v == "c"
+	}))
+
+	lowered, err := c.Compile(&g)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(lowered.String())
+	expected := strings.TrimSpace(testCompilerFanOutBarrierStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+const testCompilerStr = `
+a
+b
+  b1
+  b2
+b1
+  a
+b2
+  a
+`
+
+const testCompilerFanOutBarrierStr = `
+a
+b1
+  a
+b2
+  a
+c
+  c-barrier
+c-barrier
+  b1
+  b2
+`
@@ -0,0 +1,359 @@
+package dagg
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"sync"
+)
+
+// WalkOpts are the options for AcyclicGraph.WalkWithOpts.
+type WalkOpts[T Hashable] struct {
+	// MaxConcurrency, if greater than zero, is the number of callbacks
+	// which may run at once.
+	MaxConcurrency int
+
+	// Priority orders the vertices waiting for one of the MaxConcurrency
+	// places, as Walker.Priority.
+	Priority func(v T) int
+
+	// Sweep removes the expired edges from the graph before the walk, as
+	// Sweep. Otherwise the graph isn't modified.
+	Sweep bool
+}
+
+// WalkWithOpts walks the graph like Walk, with the given options. A nil
+// opts is the same as the zero WalkOpts.
+func (g *AcyclicGraph[T]) WalkWithOpts(cb WalkFunc[T], opts *WalkOpts[T]) error {
+	if opts == nil {
+		opts = &WalkOpts[T]{}
+	}
+
+	if opts.Sweep {
+		g.Sweep()
+	}
+	w := &Walker[T]{
+		Callback:       cb,
+		Reverse:        true,
+		MaxConcurrency: opts.MaxConcurrency,
+		Priority:       opts.Priority,
+	}
+	w.Update(g)
+	return w.Wait()
+}
+
+// acquireSlot blocks until fewer than MaxConcurrency vertices are running,
+// and no vertex with a higher priority is waiting, then takes a place. The
+// returned func gives the place back.
+func (w *Walker[T]) acquireSlot(v T) func() {
+	if w.slots == nil {
+		return func() {}
+	}
+	key := w.slotKey(v)
+	w.slots.acquire(key, w.priority(v))
+	return func() { w.slots.release(key) }
+}
+
+// heldPlaces are the places a running vertex holds: its place under
+// MaxConcurrency and its places in its concurrency groups. They're given up
+// while the vertex waits, for a SubWalk or after yielding to a preempting
+// vertex, and taken back in the same order they were first taken.
+type heldPlaces struct {
+	acquire func() func()
+
+	lock    sync.Mutex
+	held    bool
+	release func()
+}
+
+// take waits for the places, and takes them.
+func (p *heldPlaces) take() {
+	release := p.acquire()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.release = release
+	p.held = true
+}
+
+// give gives the places back. It returns false if they were already given
+// back.
+func (p *heldPlaces) give() bool {
+	p.lock.Lock()
+	if !p.held {
+		p.lock.Unlock()
+		return false
+	}
+	p.held = false
+	release := p.release
+	p.lock.Unlock()
+
+	release()
+	return true
+}
+
+// takePlaces takes the places of v, waiting until there is room under
+// MaxConcurrency and in each of its concurrency groups, and records them
+// until givePlaces.
+func (w *Walker[T]) takePlaces(v T) *heldPlaces {
+	groups := vertexGroups(v)
+	places := &heldPlaces{acquire: func() func() {
+		releaseSlot := w.acquireSlot(v)
+		release := acquireGroups(groups)
+		return func() {
+			release()
+			releaseSlot()
+		}
+	}}
+	places.take()
+
+	w.placesLock.Lock()
+	defer w.placesLock.Unlock()
+	if w.places == nil {
+		w.places = make(map[string]*heldPlaces)
+	}
+	w.places[v.Hashcode()] = places
+	return places
+}
+
+// givePlaces gives back the places of v once it has finished.
+func (w *Walker[T]) givePlaces(v T, places *heldPlaces) {
+	w.placesLock.Lock()
+	delete(w.places, v.Hashcode())
+	w.placesLock.Unlock()
+	places.give()
+}
+
+// runningPlaces returns the places of v, or nil if v isn't running.
+func (w *Walker[T]) runningPlaces(v T) *heldPlaces {
+	w.placesLock.Lock()
+	defer w.placesLock.Unlock()
+	return w.places[v.Hashcode()]
+}
+
+// slotKey returns the key of the place v holds in the walk of w.
+func (w *Walker[T]) slotKey(v T) slotKey {
+	return slotKey{walker: w, vertex: v.Hashcode()}
+}
+
+// executePreemptible executes v, running it again each time it yields its
+// place to a waiting vertex under the Preemption policy. yield gives up the
+// places v holds, and waits until it has them again.
+func (w *Walker[T]) executePreemptible(v T, yield func()) error {
+	if w.slots == nil || w.Preemption == nil || !w.hasContext(v) {
+		return w.executeLabeled(context.Background(), v)
+	}
+
+	key := w.slotKey(v)
+	for preemptions := 0; ; preemptions++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		preempted := make(chan struct{})
+		w.slots.preemptible(key, preemptions, func() {
+			close(preempted)
+			cancel()
+		})
+		err := w.executeLabeled(ctx, v)
+		w.slots.preemptible(key, preemptions, nil)
+		cancel()
+
+		select {
+		case <-preempted:
+		default:
+			return err
+		}
+		if err == nil {
+			// finished before it could yield
+			return nil
+		}
+
+		log.Printf("[TRACE] dagg/walk: %q yielded its place to a higher priority vertex", VertexName(v))
+		w.logEvent(WalkEventPreempted, v, "", err)
+		w.errLock.Lock()
+		if w.preemptions == nil {
+			w.preemptions = make(map[string]int)
+		}
+		w.preemptions[v.Hashcode()]++
+		w.errLock.Unlock()
+		yield()
+	}
+}
+
+// hasContext returns true if v is run with a context, which is needed for
+// it to be preempted.
+func (w *Walker[T]) hasContext(v T) bool {
+	var raw interface{}
+	raw = v
+	if _, ok := raw.(ExternalVertex); ok {
+		return true
+	}
+	return len(w.Executors) == 0 && w.HeartbeatCallback != nil
+}
+
+// priority returns the Priority of v, or 0 if there is no Priority.
+func (w *Walker[T]) priority(v T) int {
+	if w.Priority == nil {
+		return 0
+	}
+	return w.Priority(v)
+}
+
+// slotPool is a counting semaphore which hands out places by priority.
+// The holder of each place is recorded, so a place can only be given back
+// by the vertex which holds it.
+type slotPool struct {
+	lock    sync.Mutex
+	limit   int
+	running int
+	held    map[slotKey]*slotHolder
+	waiting slotWaiters
+	seq     uint64
+
+	// preemption is the policy for asking holders to yield their places
+	// to waiters, and yielding the number of holders which have been asked
+	// but haven't yet given their places back.
+	preemption *PreemptionPolicy
+	yielding   int
+}
+
+// slotHolder is a vertex holding a place, which has yielded its place
+// preemptions times before.
+type slotHolder struct {
+	priority    int
+	preemptions int
+
+	// preempt, if set, asks the vertex to yield its place. It's cleared
+	// once called, and asked is set.
+	preempt func()
+	asked   bool
+}
+
+// slotKey identifies a vertex of a walk holding a place. Walks of the same
+// pool may have vertices with the same hashcode, so the walker is part of
+// the key.
+type slotKey struct {
+	walker interface{}
+	vertex string
+}
+
+// acquire blocks until a place is free and no waiter has a higher
+// priority, then takes it for key.
+func (p *slotPool) acquire(key slotKey, priority int) {
+	p.lock.Lock()
+	if p.held == nil {
+		p.held = make(map[slotKey]*slotHolder)
+	}
+	if p.running < p.limit && len(p.waiting) == 0 {
+		p.running++
+		p.held[key] = &slotHolder{priority: priority}
+		p.lock.Unlock()
+		return
+	}
+
+	p.seq++
+	ready := make(chan struct{})
+	heap.Push(&p.waiting, &slotWaiter{key: key, priority: priority, seq: p.seq, ready: ready})
+	p.preempt()
+	p.lock.Unlock()
+	<-ready
+}
+
+// release gives back the place held by key, handing it straight to the
+// first waiter if there is one. It returns false, and does nothing, if key
+// doesn't hold a place.
+func (p *slotPool) release(key slotKey) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	h, ok := p.held[key]
+	if !ok {
+		return false
+	}
+	delete(p.held, key)
+	if h.asked {
+		p.yielding--
+	}
+
+	if len(p.waiting) > 0 {
+		next := heap.Pop(&p.waiting).(*slotWaiter)
+		p.held[next.key] = &slotHolder{priority: next.priority}
+		close(next.ready)
+		return true
+	}
+	p.running--
+	return true
+}
+
+// preemptible sets the func which asks the holder of key, which has been
+// preempted the given number of times already, to yield its place, or
+// clears it if preempt is nil. A waiter may already be owed a place, so the
+// holder may be asked at once.
+func (p *slotPool) preemptible(key slotKey, preemptions int, preempt func()) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	h, ok := p.held[key]
+	if !ok {
+		return
+	}
+	h.preempt = preempt
+	h.preemptions = preemptions
+	if preempt != nil {
+		p.preempt()
+	}
+}
+
+// preempt asks the preemptible holder with the lowest priority to yield its
+// place, if the first waiter's priority is far enough above it under the
+// policy, and more vertices are waiting than holders have been asked
+// already. The lock must be held.
+func (p *slotPool) preempt() {
+	if p.preemption == nil || len(p.waiting) <= p.yielding {
+		return
+	}
+
+	top := p.waiting[0].priority
+	var lowest *slotHolder
+	for _, h := range p.held {
+		if h.preempt == nil || !p.preemption.allows(h.priority, top, h.preemptions) {
+			continue
+		}
+		if lowest == nil || h.priority < lowest.priority {
+			lowest = h
+		}
+	}
+	if lowest == nil {
+		return
+	}
+
+	lowest.preempt()
+	lowest.preempt = nil
+	lowest.asked = true
+	p.yielding++
+}
+
+// slotWaiter is a vertex waiting for a place.
+type slotWaiter struct {
+	key      slotKey
+	priority int
+	seq      uint64
+	ready    chan struct{}
+}
+
+// slotWaiters is a heap of waiters, highest priority first, and then in
+// the order they started waiting.
+type slotWaiters []*slotWaiter
+
+func (h slotWaiters) Len() int { return len(h) }
+func (h slotWaiters) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h slotWaiters) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *slotWaiters) Push(x interface{}) { *h = append(*h, x.(*slotWaiter)) }
+func (h *slotWaiters) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
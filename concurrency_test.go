@@ -0,0 +1,324 @@
+package dagg
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcyclicGraphWalkWithOpts_maxConcurrency(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 0; i < 10; i++ {
+		g.Add(myint(i))
+	}
+
+	var lock sync.Mutex
+	running, max, count := 0, 0, 0
+	cb := func(v myint) error {
+		lock.Lock()
+		running++
+		count++
+		if running > max {
+			max = running
+		}
+		lock.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+		return nil
+	}
+
+	if err := g.WalkWithOpts(cb, &WalkOpts[myint]{MaxConcurrency: 2}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if count != 10 {
+		t.Fatalf("bad: %d", count)
+	}
+	if max > 2 {
+		t.Fatalf("bad: %d running at once", max)
+	}
+
+	// nil opts are the zero WalkOpts
+	count = 0
+	if err := g.WalkWithOpts(cb, nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if count != 10 {
+		t.Fatalf("bad: %d", count)
+	}
+}
+
+func TestAcyclicGraphWalkWithOpts_priority(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 4; i++ {
+		g.Add(myint(i))
+	}
+
+	// the first vertex holds the only place long enough for the rest to
+	// queue up for it
+	var lock sync.Mutex
+	var order []myint
+	cb := func(v myint) error {
+		lock.Lock()
+		first := len(order) == 0
+		order = append(order, v)
+		lock.Unlock()
+		if first {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return nil
+	}
+	opts := &WalkOpts[myint]{
+		MaxConcurrency: 1,
+		Priority:       func(v myint) int { return int(v) },
+	}
+	if err := g.WalkWithOpts(cb, opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for i := 2; i < len(order); i++ {
+		if order[i] > order[i-1] {
+			t.Fatalf("bad: %#v", order)
+		}
+	}
+}
+
+func TestWalker_maxConcurrencySubWalk(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+
+	var nested AcyclicGraph[myint]
+	nested.Add(myint(10))
+	nested.Add(myint(11))
+
+	var lock sync.Mutex
+	running, max := 0, 0
+	var w *Walker[myint]
+	w = &Walker[myint]{
+		Reverse:        true,
+		MaxConcurrency: 1,
+		Callback: func(v myint) error {
+			if v == 1 {
+				return w.SubWalk(v, &nested)
+			}
+
+			lock.Lock()
+			running++
+			if running > max {
+				max = running
+			}
+			lock.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			lock.Lock()
+			running--
+			lock.Unlock()
+			return nil
+		},
+	}
+	w.Update(&g)
+
+	// the nested vertices can only run because 1 gives up its place
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if max > 1 {
+		t.Fatalf("bad: %d running at once", max)
+	}
+}
+
+func TestWalker_priority(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 5; i++ {
+		g.Add(myint(i))
+	}
+
+	var w *Walker[myint]
+	var lock sync.Mutex
+	var order []myint
+	cb := func(v myint) error {
+		lock.Lock()
+		first := len(order) == 0
+		order = append(order, v)
+		lock.Unlock()
+
+		// hold the only place until every other vertex is waiting for it
+		for first {
+			w.slots.lock.Lock()
+			waiting := len(w.slots.waiting)
+			w.slots.lock.Unlock()
+			if waiting == 4 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return nil
+	}
+
+	w = &Walker[myint]{
+		Callback:       cb,
+		Reverse:        true,
+		MaxConcurrency: 1,
+		Priority:       func(v myint) int { return int(v) },
+	}
+	w.Update(&g)
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// after the first, the rest run highest priority first
+	var expected []myint
+	for i := 5; i >= 1; i-- {
+		if i != int(order[0]) {
+			expected = append(expected, myint(i))
+		}
+	}
+	if !reflect.DeepEqual(order[1:], expected) {
+		t.Fatalf("bad: %#v", order)
+	}
+}
+
+func TestWalker_preemption(t *testing.T) {
+	// walk runs 1 until it is preempted or released, and each of the other
+	// vertices once 1 has started as many times as the vertex is above 2,
+	// so 2 is ready once 1 first starts, and 3 once 1 starts again. 1 is
+	// released once the vertex ready last is waiting for its place, or has
+	// been given it already by 1 yielding.
+	walk := func(n int, policy *PreemptionPolicy) ([]myint, *Walker[myint], *bytes.Buffer) {
+		var g AcyclicGraph[myint]
+		started := make([]chan struct{}, n)
+		for i := 1; i <= n; i++ {
+			g.Add(myint(i))
+			started[i-1] = make(chan struct{})
+		}
+		release := make(chan struct{})
+
+		var lock sync.Mutex
+		var order []myint
+		var starts int
+		var buf bytes.Buffer
+		var w *Walker[myint]
+		w = &Walker[myint]{
+			Reverse:        true,
+			MaxConcurrency: 1,
+			Preemption:     policy,
+			EventLog:       &buf,
+			Priority: func(v myint) int {
+				return int(v) * 10
+			},
+			BeforeStart: func(v myint) (time.Duration, bool) {
+				if v > 1 {
+					<-started[v-2]
+				}
+				return 0, true
+			},
+			HeartbeatCallback: func(ctx context.Context, v myint, hb *Heartbeat) error {
+				lock.Lock()
+				order = append(order, v)
+				if v == 1 {
+					close(started[starts])
+					starts++
+				}
+				lock.Unlock()
+				if v != 1 {
+					return nil
+				}
+
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-release:
+					return nil
+				}
+			},
+		}
+		w.Update(&g)
+
+		last := myint(n)
+		for !testWaiting(w, last) {
+			lock.Lock()
+			ran := false
+			for _, v := range order {
+				ran = ran || v == last
+			}
+			lock.Unlock()
+			if ran {
+				break
+			}
+			runtime.Gosched()
+		}
+		close(release)
+		if err := w.Wait(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return order, w, &buf
+	}
+
+	// 1 yields to 2, and then runs again
+	order, w, buf := walk(2, &PreemptionPolicy{})
+	if !reflect.DeepEqual(order, []myint{1, 2, 1}) {
+		t.Fatalf("bad: %#v", order)
+	}
+	r := w.Result()
+	if r.Vertices["1"].Preemptions != 1 || r.Vertices["2"].Preemptions != 0 {
+		t.Fatalf("bad: %#v", r.Vertices["1"])
+	}
+	l, err := ReadWalkLog(buf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if l.Vertices["1"].Preemptions != 1 {
+		t.Fatalf("bad: %#v", l.Vertices["1"])
+	}
+	if w.slots.running != 0 || w.slots.yielding != 0 {
+		t.Fatalf("bad: %#v", w.slots)
+	}
+
+	// 1 yields to 2, but runs to completion once 3 is waiting
+	order, w, _ = walk(3, &PreemptionPolicy{MaxPreemptions: 1})
+	if !reflect.DeepEqual(order, []myint{1, 2, 1, 3}) {
+		t.Fatalf("bad: %#v", order)
+	}
+	if w.Result().Vertices["1"].Preemptions != 1 {
+		t.Fatalf("bad: %#v", w.Result().Vertices["1"])
+	}
+
+	// the priority of 2 isn't far enough above that of 1
+	order, w, _ = walk(2, &PreemptionPolicy{MinGap: 20})
+	if !reflect.DeepEqual(order, []myint{1, 2}) {
+		t.Fatalf("bad: %#v", order)
+	}
+	if w.Result().Vertices["1"].Preemptions != 0 {
+		t.Fatal("should not preempt")
+	}
+
+	// without a policy nothing is preempted
+	order, _, _ = walk(2, nil)
+	if !reflect.DeepEqual(order, []myint{1, 2}) {
+		t.Fatalf("bad: %#v", order)
+	}
+}
+
+// testWaiting returns true if v is waiting for a place in the walk of w.
+func testWaiting(w *Walker[myint], v myint) bool {
+	w.slots.lock.Lock()
+	defer w.slots.lock.Unlock()
+	for _, waiter := range w.slots.waiting {
+		if waiter.key == w.slotKey(v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package dagg
+
+import (
+	"strings"
+)
+
+// Component is a strongly connected component of a graph, used as the
+// vertex of a condensed graph. The members are sorted by name.
+type Component[T Hashable] []T
+
+// Hashcode returns the hashcodes of the members, separated by commas.
+func (c Component[T]) Hashcode() string {
+	codes := make([]string, len(c))
+	for i, v := range c {
+		codes[i] = v.Hashcode()
+	}
+	return strings.Join(codes, ",")
+}
+
+// Name returns the names of the members, separated by commas.
+func (c Component[T]) Name() string {
+	names := make([]string, len(c))
+	for i, v := range c {
+		names[i] = VertexName(v)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Condense returns the condensation of g: a graph with a vertex for each
+// strongly connected component of g, and an edge between two components if
+// g has an edge between their members. Contracting every cycle into a
+// single vertex means the result is always acyclic.
+//
+// Since a slice can't implement Hashable, the components are returned as a
+// Component rather than a []T.
+//
+// Complexity: O(V+E)
+func Condense[T Hashable](g *Graph[T]) (*AcyclicGraph[Component[T]], error) {
+	result := &AcyclicGraph[Component[T]]{}
+
+	components := make(map[string]Component[T])
+	for _, scc := range StronglyConnected(g) {
+		c := make(Component[T], len(scc))
+		copy(c, scc)
+		g.sortByName(c)
+
+		result.Add(c)
+		for _, v := range c {
+			components[v.Hashcode()] = c
+		}
+	}
+
+	for _, e := range g.Edges() {
+		source, ok := components[e.Source().Hashcode()]
+		if !ok {
+			continue
+		}
+		target, ok := components[e.Target().Hashcode()]
+		if !ok {
+			continue
+		}
+		if source.Hashcode() == target.Hashcode() {
+			continue
+		}
+		result.Connect(BasicEdge(source, target))
+	}
+
+	return result, nil
+}
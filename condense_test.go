@@ -0,0 +1,42 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCondense(t *testing.T) {
+	var g Graph[myint]
+	for i := 1; i <= 5; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(2)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+	g.Connect(BasicEdge(myint(4), myint(5)))
+	g.Connect(BasicEdge(myint(5), myint(4)))
+	g.Connect(BasicEdge(myint(5), myint(5)))
+
+	c, err := Condense(&g)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(c.String())
+	expected := strings.TrimSpace(testCondenseStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+const testCondenseStr = `
+1
+  2, 3
+2, 3
+  4, 5
+4, 5
+`
@@ -0,0 +1,83 @@
+package dagg
+
+// WeightedVertex can be implemented by a vertex with a cost, such as the
+// time it takes to run, for CriticalPath.
+type WeightedVertex interface {
+	Weight() float64
+}
+
+// vertexWeight returns the Weight of a WeightedVertex, or 0.
+func vertexWeight[T Hashable](v T) float64 {
+	var raw interface{}
+	raw = v
+	if w, ok := raw.(WeightedVertex); ok {
+		return w.Weight()
+	}
+	return 0
+}
+
+// CriticalPath returns the path through the graph, following the direction
+// of the edges, with the greatest total cost, along with that cost. This is
+// the chain of vertices which bounds the time taken to run the whole graph.
+// If cost is nil, the Weight of each WeightedVertex is used, and other
+// vertices cost nothing. Ties are broken by vertex name.
+//
+// The path is returned in the direction of the edges, taking the source of
+// each edge to run before its target. In a graph where the source of an
+// edge depends on its target, as walked by a Walker with Reverse set, the
+// path starts with the vertex which runs last, so it should be reversed for
+// the order the vertices run in. The cost is the same either way.
+//
+// Complexity: O(V+E)
+func (g *AcyclicGraph[T]) CriticalPath(cost func(T) float64) ([]T, float64, error) {
+	if cost == nil {
+		cost = vertexWeight[T]
+	}
+
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := make(map[string]float64, len(order))
+	prev := make(map[string]T, len(order))
+	for _, u := range order {
+		total[u.Hashcode()] += cost(u)
+		for _, v := range g.downEdgesNoCopy(u) {
+			if !g.HasVertex(v) {
+				continue
+			}
+
+			d := total[u.Hashcode()]
+			current, ok := prev[v.Hashcode()]
+			if !ok || d > total[v.Hashcode()] ||
+				d == total[v.Hashcode()] && g.nameLess(VertexName(u), VertexName(current)) {
+				total[v.Hashcode()] = d
+				prev[v.Hashcode()] = u
+			}
+		}
+	}
+
+	var end T
+	var found bool
+	for _, v := range order {
+		t := total[v.Hashcode()]
+		if !found || t > total[end.Hashcode()] ||
+			t == total[end.Hashcode()] && g.nameLess(VertexName(v), VertexName(end)) {
+			end, found = v, true
+		}
+	}
+	if !found {
+		return nil, 0, nil
+	}
+
+	path := []T{end}
+	for {
+		p, ok := prev[path[0].Hashcode()]
+		if !ok {
+			break
+		}
+		path = append([]T{p}, path...)
+	}
+	return path, total[end.Hashcode()], nil
+}
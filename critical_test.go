@@ -0,0 +1,60 @@
+package dagg
+
+import (
+	"reflect"
+	"testing"
+)
+
+type weightedVertex struct {
+	name   string
+	weight float64
+}
+
+func (v weightedVertex) Hashcode() string { return v.name }
+func (v weightedVertex) Name() string     { return v.name }
+func (v weightedVertex) Weight() float64  { return v.weight }
+
+func TestAcyclicGraphCriticalPath(t *testing.T) {
+	var g AcyclicGraph[weightedVertex]
+	fetch := weightedVertex{"fetch", 2}
+	compile := weightedVertex{"compile", 10}
+	lint := weightedVertex{"lint", 3}
+	test := weightedVertex{"test", 5}
+	docs := weightedVertex{"docs", 1}
+	for _, v := range []weightedVertex{fetch, compile, lint, test, docs} {
+		g.Add(v)
+	}
+	g.Connect(BasicEdge(fetch, compile))
+	g.Connect(BasicEdge(fetch, lint))
+	g.Connect(BasicEdge(compile, test))
+	g.Connect(BasicEdge(lint, test))
+	g.Connect(BasicEdge(fetch, docs))
+
+	path, cost, err := g.CriticalPath(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(path, []weightedVertex{fetch, compile, test}) {
+		t.Fatalf("bad path: %#v", path)
+	}
+	if cost != 17 {
+		t.Fatalf("bad cost: %f", cost)
+	}
+
+	// with a callback making lint the slowest
+	path, cost, err = g.CriticalPath(func(v weightedVertex) float64 {
+		if v == lint {
+			return 20
+		}
+		return v.weight
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(path, []weightedVertex{fetch, lint, test}) {
+		t.Fatalf("bad path: %#v", path)
+	}
+	if cost != 27 {
+		t.Fatalf("bad cost: %f", cost)
+	}
+}
@@ -0,0 +1,114 @@
+package dagg
+
+// CycleReporter is implemented by errors caused by cycles in a graph, so
+// that the offending vertices and edges can be inspected programmatically.
+type CycleReporter[T Hashable] interface {
+	error
+
+	// VertexCycles returns the vertices of each cycle of more than one
+	// vertex. Self-loops are returned separately by SelfLoops.
+	VertexCycles() [][]T
+
+	// EdgeCycles returns a path of edges around each cycle, in the same order
+	// as VertexCycles. Removing any one edge of a path breaks that cycle,
+	// though a strongly connected component may contain more cycles than the
+	// one returned.
+	EdgeCycles() [][]Edge[T]
+
+	// SelfLoops returns the edges from a vertex to itself.
+	SelfLoops() []Edge[T]
+}
+
+// CycleError is wrapped by the ValidationError returned by Validate when the
+// graph contains cycles or self-loops. It wraps the complete validation
+// error.
+type CycleError[T Hashable] struct {
+	Err       error
+	cycles    [][]T
+	edges     [][]Edge[T]
+	selfLoops []Edge[T]
+}
+
+func (e *CycleError[T]) Error() string           { return e.Err.Error() }
+func (e *CycleError[T]) Unwrap() error           { return e.Err }
+func (e *CycleError[T]) VertexCycles() [][]T     { return e.cycles }
+func (e *CycleError[T]) EdgeCycles() [][]Edge[T] { return e.edges }
+func (e *CycleError[T]) SelfLoops() []Edge[T]    { return e.selfLoops }
+
+// ValidationError is the error returned by Validate, describing everything
+// that is wrong with the graph. Err is the complete validation error. When
+// the graph contains cycles, ValidationError wraps a *CycleError, so the
+// cycles can also be inspected through the CycleReporter interface with
+// errors.As.
+type ValidationError[T Hashable] struct {
+	Err error
+
+	cycles    [][]T
+	selfLoops []T
+	dangling  []Edge[T]
+	cycleErr  *CycleError[T]
+}
+
+func (e *ValidationError[T]) Error() string { return e.Err.Error() }
+
+func (e *ValidationError[T]) Unwrap() error {
+	if e.cycleErr != nil {
+		return e.cycleErr
+	}
+	return e.Err
+}
+
+// Cycles returns the vertices of each cycle of more than one vertex.
+func (e *ValidationError[T]) Cycles() [][]T { return e.cycles }
+
+// SelfLoops returns the vertices with an edge to themselves.
+func (e *ValidationError[T]) SelfLoops() []T { return e.selfLoops }
+
+// DanglingEdges returns the edges whose source or target was never added to
+// the graph, sorted by Hashcode. They are always returned, but are only an
+// error if ValidateOpts.DanglingEdges is set.
+func (e *ValidationError[T]) DanglingEdges() []Edge[T] { return e.dangling }
+
+// cyclePath returns the shortest path of edges from the first vertex of the
+// cycle back to itself, only following edges between members of the cycle.
+func (g *Graph[T]) cyclePath(cycle []T) []Edge[T] {
+	members := make(Set[T])
+	for _, v := range cycle {
+		members.Add(v)
+	}
+
+	start := cycle[0]
+	via := make(map[string]Edge[T])
+	queue := []T{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, e := range g.EdgesFrom(current) {
+			target := e.Target()
+			if !members.Include(target) {
+				continue
+			}
+			if _, seen := via[target.Hashcode()]; seen {
+				continue
+			}
+			via[target.Hashcode()] = e
+
+			if target.Hashcode() == start.Hashcode() {
+				// walk the path back to the start
+				var path []Edge[T]
+				for {
+					e := via[target.Hashcode()]
+					path = append([]Edge[T]{e}, path...)
+					target = e.Source()
+					if target.Hashcode() == start.Hashcode() {
+						return path
+					}
+				}
+			}
+			queue = append(queue, target)
+		}
+	}
+
+	return nil
+}
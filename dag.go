@@ -1,9 +1,14 @@
 package dagg
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/hashicorp/go-multierror"
 )
@@ -14,7 +19,7 @@ type AcyclicGraph[T Hashable] struct {
 }
 
 // WalkFunc is the callback used for walking the graph.
-type WalkFunc[T Hashable] func(T)
+type WalkFunc[T Hashable] func(T) error
 
 // DepthWalkFunc is a walk function that also receives the current depth of the
 // walk as an argument
@@ -24,50 +29,153 @@ func (g *AcyclicGraph[T]) DirectedGraph() Grapher {
 	return g
 }
 
+// Copy returns a copy of the graph. See Graph.Copy for details.
+func (g *AcyclicGraph[T]) Copy() *AcyclicGraph[T] {
+	return &AcyclicGraph[T]{*g.Graph.Copy()}
+}
+
+// ReadSnapshot returns a read-only snapshot of the graph. See
+// Graph.ReadSnapshot for details.
+func (g *AcyclicGraph[T]) ReadSnapshot() *AcyclicGraph[T] {
+	return &AcyclicGraph[T]{*g.Graph.ReadSnapshot()}
+}
+
 // Returns a Set that includes every Vertex yielded by walking down from the
 // provided starting Vertex v. Descendents will NOT include root vertexes that can be reached
 // by walking up from v.
 func (g *AcyclicGraph[T]) Descendents(v T) (Set[T], error) {
+	return g.reach([]T{v}, g.downEdges), nil
+}
+
+// Returns a Set that includes every Vertex yielded by walking up from the
+// provided starting Vertex v. Ancestors will include all root vertexes that can be reached
+// by walking up from v.
+func (g *AcyclicGraph[T]) Ancestors(v T) (Set[T], error) {
+	return g.reach([]T{v}, g.upEdges), nil
+}
+
+// DescendentsOf returns the combined descendents of every vertex in vs, as
+// the union of their Descendents. The subgraphs shared by the vertices are
+// only searched once. A vertex of vs is only included if it is a
+// descendent of another.
+func (g *AcyclicGraph[T]) DescendentsOf(vs ...T) (Set[T], error) {
+	return g.reach(vs, g.downEdges), nil
+}
+
+// AncestorsOf returns the combined ancestors of every vertex in vs, as the
+// union of their Ancestors. The subgraphs shared by the vertices are only
+// searched once. A vertex of vs is only included if it is an ancestor of
+// another.
+func (g *AcyclicGraph[T]) AncestorsOf(vs ...T) (Set[T], error) {
+	return g.reach(vs, g.upEdges), nil
+}
+
+// reach returns every vertex reached from the vertices of vs by following
+// the adjacency adj. Unlike a DepthFirstWalk it doesn't track depth or
+// order, and only pushes vertices which haven't been reached yet, so it is
+// much cheaper on dense graphs.
+func (g *AcyclicGraph[T]) reach(vs []T, adj map[int]idSet[T]) Set[T] {
 	s := make(Set[T])
-	memoFunc := func(v T, d int) error {
-		s.Add(v)
-		return nil
+	seen := make(map[int]struct{})
+	stack := g.interned(vs)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for id, t := range adj[current] {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				s.Add(t)
+				stack = append(stack, id)
+			}
+		}
 	}
+	return s
+}
 
-	if err := g.DepthFirstWalk(g.downEdgesNoCopy(v), memoFunc); err != nil {
-		return nil, err
+// interned returns the IDs of the vertices of vs which have them.
+func (g *AcyclicGraph[T]) interned(vs []T) []int {
+	result := make([]int, 0, len(vs))
+	for _, v := range vs {
+		if id, ok := g.ids[v.Hashcode()]; ok {
+			result = append(result, id)
+		}
 	}
+	return result
+}
 
-	return s, nil
+// DescendentsWithin returns the descendents of v which are at most maxDepth
+// edges below it, so 1 returns the targets of v's edges.
+func (g *AcyclicGraph[T]) DescendentsWithin(v T, maxDepth int) (Set[T], error) {
+	return g.reachWithin(v, maxDepth, g.downEdges), nil
 }
 
-// Returns a Set that includes every Vertex yielded by walking up from the
-// provided starting Vertex v. Ancestors will include all root vertexes that can be reached
-// by walking up from v.
-func (g *AcyclicGraph[T]) Ancestors(v T) (Set[T], error) {
+// AncestorsWithin returns the ancestors of v which are at most maxDepth
+// edges above it, so 1 returns the sources of the edges to v.
+func (g *AcyclicGraph[T]) AncestorsWithin(v T, maxDepth int) (Set[T], error) {
+	return g.reachWithin(v, maxDepth, g.upEdges), nil
+}
+
+// reachWithin returns every vertex reached from v by following the
+// adjacency adj at most maxDepth times, searching breadth first so each
+// vertex is reached by its shortest path.
+func (g *AcyclicGraph[T]) reachWithin(v T, maxDepth int, adj map[int]idSet[T]) Set[T] {
 	s := make(Set[T])
-	memoFunc := func(v T, d int) error {
-		s.Add(v)
-		return nil
+	seen := make(map[int]struct{})
+	frontier := g.interned([]T{v})
+	for depth := 0; depth < maxDepth && len(frontier) > 0; depth++ {
+		var reached []int
+		for _, current := range frontier {
+			for id, t := range adj[current] {
+				if _, ok := seen[id]; !ok {
+					seen[id] = struct{}{}
+					s.Add(t)
+					reached = append(reached, id)
+				}
+			}
+		}
+		frontier = reached
+	}
+	return s
+}
+
+// AncestorSubgraph returns a new graph containing v, every ancestor of v, and
+// all edges between them.
+func (g *AcyclicGraph[T]) AncestorSubgraph(v T) (*AcyclicGraph[T], error) {
+	if !g.HasVertex(v) {
+		return nil, fmt.Errorf("vertex %q not found", VertexName(v))
 	}
 
-	if err := g.ReverseDepthFirstWalk(g.upEdgesNoCopy(v), memoFunc); err != nil {
+	s, err := g.Ancestors(v)
+	if err != nil {
 		return nil, err
 	}
+	s.Add(v)
 
-	return s, nil
+	return &AcyclicGraph[T]{*g.Filter(s.Include)}, nil
 }
 
-// Roots returns the root of the DAG, or an error.
+// DescendantSubgraph returns a new graph containing v, every descendant of
+// v, and all edges between them.
+func (g *AcyclicGraph[T]) DescendantSubgraph(v T) (*AcyclicGraph[T], error) {
+	if !g.HasVertex(v) {
+		return nil, fmt.Errorf("vertex %q not found", VertexName(v))
+	}
+
+	s, err := g.Descendents(v)
+	if err != nil {
+		return nil, err
+	}
+	s.Add(v)
+
+	return &AcyclicGraph[T]{*g.Filter(s.Include)}, nil
+}
+
+// Roots returns every root of the DAG, or an error if there are none.
 //
 // Complexity: O(V)
 func (g *AcyclicGraph[T]) Roots() ([]T, error) {
-	roots := make([]T, 0, 1)
-	for _, v := range g.Vertices() {
-		if g.upEdgesNoCopy(v).Len() == 0 {
-			roots = append(roots, v)
-		}
-	}
+	roots := g.Graph.Roots()
 	if len(roots) == 0 {
 		return []T{}, fmt.Errorf("no roots found")
 	}
@@ -75,6 +183,43 @@ func (g *AcyclicGraph[T]) Roots() ([]T, error) {
 	return roots, nil
 }
 
+// AddVirtualRoot adds root to the graph, with an edge to every existing
+// root, so that root is the only root of the graph. This suits graphs with
+// several entry points where an algorithm needs a single root, such as
+// Dominators.
+//
+// Complexity: O(V)
+func (g *AcyclicGraph[T]) AddVirtualRoot(root T) {
+	roots := g.Graph.Roots()
+	g.Add(root)
+	for _, r := range roots {
+		if r.Hashcode() != root.Hashcode() {
+			// both ends are vertices, so no VertexPolicy can reject the
+			// edge
+			g.connect(BasicEdge(root, r))
+		}
+	}
+}
+
+// PrimaryRoot returns a single root of the DAG. When there are multiple
+// roots, the smallest according to less is returned, so the choice is
+// deterministic. An error is only returned if there are no roots.
+func (g *AcyclicGraph[T]) PrimaryRoot(less func(a, b T) bool) (T, error) {
+	roots, err := g.Roots()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	primary := roots[0]
+	for _, r := range roots[1:] {
+		if less(r, primary) {
+			primary = r
+		}
+	}
+	return primary, nil
+}
+
 // TransitiveReduction performs the transitive reduction of graph g in place.
 // The transitive reduction of a graph is a graph with as few edges as
 // possible with the same reachability as the original graph. This means
@@ -89,57 +234,216 @@ func (g *AcyclicGraph[T]) Roots() ([]T, error) {
 //
 // Complexity: O(V(V+E)), or asymptotically O(VE)
 func (g *AcyclicGraph[T]) TransitiveReduction() {
-	// For each vertex u in graph g, do a DFS starting from each vertex
-	// v such that the edge (u,v) exists (v is a direct descendant of u).
+	g.TransitiveReductionReport()
+}
+
+// TransitiveReductionReport performs the transitive reduction of graph g in
+// place, like TransitiveReduction, and returns the edges which were removed,
+// sorted by Hashcode.
+func (g *AcyclicGraph[T]) TransitiveReductionReport() []Edge[T] {
+	return g.ParallelTransitiveReduction(1)
+}
+
+// ParallelTransitiveReduction performs the transitive reduction of graph g
+// in place, like TransitiveReductionReport, searching from the vertices
+// with the given number of goroutines. If workers is 0 or less,
+// runtime.GOMAXPROCS goroutines are used. The result is the same as
+// TransitiveReductionReport for any number of workers.
+//
+// Complexity: O(V(V+E)/workers), with O(V) extra space for each worker
+func (g *AcyclicGraph[T]) ParallelTransitiveReduction(workers int) []Edge[T] {
+	// For each vertex u in graph g, do a DFS starting from the targets of
+	// each vertex v such that the edge (u,v) exists (v is a direct
+	// descendant of u).
 	//
-	// For each v-prime reachable from v, remove the edge (u, v-prime).
-	for _, u := range g.Vertices() {
-		uTargets := g.downEdgesNoCopy(u)
-
-		g.DepthFirstWalk(g.downEdgesNoCopy(u), func(v T, d int) error {
-			shared := uTargets.Intersection(g.downEdgesNoCopy(v))
-			for _, vPrime := range shared {
-				g.RemoveEdge(BasicEdge(u, vPrime))
+	// For each v-prime reached, the edge (u, v-prime) is redundant.
+	// Removing these edges never changes what is reachable, so the
+	// searches from every vertex can use the same index, independently of
+	// each other, and the edges are removed once they're done.
+	idx := g.index()
+	redundant := make([][]int, len(idx.vertices))
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(idx.vertices) {
+		workers = len(idx.vertices)
+	}
+
+	if workers <= 1 {
+		seen := make([]int, len(idx.vertices))
+		var stack []int
+		for u := range idx.vertices {
+			redundant[u], stack = idx.redundant(u, seen, stack)
+		}
+	} else {
+		var next int64 = -1
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				seen := make([]int, len(idx.vertices))
+				var stack []int
+				for {
+					u := int(atomic.AddInt64(&next, 1))
+					if u >= len(idx.vertices) {
+						return
+					}
+					redundant[u], stack = idx.redundant(u, seen, stack)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	var removed []Edge[T]
+	for u, targets := range redundant {
+		for _, v := range targets {
+			if e, ok := g.edges[directedKey(idx.vertices[u], idx.vertices[v])]; ok {
+				removed = append(removed, e)
 			}
+			g.RemoveEdge(BasicEdge(idx.vertices[u], idx.vertices[v]))
+		}
+	}
 
-			return nil
-		})
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].Hashcode() < removed[j].Hashcode()
+	})
+	return removed
+}
+
+// redundant returns the targets of u which can also be reached through
+// another target of u. seen is used to mark the vertices reached, and
+// stack is reused between calls and returned.
+func (idx *graphIndex[T]) redundant(u int, seen, stack []int) ([]int, []int) {
+	// seen holds u+1 for every vertex reached from u, so it doesn't need
+	// clearing between vertices.
+	mark := u + 1
+	for _, v := range idx.down[u] {
+		for _, w := range idx.down[v] {
+			if seen[w] != mark {
+				seen[w] = mark
+				stack = append(stack, w)
+			}
+		}
+	}
+	for len(stack) > 0 {
+		w := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, x := range idx.down[w] {
+			if seen[x] != mark {
+				seen[x] = mark
+				stack = append(stack, x)
+			}
+		}
 	}
+
+	var result []int
+	for _, v := range idx.down[u] {
+		if seen[v] == mark {
+			result = append(result, v)
+		}
+	}
+	return result, stack
+}
+
+// TransitiveReduced returns the transitive reduction of the graph as a
+// copy, leaving g unchanged. See TransitiveReduction.
+func (g *AcyclicGraph[T]) TransitiveReduced() *AcyclicGraph[T] {
+	c := g.Copy()
+	c.TransitiveReduction()
+	return c
+}
+
+// ValidateOpts are the options for validating an AcyclicGraph.
+type ValidateOpts struct {
+	// Fail validation if any edge refers to a vertex which is not in the
+	// graph.
+	DanglingEdges bool
+
+	// Fail validation if any edge violates the layering of LayeredVertex
+	// vertices.
+	Layering bool
 }
 
 // Validate validates the DAG. A DAG is valid if it has at least one root
-// and no cycles.
+// and no cycles. The error is a *ValidationError, so the problems can be
+// inspected programmatically. If the graph contains cycles, they can also
+// be inspected through the CycleReporter interface with errors.As.
 func (g *AcyclicGraph[T]) Validate() error {
-	if _, err := g.Roots(); err != nil {
-		return err
+	return g.ValidateWithOpts(nil)
+}
+
+// ValidateWithOpts validates the DAG, with additional checks enabled by
+// opts. A nil opts is the same as calling Validate.
+func (g *AcyclicGraph[T]) ValidateWithOpts(opts *ValidateOpts) error {
+	if opts == nil {
+		opts = &ValidateOpts{}
 	}
 
-	// Look for cycles of more than 1 component
 	var err error
-	cycles := g.Cycles()
-	if len(cycles) > 0 {
-		for _, cycle := range cycles {
-			cycleStr := make([]string, len(cycle))
-			for j, vertex := range cycle {
-				cycleStr[j] = VertexName(vertex)
-			}
+	if _, rErr := g.Roots(); rErr != nil {
+		err = multierror.Append(err, rErr)
+	}
 
-			err = multierror.Append(err, fmt.Errorf(
-				"Cycle: %s", strings.Join(cycleStr, ", ")))
+	var cycles [][]T
+	for _, cycle := range StronglyConnected(&g.Graph) {
+		if len(cycle) < 2 {
+			continue
 		}
+		cycles = append(cycles, cycle)
+
+		cycleStr := make([]string, len(cycle))
+		for j, vertex := range cycle {
+			cycleStr[j] = VertexName(vertex)
+		}
+
+		err = multierror.Append(err, fmt.Errorf(
+			"Cycle: %s", strings.Join(cycleStr, ", ")))
 	}
 
-	// Look for cycles to self
-	for _, e := range g.Edges() {
-		if e.Source().Hashcode() == e.Target().Hashcode() {
+	selfLoops := g.SelfLoops()
+	for _, e := range selfLoops {
+		err = multierror.Append(err, fmt.Errorf(
+			"Self reference: %s", VertexName(e.Source())))
+	}
+
+	if opts.Layering {
+		for _, e := range g.SortedEdges() {
+			if lErr := CheckLayering(e); lErr != nil {
+				err = multierror.Append(err, lErr)
+			}
+		}
+	}
+
+	dangling := g.DanglingEdges()
+	if opts.DanglingEdges {
+		for _, e := range dangling {
 			err = multierror.Append(err, fmt.Errorf(
-				"Self reference: %s", VertexName(e.Source())))
+				"Dangling edge: %s -> %s", VertexName(e.Source()), VertexName(e.Target())))
 		}
 	}
 
-	return err
+	if err == nil {
+		return nil
+	}
+
+	vErr := &ValidationError[T]{Err: err, cycles: cycles, dangling: dangling}
+	for _, e := range selfLoops {
+		vErr.selfLoops = append(vErr.selfLoops, e.Source())
+	}
+	if len(cycles) > 0 || len(selfLoops) > 0 {
+		vErr.cycleErr = &CycleError[T]{Err: err, cycles: cycles, selfLoops: selfLoops}
+		for _, cycle := range cycles {
+			vErr.cycleErr.edges = append(vErr.cycleErr.edges, g.cyclePath(cycle))
+		}
+	}
+	return vErr
 }
 
+// Cycles returns the strongly connected components of the graph with more
+// than one member, followed by a single vertex cycle for each vertex with an
+// edge to itself.
 func (g *AcyclicGraph[T]) Cycles() [][]T {
 	var cycles [][]T
 	for _, cycle := range StronglyConnected(&g.Graph) {
@@ -147,18 +451,21 @@ func (g *AcyclicGraph[T]) Cycles() [][]T {
 			cycles = append(cycles, cycle)
 		}
 	}
+
+	for _, e := range g.SelfLoops() {
+		cycles = append(cycles, []T{e.Source()})
+	}
 	return cycles
 }
 
-// // Walk walks the graph, calling your callback as each node is visited.
-// // This will walk nodes in parallel if it can. The resulting diagnostics
-// // contains problems from all graphs visited, in no particular order.
-// func (g *AcyclicGraph[T]) Walk(cb WalkFunc[T]) {
-// 	w := &Walker[T]{Callback: cb, Reverse: true}
-// 	w.Update(g)
-// 	w.Wait()
-
-// }
+// Walk walks the graph, calling your callback as each node is visited.
+// This will walk nodes in parallel if it can. The resulting error
+// contains problems from all graphs visited, in no particular order.
+func (g *AcyclicGraph[T]) Walk(cb WalkFunc[T]) error {
+	w := &Walker[T]{Callback: cb, Reverse: true}
+	w.Update(g)
+	return w.Wait()
+}
 
 // simple convenience helper for converting a dag.Set to a []Vertex
 func AsVertexList[T Hashable](s Set[T]) []T {
@@ -213,9 +520,26 @@ func (g *AcyclicGraph[T]) DepthFirstWalk(start Set[T], f DepthWalkFunc[T]) error
 	return nil
 }
 
+// SkipSubtree can be returned by the callback of a context aware walk to
+// stop descending below the current vertex without aborting the walk.
+var SkipSubtree = errors.New("skip this subtree")
+
 // SortedDepthFirstWalk does a depth-first walk of the graph starting from
 // the vertices in start, always iterating the nodes in a consistent order.
 func (g *AcyclicGraph[T]) SortedDepthFirstWalk(start []T, f DepthWalkFunc[T]) error {
+	return g.SortedDepthFirstWalkContext(context.Background(), start, f)
+}
+
+// SortedDepthFirstWalkContext is like SortedDepthFirstWalk, but stops with
+// the context's error if it is cancelled. If f returns SkipSubtree, the walk
+// continues without visiting the targets of that vertex.
+func (g *AcyclicGraph[T]) SortedDepthFirstWalkContext(ctx context.Context, start []T, f DepthWalkFunc[T]) error {
+	return g.sortedWalkContext(ctx, start, f, g.downEdgesNoCopy)
+}
+
+// sortedWalkContext does a sorted depth-first walk from start, following the
+// edges returned by next.
+func (g *AcyclicGraph[T]) sortedWalkContext(ctx context.Context, start []T, f DepthWalkFunc[T], next func(T) idSet[T]) error {
 	seen := make(map[string]struct{})
 	frontier := make([]*vertexAtDepth[T], len(start))
 	for i, v := range start {
@@ -225,6 +549,10 @@ func (g *AcyclicGraph[T]) SortedDepthFirstWalk(start []T, f DepthWalkFunc[T]) er
 		}
 	}
 	for len(frontier) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		// Pop the current vertex
 		n := len(frontier)
 		current := frontier[n-1]
@@ -236,15 +564,19 @@ func (g *AcyclicGraph[T]) SortedDepthFirstWalk(start []T, f DepthWalkFunc[T]) er
 		}
 		seen[current.Vertex.Hashcode()] = struct{}{}
 
+		// Find the targets before visiting, since the callback may remove
+		// the current node.
+		targets := next(current.Vertex).List()
+		g.sortByName(targets)
+
 		// Visit the current node
-		if err := f(current.Vertex, current.Depth); err != nil {
+		if err := f(current.Vertex, current.Depth); err == SkipSubtree {
+			continue
+		} else if err != nil {
 			return err
 		}
 
 		// Visit targets of this in a consistent order.
-		targets := AsVertexList(g.downEdgesNoCopy(current.Vertex))
-		sort.Sort(byVertexName[T](targets))
-
 		for _, t := range targets {
 			frontier = append(frontier, &vertexAtDepth[T]{
 				Vertex: t,
@@ -298,19 +630,43 @@ func (g *AcyclicGraph[T]) ReverseDepthFirstWalk(start Set[T], f DepthWalkFunc[T]
 // SortedReverseDepthFirstWalk does a depth-first walk _up_ the graph starting from
 // the vertices in start, always iterating the nodes in a consistent order.
 func (g *AcyclicGraph[T]) SortedReverseDepthFirstWalk(start []T, f DepthWalkFunc[T]) error {
+	return g.SortedReverseDepthFirstWalkContext(context.Background(), start, f)
+}
+
+// SortedReverseDepthFirstWalkContext is like SortedReverseDepthFirstWalk,
+// but stops with the context's error if it is cancelled. If f returns
+// SkipSubtree, the walk continues without visiting the sources of that
+// vertex.
+func (g *AcyclicGraph[T]) SortedReverseDepthFirstWalkContext(ctx context.Context, start []T, f DepthWalkFunc[T]) error {
+	return g.sortedWalkContext(ctx, start, f, g.upEdgesNoCopy)
+}
+
+// BreadthFirstWalk does a breadth-first walk of the graph starting from
+// the vertices in start. Every vertex at one depth is visited before any
+// vertex at the next depth.
+func (g *AcyclicGraph[T]) BreadthFirstWalk(start Set[T], f DepthWalkFunc[T]) error {
+	return g.breadthFirstWalk(start, f, g.downEdgesNoCopy)
+}
+
+// ReverseBreadthFirstWalk does a breadth-first walk _up_ the graph starting
+// from the vertices in start.
+func (g *AcyclicGraph[T]) ReverseBreadthFirstWalk(start Set[T], f DepthWalkFunc[T]) error {
+	return g.breadthFirstWalk(start, f, g.upEdgesNoCopy)
+}
+
+func (g *AcyclicGraph[T]) breadthFirstWalk(start Set[T], f DepthWalkFunc[T], next func(T) idSet[T]) error {
 	seen := make(map[string]struct{})
-	frontier := make([]*vertexAtDepth[T], len(start))
-	for i, v := range start {
-		frontier[i] = &vertexAtDepth[T]{
+	queue := make([]*vertexAtDepth[T], 0, len(start))
+	for _, v := range start {
+		queue = append(queue, &vertexAtDepth[T]{
 			Vertex: v,
 			Depth:  0,
-		}
+		})
 	}
-	for len(frontier) > 0 {
-		// Pop the current vertex
-		n := len(frontier)
-		current := frontier[n-1]
-		frontier = frontier[:n-1]
+	for len(queue) > 0 {
+		// Shift the next vertex
+		current := queue[0]
+		queue = queue[1:]
 
 		// Check if we've seen this already and return...
 		if _, ok := seen[current.Vertex.Hashcode()]; ok {
@@ -318,23 +674,95 @@ func (g *AcyclicGraph[T]) SortedReverseDepthFirstWalk(start []T, f DepthWalkFunc
 		}
 		seen[current.Vertex.Hashcode()] = struct{}{}
 
-		// Add next set of targets in a consistent order.
-		targets := AsVertexList(g.upEdgesNoCopy(current.Vertex))
-		sort.Sort(byVertexName[T](targets))
+		targets := next(current.Vertex)
+
+		// Visit the current node
+		if err := f(current.Vertex, current.Depth); err != nil {
+			return err
+		}
+
 		for _, t := range targets {
-			frontier = append(frontier, &vertexAtDepth[T]{
+			queue = append(queue, &vertexAtDepth[T]{
 				Vertex: t,
 				Depth:  current.Depth + 1,
 			})
 		}
+	}
 
-		// Visit the current node
-		if err := f(current.Vertex, current.Depth); err != nil {
-			return err
+	return nil
+}
+
+// TopologicalGenerations groups the vertices of the graph into generations,
+// where every vertex only depends on vertices in earlier generations. All
+// the vertices in a generation can therefore be run in parallel once the
+// previous generations are complete. Each generation is sorted by vertex
+// name.
+//
+// As with Walk, the source of an edge depends on its target, so the first
+// generation contains the vertices with no outgoing edges. An error is
+// returned if the graph contains a cycle.
+//
+// Complexity: O(V+E)
+func (g *AcyclicGraph[T]) TopologicalGenerations() ([][]T, error) {
+	outDegree := make(map[string]int, len(g.vertices))
+	var current []T
+	for _, v := range g.Vertices() {
+		for _, t := range g.downEdgesNoCopy(v) {
+			if g.HasVertex(t) {
+				outDegree[v.Hashcode()]++
+			}
+		}
+		if outDegree[v.Hashcode()] == 0 {
+			current = append(current, v)
 		}
 	}
 
-	return nil
+	var generations [][]T
+	seen := 0
+	for len(current) > 0 {
+		g.sortByName(current)
+		generations = append(generations, current)
+		seen += len(current)
+
+		var next []T
+		for _, v := range current {
+			for _, s := range g.upEdgesNoCopy(v) {
+				if !g.HasVertex(s) {
+					continue
+				}
+				outDegree[s.Hashcode()]--
+				if outDegree[s.Hashcode()] == 0 {
+					next = append(next, s)
+				}
+			}
+		}
+		current = next
+	}
+
+	if seen != len(g.vertices) {
+		return nil, fmt.Errorf("graph contains a cycle")
+	}
+
+	return generations, nil
+}
+
+// topologicalOrder returns the vertices of the graph ordered so that every
+// vertex comes before the targets of its edges, or an error if the graph
+// contains a cycle. Edges to vertices which are not in the graph are ignored.
+//
+// Complexity: O(V+E)
+func (g *AcyclicGraph[T]) topologicalOrder() ([]T, error) {
+	idx := g.index()
+	ids, ok := idx.topological()
+	if !ok {
+		return nil, fmt.Errorf("graph contains a cycle")
+	}
+
+	order := make([]T, len(ids))
+	for i, id := range ids {
+		order[i] = idx.vertices[id]
+	}
+	return order, nil
 }
 
 // byVertexName implements sort.Interface so a list of Vertices can be sorted
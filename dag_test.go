@@ -1,9 +1,13 @@
 package dagg
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -56,6 +60,78 @@ func TestAcyclicGraphRoot_multiple(t *testing.T) {
 	}
 }
 
+func TestAcyclicGraphPrimaryRoot(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(3), myint(1)))
+	g.Connect(BasicEdge(myint(4), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+
+	less := func(a, b myint) bool { return a < b }
+	for i := 0; i < 10; i++ {
+		root, err := g.PrimaryRoot(less)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if root != myint(3) {
+			t.Fatalf("bad: %#v", root)
+		}
+	}
+
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(1), myint(4)))
+	if _, err := g.PrimaryRoot(less); err == nil {
+		t.Fatal("should error with no roots")
+	}
+}
+
+func TestAcyclicGraphAddVirtualRoot(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(3), myint(1)))
+	g.Connect(BasicEdge(myint(4), myint(2)))
+
+	g.AddVirtualRoot(myint(0))
+
+	roots, err := g.Roots()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(roots) != 1 || roots[0] != myint(0) {
+		t.Fatalf("bad: %#v", roots)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testGraphAddVirtualRootStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// adding it again changes nothing
+	g.AddVirtualRoot(myint(0))
+	if actual := strings.TrimSpace(g.String()); actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestAcyclicGraphAddVirtualRoot_strictVertices(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.SetVertexPolicy(StrictVertices)
+	g.Add(myint(1))
+	g.Add(myint(2))
+
+	g.AddVirtualRoot(myint(0))
+	if down := g.DownEdges(myint(0)); down.Len() != 2 {
+		t.Fatalf("bad: %#v", down)
+	}
+}
+
 func TestAcyclicGraphTransReduction(t *testing.T) {
 	var g AcyclicGraph[myint]
 	g.Add(myint(1))
@@ -94,6 +170,173 @@ func TestAcyclicGraphTransReduction_more(t *testing.T) {
 	}
 }
 
+func TestAcyclicGraphTransitiveReductionReport(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(LabeledEdge(myint(1), myint(3), "redundant"))
+	g.Connect(BasicEdge(myint(1), myint(4)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+
+	removed := g.TransitiveReductionReport()
+	var actual []string
+	for _, e := range removed {
+		actual = append(actual, e.Hashcode())
+	}
+	if !reflect.DeepEqual(actual, []string{"1-3", "1-4"}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	// the stored edges are returned, with their data
+	if data, ok := removed[0].(DataEdge); !ok || data.EdgeData() != "redundant" {
+		t.Fatalf("bad: %#v", removed[0])
+	}
+	if g.HasEdge(BasicEdge(myint(1), myint(3))) {
+		t.Fatal("edge should be removed")
+	}
+
+	if removed := g.TransitiveReductionReport(); len(removed) != 0 {
+		t.Fatalf("bad: %#v", removed)
+	}
+}
+
+func TestAcyclicGraphTransitiveReduced(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	before := g.String()
+
+	reduced := g.TransitiveReduced()
+	actual := strings.TrimSpace(reduced.String())
+	expected := strings.TrimSpace(testGraphTransReductionStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+	if g.String() != before {
+		t.Fatalf("original changed: %s", g.String())
+	}
+}
+
+func TestAcyclicGraphParallelTransitiveReduction(t *testing.T) {
+	// a dense graph with a deterministic pattern of edges
+	var g AcyclicGraph[myint]
+	const n = 60
+	for i := 0; i < n; i++ {
+		g.Add(myint(i))
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if (i*7+j*13)%5 != 0 {
+				g.Connect(BasicEdge(myint(i), myint(j)))
+			}
+		}
+	}
+
+	serial := g.Copy()
+	expected := serial.TransitiveReductionReport()
+	for _, workers := range []int{0, 2, 8} {
+		c := g.Copy()
+		removed := c.ParallelTransitiveReduction(workers)
+		if !reflect.DeepEqual(removed, expected) {
+			t.Fatalf("%d workers: removed %d edges, want %d", workers, len(removed), len(expected))
+		}
+		if c.String() != serial.String() {
+			t.Fatalf("%d workers: bad: %s", workers, c.String())
+		}
+	}
+}
+
+func TestAcyclicGraphAncestorsWithin(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 5; i++ {
+		g.Add(myint(i))
+	}
+	// a chain 1 -> 2 -> 3 -> 4, with a shortcut 1 -> 4 and 5 -> 3
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+	g.Connect(BasicEdge(myint(1), myint(4)))
+	g.Connect(BasicEdge(myint(5), myint(3)))
+
+	cases := []struct {
+		depth       int
+		ancestors   []myint
+		descendents []myint
+	}{
+		{0, nil, nil},
+		{1, []myint{1, 3}, []myint{2, 4}},
+		{2, []myint{1, 2, 3, 5}, []myint{2, 3, 4}},
+		{10, []myint{1, 2, 3, 5}, []myint{2, 3, 4}},
+	}
+	for _, tc := range cases {
+		ancestors, err := g.AncestorsWithin(myint(4), tc.depth)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		actual := ancestors.List()
+		sort.Sort(byVertexName[myint](actual))
+		if len(actual) != len(tc.ancestors) || (len(actual) > 0 && !reflect.DeepEqual(actual, tc.ancestors)) {
+			t.Fatalf("depth %d: bad ancestors: %#v", tc.depth, actual)
+		}
+
+		descendents, err := g.DescendentsWithin(myint(1), tc.depth)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		actual = descendents.List()
+		sort.Sort(byVertexName[myint](actual))
+		if len(actual) != len(tc.descendents) || (len(actual) > 0 && !reflect.DeepEqual(actual, tc.descendents)) {
+			t.Fatalf("depth %d: bad descendents: %#v", tc.depth, actual)
+		}
+	}
+}
+
+func TestAcyclicGraphAncestorsOf(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 6; i++ {
+		g.Add(myint(i))
+	}
+	// 1 and 2 share the ancestors 3 and 4
+	g.Connect(BasicEdge(myint(3), myint(1)))
+	g.Connect(BasicEdge(myint(3), myint(2)))
+	g.Connect(BasicEdge(myint(4), myint(3)))
+	g.Connect(BasicEdge(myint(5), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(6)))
+
+	ancestors, err := g.AncestorsOf(myint(1), myint(2))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	actual := ancestors.List()
+	sort.Sort(byVertexName[myint](actual))
+	if !reflect.DeepEqual(actual, []myint{3, 4, 5}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	// 2 is a descendent of 5, so it is included
+	descendents, err := g.DescendentsOf(myint(4), myint(5))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	actual = descendents.List()
+	sort.Sort(byVertexName[myint](actual))
+	if !reflect.DeepEqual(actual, []myint{1, 2, 3, 6}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if s, _ := g.AncestorsOf(); s.Len() != 0 {
+		t.Fatalf("bad: %#v", s)
+	}
+}
+
 // use this to simulate slow sort operations
 type counter struct {
 	Name  string
@@ -153,6 +396,33 @@ func TestAcyclicGraphTransReduction_fullyConnected(t *testing.T) {
 	}
 }
 
+func TestAcyclicGraphCopy(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+
+	c := g.Copy()
+	c.TransitiveReduction()
+	c.Remove(myint(3))
+
+	actual := strings.TrimSpace(c.String())
+	expected := strings.TrimSpace(testGraphCopyStr)
+	if actual != expected {
+		t.Fatalf("bad copy: %s", actual)
+	}
+
+	if len(g.Vertices()) != 3 || len(g.Edges()) != 3 {
+		t.Fatalf("original graph modified: %s", g.String())
+	}
+	if g.DownEdges(myint(1)).Len() != 2 {
+		t.Fatalf("original graph modified: %s", g.String())
+	}
+}
+
 func TestAcyclicGraphValidate(t *testing.T) {
 	var g AcyclicGraph[myint]
 	g.Add(myint(1))
@@ -181,6 +451,101 @@ func TestAcyclicGraphValidate_cycle(t *testing.T) {
 	}
 }
 
+func TestAcyclicGraphValidate_validationError(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.SetVertexPolicy(AllowDanglingEdges)
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(BasicEdge(myint(3), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(0)))
+
+	var verr *ValidationError[myint]
+	if !errors.As(g.Validate(), &verr) {
+		t.Fatal("should return a ValidationError")
+	}
+
+	if cycles := verr.Cycles(); len(cycles) != 1 || len(cycles[0]) != 2 {
+		t.Fatalf("bad cycles: %#v", cycles)
+	}
+	if loops := verr.SelfLoops(); len(loops) != 1 || loops[0] != myint(3) {
+		t.Fatalf("bad self loops: %#v", loops)
+	}
+	dangling := verr.DanglingEdges()
+	if len(dangling) != 1 || dangling[0].Target() != myint(0) {
+		t.Fatalf("bad dangling edges: %#v", dangling)
+	}
+
+	// the cycles are still reported through a CycleReporter
+	var cr CycleReporter[myint]
+	if !errors.As(verr, &cr) || len(cr.EdgeCycles()) != 1 {
+		t.Fatal("should wrap a CycleReporter")
+	}
+
+	// without cycles, dangling edges are only an error when asked for
+	g.RemoveEdge(BasicEdge(myint(2), myint(1)))
+	g.RemoveEdge(BasicEdge(myint(3), myint(3)))
+	if err := g.Validate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	err := g.ValidateWithOpts(&ValidateOpts{DanglingEdges: true})
+	if !errors.As(err, &verr) || len(verr.DanglingEdges()) != 1 || len(verr.Cycles()) != 0 {
+		t.Fatalf("bad: %v", err)
+	}
+	if errors.As(err, &cr) {
+		t.Fatal("should not wrap a CycleReporter")
+	}
+}
+
+func TestAcyclicGraphValidate_cycleEdges(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(4), myint(1)))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(1)))
+	g.Connect(BasicEdge(myint(4), myint(4)))
+
+	var cr CycleReporter[myint]
+	if !errors.As(g.Validate(), &cr) {
+		t.Fatal("should return a CycleReporter")
+	}
+
+	cycles := cr.VertexCycles()
+	if len(cycles) != 1 || len(cycles[0]) != 3 {
+		t.Fatalf("bad cycles: %#v", cycles)
+	}
+
+	loops := cr.SelfLoops()
+	if len(loops) != 1 || loops[0].Source() != myint(4) {
+		t.Fatalf("bad self loops: %#v", loops)
+	}
+
+	for _, path := range cr.EdgeCycles() {
+		if len(path) == 0 {
+			t.Fatalf("bad path: %#v", path)
+		}
+		// each path must be a closed walk along the edges of the graph
+		for i, e := range path {
+			if !g.HasEdge(e) {
+				t.Fatalf("bad edge: %s", e)
+			}
+			next := path[(i+1)%len(path)]
+			if e.Target() != next.Source() {
+				t.Fatalf("bad path: %#v", path)
+			}
+		}
+	}
+	if len(cr.EdgeCycles()[0]) != 3 {
+		t.Fatalf("bad path: %#v", cr.EdgeCycles()[0])
+	}
+}
+
 func TestAcyclicGraphValidate_cycleSelf(t *testing.T) {
 	var g AcyclicGraph[myint]
 	g.Add(myint(1))
@@ -192,6 +557,27 @@ func TestAcyclicGraphValidate_cycleSelf(t *testing.T) {
 	}
 }
 
+func TestAcyclicGraphValidate_danglingEdges(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.SetVertexPolicy(AllowDanglingEdges)
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+
+	dangling := g.DanglingEdges()
+	if len(dangling) != 1 || dangling[0].Hashcode() != "2-3" {
+		t.Fatalf("bad: %#v", dangling)
+	}
+
+	if err := g.Validate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := g.ValidateWithOpts(&ValidateOpts{DanglingEdges: true}); err == nil {
+		t.Fatal("should error")
+	}
+}
+
 func TestAcyclicGraphAncestors(t *testing.T) {
 	var g AcyclicGraph[myint]
 	g.Add(myint(1))
@@ -254,130 +640,216 @@ func TestAcyclicGraphDescendents(t *testing.T) {
 	}
 }
 
-// func TestAcyclicGraphWalk(t *testing.T) {
-// 	var g AcyclicGraph[myint]
-// 	g.Add(myint(1))
-// 	g.Add(myint(2))
-// 	g.Add(myint(3))
-// 	g.Connect(BasicEdge(myint(3), myint(2)))
-// 	g.Connect(BasicEdge(myint(3), myint(1)))
-
-// 	var visits []Vertex[myint]
-// 	var lock sync.Mutex
-// 	g.Walk(func(v Vertex[myint]) {
-// 		lock.Lock()
-// 		defer lock.Unlock()
-// 		visits = append(visits, v)
-// 	})
-// 	t.Log("test")
-
-// 	expected := [][]Vertex[myint]{
-// 		{myint(1), myint(2), myint(3)},
-// 		{myint(2), myint(1), myint(3)},
-// 	}
-// 	time.Sleep(time.Second)
-// 	for _, e := range expected {
-// 		if reflect.DeepEqual(visits, e) {
-// 			return
-// 		}
-// 	}
-
-// 	t.Fatalf("bad: %#v", visits)
-// }
-
-// func TestAcyclicGraphWalk_error(t *testing.T) {
-// 	var g AcyclicGraph[myint]
-// 	g.Add(myint(1))
-// 	g.Add(myint(2))
-// 	g.Add(myint(3))
-// 	g.Add(myint(4))
-// 	g.Connect(BasicEdge(myint(4), myint(3)))
-// 	g.Connect(BasicEdge(myint(3), myint(2)))
-// 	g.Connect(BasicEdge(myint(2), myint(1)))
-
-// 	var visits []Vertex[myint]
-// 	var lock sync.Mutex
-// 	g.Walk(func(v Vertex[myint]) {
-// 		lock.Lock()
-// 		defer lock.Unlock()
-
-// 		var diags Diagnostics
-
-// 		if v == 2 {
-// 			diags = diags.Append(fmt.Errorf("error"))
-// 			return diags
-// 		}
-
-// 		visits = append(visits, v)
-// 		return diags
-// 	})
-// 	if err == nil {
-// 		t.Fatal("should error")
-// 	}
-
-// 	expected := []Vertex{1}
-// 	if !reflect.DeepEqual(visits, expected) {
-// 		t.Errorf("wrong visits\ngot:  %#v\nwant: %#v", visits, expected)
-// 	}
-
-// }
-
-// func BenchmarkDAG(b *testing.B) {
-// 	for i := 0; i < b.N; i++ {
-// 		count := 150
-// 		b.StopTimer()
-// 		g := &AcyclicGraph[mystr]{}
-
-// 		// create 4 layers of fully connected nodes
-// 		// layer A
-// 		for i := 0; i < count; i++ {
-// 			g.Add(mystr(fmt.Sprintf("A%d", i)))
-// 		}
-
-// 		// layer B
-// 		for i := 0; i < count; i++ {
-// 			B := fmt.Sprintf("B%d", i)
-// 			g.Add(mystr(B))
-// 			for j := 0; j < count; j++ {
-// 				g.Connect(BasicEdge[mystr](mystr(B), mystr(fmt.Sprintf("A%d", j))))
-// 			}
-// 		}
-
-// 		// layer C
-// 		for i := 0; i < count; i++ {
-// 			c := fmt.Sprintf("C%d", i)
-// 			g.Add(mystr(c))
-// 			for j := 0; j < count; j++ {
-// 				// connect them to previous layers so we have something that requires reduction
-// 				g.Connect(BasicEdge[mystr](mystr(c), mystr(fmt.Sprintf("A%d", j))))
-// 				g.Connect(BasicEdge[mystr](mystr(c), mystr(fmt.Sprintf("B%d", j))))
-// 			}
-// 		}
-
-// 		// layer D
-// 		for i := 0; i < count; i++ {
-// 			d := fmt.Sprintf("D%d", i)
-// 			g.Add(mystr(d))
-// 			for j := 0; j < count; j++ {
-// 				g.Connect(BasicEdge[mystr](mystr(d), mystr(fmt.Sprintf("A%d", j))))
-// 				g.Connect(BasicEdge[mystr](mystr(d), mystr(fmt.Sprintf("B%d", j))))
-// 				g.Connect(BasicEdge[mystr](mystr(d), mystr(fmt.Sprintf("C%d", j))))
-// 			}
-// 		}
-
-// 		b.StartTimer()
-// 		// Find dependencies for every node
-// 		for _, v := range g.Vertices() {
-// 			_, err := g.Ancestors(v)
-// 			if err != nil {
-// 				b.Fatal(err)
-// 			}
-// 		}
-
-// 		// reduce the final graph
-// 		g.TransitiveReduction()
-// 	}
-// }
+func TestAcyclicGraphAncestorSubgraph(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Add(myint(5))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+	g.Connect(BasicEdge(myint(5), myint(4)))
+
+	sub, err := g.AncestorSubgraph(myint(3))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(sub.String())
+	expected := strings.TrimSpace(testGraphAncestorSubgraphStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	if _, err := g.AncestorSubgraph(myint(9)); err == nil {
+		t.Fatal("should error on missing vertex")
+	}
+}
+
+func TestAcyclicGraphDescendantSubgraph(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Add(myint(5))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(4)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+	g.Connect(BasicEdge(myint(5), myint(4)))
+
+	sub, err := g.DescendantSubgraph(myint(2))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(sub.String())
+	expected := strings.TrimSpace(testGraphDescendantSubgraphStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestAcyclicGraphWalk(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(3), myint(2)))
+	g.Connect(BasicEdge(myint(3), myint(1)))
+
+	var visits []myint
+	var lock sync.Mutex
+	err := g.Walk(func(v myint) error {
+		lock.Lock()
+		defer lock.Unlock()
+		visits = append(visits, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := [][]myint{
+		{myint(1), myint(2), myint(3)},
+		{myint(2), myint(1), myint(3)},
+	}
+	for _, e := range expected {
+		if reflect.DeepEqual(visits, e) {
+			return
+		}
+	}
+
+	t.Fatalf("bad: %#v", visits)
+}
+
+func TestAcyclicGraphWalk_error(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(4), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+
+	var visits []myint
+	var lock sync.Mutex
+	err := g.Walk(func(v myint) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if v == 2 {
+			return fmt.Errorf("error")
+		}
+
+		visits = append(visits, v)
+		return nil
+	})
+	if err == nil {
+		t.Fatal("should error")
+	}
+
+	expected := []myint{1}
+	if !reflect.DeepEqual(visits, expected) {
+		t.Errorf("wrong visits\ngot:  %#v\nwant: %#v", visits, expected)
+	}
+
+}
+
+func BenchmarkDAG(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		count := 150
+		b.StopTimer()
+		g := &AcyclicGraph[mystr]{}
+
+		// create 4 layers of fully connected nodes
+		// layer A
+		for i := 0; i < count; i++ {
+			g.Add(mystr(fmt.Sprintf("A%d", i)))
+		}
+
+		// layer B
+		for i := 0; i < count; i++ {
+			B := fmt.Sprintf("B%d", i)
+			g.Add(mystr(B))
+			for j := 0; j < count; j++ {
+				g.Connect(BasicEdge(mystr(B), mystr(fmt.Sprintf("A%d", j))))
+			}
+		}
+
+		// layer C
+		for i := 0; i < count; i++ {
+			c := fmt.Sprintf("C%d", i)
+			g.Add(mystr(c))
+			for j := 0; j < count; j++ {
+				// connect them to previous layers so we have something that requires reduction
+				g.Connect(BasicEdge(mystr(c), mystr(fmt.Sprintf("A%d", j))))
+				g.Connect(BasicEdge(mystr(c), mystr(fmt.Sprintf("B%d", j))))
+			}
+		}
+
+		// layer D
+		for i := 0; i < count; i++ {
+			d := fmt.Sprintf("D%d", i)
+			g.Add(mystr(d))
+			for j := 0; j < count; j++ {
+				g.Connect(BasicEdge(mystr(d), mystr(fmt.Sprintf("A%d", j))))
+				g.Connect(BasicEdge(mystr(d), mystr(fmt.Sprintf("B%d", j))))
+				g.Connect(BasicEdge(mystr(d), mystr(fmt.Sprintf("C%d", j))))
+			}
+		}
+
+		b.StartTimer()
+		// Find dependencies for every node
+		for _, v := range g.Vertices() {
+			_, err := g.Ancestors(v)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		// reduce the final graph
+		g.TransitiveReduction()
+	}
+}
+
+func TestAcyclicGraphTopologicalGenerations(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Add(myint(5))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(4)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+	g.Connect(BasicEdge(myint(1), myint(4)))
+
+	actual, err := g.TopologicalGenerations()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := [][]myint{
+		{4, 5},
+		{2, 3},
+		{1},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	g.Connect(BasicEdge(myint(4), myint(1)))
+	if _, err := g.TopologicalGenerations(); err == nil {
+		t.Fatal("should error on cycle")
+	}
+}
 
 func TestAcyclicGraph_ReverseDepthFirstWalk_WithRemoval(t *testing.T) {
 	var g AcyclicGraph[myint]
@@ -406,6 +878,97 @@ func TestAcyclicGraph_ReverseDepthFirstWalk_WithRemoval(t *testing.T) {
 	}
 }
 
+func TestAcyclicGraph_SortedDepthFirstWalkContext(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Add(myint(5))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(4)))
+	g.Connect(BasicEdge(myint(3), myint(5)))
+
+	var visits []myint
+	err := g.SortedDepthFirstWalkContext(context.Background(), []myint{1}, func(v myint, d int) error {
+		visits = append(visits, v)
+		if v == 3 {
+			return SkipSubtree
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []myint{1, 3, 2, 4}
+	if !reflect.DeepEqual(visits, expected) {
+		t.Fatalf("expected: %#v, got: %#v", expected, visits)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	visits = nil
+	err = g.SortedReverseDepthFirstWalkContext(ctx, []myint{5}, func(v myint, d int) error {
+		visits = append(visits, v)
+		cancel()
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("bad: %v", err)
+	}
+	if !reflect.DeepEqual(visits, []myint{5}) {
+		t.Fatalf("bad: %#v", visits)
+	}
+}
+
+func TestAcyclicGraph_BreadthFirstWalk(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(4)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+
+	depths := make(map[myint]int)
+	var order []myint
+	err := g.BreadthFirstWalk(g.DownEdges(myint(1)), func(v myint, d int) error {
+		depths[v] = d
+		order = append(order, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[myint]int{2: 0, 4: 0, 3: 1}
+	if !reflect.DeepEqual(depths, expected) {
+		t.Fatalf("bad: %#v", depths)
+	}
+	if order[2] != myint(3) {
+		t.Fatalf("bad order: %#v", order)
+	}
+
+	depths = make(map[myint]int)
+	start := make(Set[myint])
+	start.Add(myint(4))
+	err = g.ReverseBreadthFirstWalk(start, func(v myint, d int) error {
+		depths[v] = d
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected = map[myint]int{4: 0, 1: 1, 3: 1, 2: 2}
+	if !reflect.DeepEqual(depths, expected) {
+		t.Fatalf("bad: %#v", depths)
+	}
+}
+
 const testGraphTransReductionStr = `
 1
   2
@@ -423,3 +986,39 @@ const testGraphTransReductionMoreStr = `
   4
 4
 `
+
+const testGraphAncestorSubgraphStr = `
+1
+  2
+  3
+2
+  3
+3
+`
+
+const testGraphDescendantSubgraphStr = `
+2
+  3
+  4
+3
+  4
+4
+`
+
+const testGraphCopyStr = `
+1
+  2
+2
+`
+
+const testGraphAddVirtualRootStr = `
+0
+  3
+  4
+1
+2
+3
+  1
+4
+  2
+`
@@ -0,0 +1,96 @@
+package dagg
+
+import (
+	"log"
+	"time"
+)
+
+// computeLatestStarts calculates the latest time each vertex can start for
+// the walk to finish by the Deadline, given the Estimate of how long each
+// vertex takes. The changeLock must be held.
+func (w *Walker[T]) computeLatestStarts() {
+	if w.Deadline.IsZero() || w.Estimate == nil {
+		return
+	}
+
+	// the vertices waiting on each vertex
+	waiters := make(map[string][]T)
+	for _, e := range w.edges {
+		waiter, dep := w.edgeParts(e)
+		waiters[dep.Hashcode()] = append(waiters[dep.Hashcode()], waiter)
+	}
+
+	// remaining is the longest time from the start of a vertex until the
+	// end of the walk. A cycle can never be walked, but it mustn't send the
+	// visit round forever, so a waiter which is already being visited is
+	// left out.
+	remaining := make(map[string]time.Duration, len(w.vertices))
+	visiting := make(map[string]struct{})
+	var visit func(v T) time.Duration
+	visit = func(v T) time.Duration {
+		if d, ok := remaining[v.Hashcode()]; ok {
+			return d
+		}
+		visiting[v.Hashcode()] = struct{}{}
+
+		var longest time.Duration
+		for _, waiter := range waiters[v.Hashcode()] {
+			if _, ok := visiting[waiter.Hashcode()]; ok {
+				continue
+			}
+			if d := visit(waiter); d > longest {
+				longest = d
+			}
+		}
+		delete(visiting, v.Hashcode())
+		d := w.Estimate(v) + longest
+		remaining[v.Hashcode()] = d
+		return d
+	}
+
+	w.latestStarts = make(map[string]time.Time, len(w.vertices))
+	for k, v := range w.vertices {
+		w.latestStarts[k] = w.Deadline.Add(-visit(v))
+	}
+}
+
+// LatestStart returns the latest time v can start for the walk to finish by
+// the Deadline, if a Deadline and Estimate are set.
+func (w *Walker[T]) LatestStart(v T) (time.Time, bool) {
+	w.changeLock.Lock()
+	defer w.changeLock.Unlock()
+	t, ok := w.latestStarts[v.Hashcode()]
+	return t, ok
+}
+
+// checkDeadline warns if v is starting after its latest start time, since
+// the walk can then no longer finish by the Deadline. Otherwise it warns
+// if v is still running at its latest finish, its latest start plus its
+// Estimate, without waiting for v to finish. The returned func must be
+// called when v finishes.
+func (w *Walker[T]) checkDeadline(v T) func() {
+	latest, ok := w.LatestStart(v)
+	if !ok {
+		return func() {}
+	}
+
+	now := w.clock()
+	if now.After(latest) {
+		late := now.Sub(latest)
+		log.Printf("[WARN] dagg/walk: %q started %s after its latest start, so the walk will miss its deadline", VertexName(v), late)
+		if w.DeadlineWarning != nil {
+			w.DeadlineWarning(v, late)
+		}
+		return func() {}
+	}
+
+	finish := latest.Add(w.Estimate(v))
+	timer := time.AfterFunc(finish.Sub(now), func() {
+		late := w.clock().Sub(finish)
+		log.Printf("[WARN] dagg/walk: %q is still running %s after its latest finish, so the walk will miss its deadline", VertexName(v), late)
+		if w.DeadlineWarning != nil {
+			w.DeadlineWarning(v, late)
+		}
+	})
+	return func() { timer.Stop() }
+}
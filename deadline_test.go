@@ -0,0 +1,145 @@
+package dagg
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWalkerDeadline(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(BasicEdge(myint(3), myint(1)))
+
+	start := time.Unix(0, 0)
+	var lock sync.Mutex
+	now := start
+	var late []myint
+	w := &Walker[myint]{
+		Reverse:  true,
+		Deadline: start.Add(10 * time.Second),
+		Estimate: func(v myint) time.Duration {
+			return time.Duration(v) * time.Second
+		},
+		DeadlineWarning: func(v myint, d time.Duration) {
+			lock.Lock()
+			defer lock.Unlock()
+			late = append(late, v)
+		},
+		Callback: func(v myint) error {
+			// 1 takes longer than estimated
+			if v == 1 {
+				lock.Lock()
+				now = now.Add(8 * time.Second)
+				lock.Unlock()
+			}
+			return nil
+		},
+		now: func() time.Time {
+			lock.Lock()
+			defer lock.Unlock()
+			return now
+		},
+	}
+	w.Update(&g)
+
+	// 3 must finish by the deadline, and 1 must finish before 3 starts
+	if s, _ := w.LatestStart(myint(3)); !s.Equal(start.Add(7 * time.Second)) {
+		t.Fatalf("bad: %s", s)
+	}
+	if s, _ := w.LatestStart(myint(1)); !s.Equal(start.Add(6 * time.Second)) {
+		t.Fatalf("bad: %s", s)
+	}
+
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// 2 and 3 start 8s in, which is too late for 3 but not 2
+	if !reflect.DeepEqual(late, []myint{3}) {
+		t.Fatalf("bad: %#v", late)
+	}
+}
+
+func TestWalkerDeadline_running(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+
+	warned := make(chan myint, 2)
+	w := &Walker[myint]{
+		Reverse:  true,
+		Deadline: time.Now().Add(200 * time.Millisecond),
+		Estimate: func(v myint) time.Duration {
+			return 10 * time.Millisecond
+		},
+		DeadlineWarning: func(v myint, d time.Duration) {
+			warned <- v
+		},
+		Callback: func(v myint) error {
+			if v != 1 {
+				return nil
+			}
+
+			// 1 overruns, and the warning comes while it's still running
+			select {
+			case v := <-warned:
+				if v != 1 {
+					t.Errorf("bad: %d", v)
+				}
+			case <-time.After(5 * time.Second):
+				t.Error("no warning while running")
+			}
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		},
+	}
+	w.Update(&g)
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// 2 starts too late
+	if v := <-warned; v != 2 {
+		t.Fatalf("bad: %d", v)
+	}
+}
+
+func TestWalkerDeadline_cycle(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+
+	start := time.Unix(0, 0)
+	w := &Walker[myint]{
+		Reverse:  true,
+		Deadline: start.Add(10 * time.Second),
+		Estimate: func(v myint) time.Duration {
+			return time.Second
+		},
+		Callback: func(v myint) error { return nil },
+		now:      func() time.Time { return start },
+	}
+
+	// the cycle is left out of the critical path, rather than overflowing
+	// the stack
+	w.Update(&g)
+	for _, v := range []myint{1, 2} {
+		if s, ok := w.LatestStart(v); !ok || s.Before(start.Add(8*time.Second)) {
+			t.Fatalf("bad: %s", s)
+		}
+	}
+
+	g.RemoveEdge(BasicEdge(myint(1), myint(2)))
+	w.Update(&g)
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
@@ -0,0 +1,166 @@
+package dagg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+)
+
+// ContentHash returns a hash of the hashcodes of the vertices and edges of
+// the graph. Two graphs with the same vertices and edges have the same
+// ContentHash, regardless of the order they were built in. Changes to a
+// vertex or edge which don't change its hashcode don't change the hash.
+//
+// Complexity: O((V+E) log(V+E))
+func (g *Graph[T]) ContentHash() string {
+	return contentHash(g.vertices, g.edges)
+}
+
+// contentHash returns the ContentHash of a graph with the given vertices
+// and edges.
+func contentHash[T Hashable](vertices Set[T], edges edgeSet[T]) string {
+	vs := make([]string, 0, len(vertices))
+	for k := range vertices {
+		vs = append(vs, k)
+	}
+	sort.Strings(vs)
+
+	es := make([]string, 0, len(edges))
+	for k := range edges {
+		es = append(es, k.String())
+	}
+	sort.Strings(es)
+
+	h := sha256.New()
+	for _, v := range vs {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	// separate the vertices from the edges
+	h.Write([]byte{1})
+	for _, e := range es {
+		h.Write([]byte(e))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Derivation caches a graph derived from another, such as its transitive
+// reduction, so that it is only recomputed when the source graph changes.
+// A Derivation is safe for concurrent use.
+type Derivation[T Hashable] struct {
+	// Compute derives a graph from the source. It is given a snapshot of the
+	// source which it may modify.
+	Compute func(*AcyclicGraph[T]) (*AcyclicGraph[T], error)
+
+	lock   sync.Mutex
+	hash   string
+	result *AcyclicGraph[T]
+	err    error
+}
+
+// Derive returns the graph derived from g, calling Compute only if the
+// ContentHash of g differs from the last call. The result is a read-only
+// snapshot, which may be modified without affecting the cached graph.
+func (d *Derivation[T]) Derive(g *AcyclicGraph[T]) (*AcyclicGraph[T], error) {
+	hash := g.ContentHash()
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.result == nil && d.err == nil || hash != d.hash {
+		d.result, d.err = d.Compute(g.ReadSnapshot())
+		d.hash = hash
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d.result.ReadSnapshot(), nil
+}
+
+// Derive derives a graph from g using compute. It is a convenience for
+// computing a single derived graph; use a Derivation to cache the result
+// across changes to g.
+func Derive[T Hashable](g *AcyclicGraph[T], compute func(*AcyclicGraph[T]) (*AcyclicGraph[T], error)) (*AcyclicGraph[T], error) {
+	d := &Derivation[T]{Compute: compute}
+	return d.Derive(g)
+}
+
+// StaleView caches a value derived from a graph, such as its topological
+// order or a reachability index, for read paths which can tolerate a
+// slightly stale value but not the latency of recomputing it. Once a value
+// has been computed, Get always returns immediately, recomputing the value
+// in the background when the graph has changed. A StaleView is safe for
+// concurrent use.
+type StaleView[T Hashable, R any] struct {
+	// Compute derives the value from the source. It is given a snapshot of
+	// the source which it may modify.
+	Compute func(*AcyclicGraph[T]) (R, error)
+
+	lock  sync.Mutex
+	hash  string
+	value R
+	err   error
+	valid bool
+
+	// pending is the hash being computed in the background, and done is
+	// closed when it finishes.
+	pending string
+	done    chan struct{}
+}
+
+// Get returns the value derived from g. The first call computes the value
+// before returning. After that, if the ContentHash of g differs from the
+// last computed value, that value is returned with stale set, and the
+// value is recomputed from a snapshot of g in the background. Only one
+// recomputation runs at a time, so Get must be called again once it
+// finishes to pick up any later changes.
+func (v *StaleView[T, R]) Get(g *AcyclicGraph[T]) (value R, stale bool, err error) {
+	hash := g.ContentHash()
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if !v.valid {
+		v.value, v.err = v.Compute(g.ReadSnapshot())
+		v.hash = hash
+		v.valid = true
+		return v.value, false, v.err
+	}
+
+	if hash == v.hash {
+		return v.value, false, v.err
+	}
+
+	if v.pending == "" {
+		v.pending = hash
+		v.done = make(chan struct{})
+		go v.recompute(g.ReadSnapshot(), hash, v.done)
+	}
+	return v.value, true, v.err
+}
+
+// recompute computes the value of the snapshot in the background.
+func (v *StaleView[T, R]) recompute(snapshot *AcyclicGraph[T], hash string, done chan struct{}) {
+	value, err := v.Compute(snapshot)
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.value, v.err = value, err
+	v.hash = hash
+	v.pending = ""
+	close(done)
+}
+
+// Wait blocks until any recomputation running in the background has
+// finished.
+func (v *StaleView[T, R]) Wait() {
+	v.lock.Lock()
+	done := v.done
+	v.lock.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
@@ -0,0 +1,122 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphContentHash(t *testing.T) {
+	var a, b Graph[myint]
+	a.Add(myint(1))
+	a.Add(myint(2))
+	a.Connect(BasicEdge(myint(1), myint(2)))
+
+	b.Add(myint(2))
+	b.Add(myint(1))
+	b.Connect(BasicEdge(myint(1), myint(2)))
+
+	if a.ContentHash() != b.ContentHash() {
+		t.Fatal("hashes should match")
+	}
+
+	b.Connect(BasicEdge(myint(2), myint(1)))
+	if a.ContentHash() == b.ContentHash() {
+		t.Fatal("hashes should differ")
+	}
+}
+
+func TestDerivation(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+
+	calls := 0
+	d := &Derivation[myint]{
+		Compute: func(g *AcyclicGraph[myint]) (*AcyclicGraph[myint], error) {
+			calls++
+			g.TransitiveReduction()
+			return g, nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		reduced, err := d.Derive(&g)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		actual := strings.TrimSpace(reduced.String())
+		expected := strings.TrimSpace(testGraphTransReductionStr)
+		if actual != expected {
+			t.Fatalf("bad: %s", actual)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("bad calls: %d", calls)
+	}
+
+	// the source is unchanged by the computation
+	if !g.HasEdge(BasicEdge(myint(1), myint(3))) {
+		t.Fatalf("bad: %s", g.String())
+	}
+
+	g.Add(myint(4))
+	if _, err := d.Derive(&g); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if calls != 2 {
+		t.Fatalf("bad calls: %d", calls)
+	}
+}
+
+func TestStaleView(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+
+	// block recomputation until the test releases it
+	release := make(chan struct{})
+	calls := 0
+	v := &StaleView[myint, int]{
+		Compute: func(g *AcyclicGraph[myint]) (int, error) {
+			calls++
+			if calls > 1 {
+				<-release
+			}
+			gens, err := g.TopologicalGenerations()
+			return len(gens), err
+		},
+	}
+
+	n, stale, err := v.Get(&g)
+	if err != nil || stale || n != 2 {
+		t.Fatalf("bad: %d %t %v", n, stale, err)
+	}
+
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+
+	// the old value is returned while the new one is computed, and only
+	// one recomputation runs at a time
+	for i := 0; i < 2; i++ {
+		n, stale, err = v.Get(&g)
+		if err != nil || !stale || n != 2 {
+			t.Fatalf("bad: %d %t %v", n, stale, err)
+		}
+	}
+
+	close(release)
+	v.Wait()
+
+	n, stale, err = v.Get(&g)
+	if err != nil || stale || n != 3 {
+		t.Fatalf("bad: %d %t %v", n, stale, err)
+	}
+	if calls != 2 {
+		t.Fatalf("bad: %d calls", calls)
+	}
+}
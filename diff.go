@@ -0,0 +1,41 @@
+package dagg
+
+// GraphDiff describes the changes required to turn one graph into another.
+// The edges are sorted by Hashcode. They are kept in slices rather than
+// Sets, since distinct edges can share a Hashcode.
+type GraphDiff[T Hashable] struct {
+	AddedVertices   Set[T]
+	RemovedVertices Set[T]
+	AddedEdges      []Edge[T]
+	RemovedEdges    []Edge[T]
+}
+
+// Empty returns true if the diff contains no changes.
+func (d GraphDiff[T]) Empty() bool {
+	return d.AddedVertices.Len() == 0 &&
+		d.RemovedVertices.Len() == 0 &&
+		len(d.AddedEdges) == 0 &&
+		len(d.RemovedEdges) == 0
+}
+
+// Diff returns the vertices and edges that were added or removed between the
+// old and new graphs. Vertices are compared by their Hashcode, and edges by
+// the hashcodes of their ends. Either graph may be nil, which is treated as
+// an empty graph.
+//
+// Complexity: O(V+E)
+func Diff[T Hashable](old, new *Graph[T]) GraphDiff[T] {
+	if old == nil {
+		old = &Graph[T]{}
+	}
+	if new == nil {
+		new = &Graph[T]{}
+	}
+
+	return GraphDiff[T]{
+		AddedVertices:   new.vertices.Difference(old.vertices),
+		RemovedVertices: old.vertices.Difference(new.vertices),
+		AddedEdges:      new.edges.Difference(old.edges).Slice(),
+		RemovedEdges:    old.edges.Difference(new.edges).Slice(),
+	}
+}
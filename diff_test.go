@@ -0,0 +1,70 @@
+package dagg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	var old Graph[myint]
+	old.Add(myint(1))
+	old.Add(myint(2))
+	old.Add(myint(3))
+	old.Connect(BasicEdge(myint(1), myint(2)))
+	old.Connect(BasicEdge(myint(2), myint(3)))
+
+	var new Graph[myint]
+	new.Add(myint(1))
+	new.Add(myint(2))
+	new.Add(myint(4))
+	new.Connect(BasicEdge(myint(1), myint(2)))
+	new.Connect(BasicEdge(myint(2), myint(4)))
+
+	d := Diff(&old, &new)
+	if d.Empty() {
+		t.Fatal("diff should not be empty")
+	}
+
+	if d.AddedVertices.Len() != 1 || !d.AddedVertices.Include(myint(4)) {
+		t.Fatalf("bad added vertices: %#v", d.AddedVertices)
+	}
+	if d.RemovedVertices.Len() != 1 || !d.RemovedVertices.Include(myint(3)) {
+		t.Fatalf("bad removed vertices: %#v", d.RemovedVertices)
+	}
+	if len(d.AddedEdges) != 1 || !reflect.DeepEqual(d.AddedEdges[0], BasicEdge(myint(2), myint(4))) {
+		t.Fatalf("bad added edges: %#v", d.AddedEdges)
+	}
+	if len(d.RemovedEdges) != 1 || !reflect.DeepEqual(d.RemovedEdges[0], BasicEdge(myint(2), myint(3))) {
+		t.Fatalf("bad removed edges: %#v", d.RemovedEdges)
+	}
+}
+
+func TestDiff_sharedHashcode(t *testing.T) {
+	// both edges have the Hashcode "a-b-c"
+	var g Graph[mystr]
+	g.Connect(BasicEdge(mystr("a-b"), mystr("c")))
+	g.Connect(BasicEdge(mystr("a"), mystr("b-c")))
+
+	d := Diff(nil, &g)
+	if len(d.AddedEdges) != 2 {
+		t.Fatalf("bad added edges: %#v", d.AddedEdges)
+	}
+	if d := Diff(&g, nil); len(d.RemovedEdges) != 2 {
+		t.Fatalf("bad removed edges: %#v", d.RemovedEdges)
+	}
+}
+
+func TestDiff_nil(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+
+	if d := Diff(nil, &g); d.AddedVertices.Len() != 1 {
+		t.Fatalf("bad: %#v", d)
+	}
+	if d := Diff(&g, nil); d.RemovedVertices.Len() != 1 {
+		t.Fatalf("bad: %#v", d)
+	}
+	if d := Diff(&g, &g); !d.Empty() {
+		t.Fatalf("bad: %#v", d)
+	}
+}
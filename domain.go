@@ -0,0 +1,35 @@
+package dagg
+
+// FailureDomainVertex is a vertex that belongs to a named failure domain,
+// such as the host or region it runs against. When MaxDomainFailures of the
+// vertices in a domain have failed, the Walker skips the remaining vertices
+// of that domain, without skipping the vertices of other domains.
+type FailureDomainVertex interface {
+	FailureDomain() string
+}
+
+// failureDomain returns the failure domain of v, or "" if it has none.
+func failureDomain[T Hashable](v T) string {
+	var raw interface{}
+	raw = v
+	if fd, ok := raw.(FailureDomainVertex); ok {
+		return fd.FailureDomain()
+	}
+	return ""
+}
+
+// domainThresholdReached returns true if the failure domain of v has
+// reached MaxDomainFailures.
+func (w *Walker[T]) domainThresholdReached(v T) bool {
+	if w.MaxDomainFailures <= 0 {
+		return false
+	}
+	domain := failureDomain(v)
+	if domain == "" {
+		return false
+	}
+
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+	return w.domainFailures[domain] >= w.MaxDomainFailures
+}
@@ -0,0 +1,71 @@
+package dagg
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type domainVertex struct {
+	name   string
+	domain string
+}
+
+func (v *domainVertex) Hashcode() string      { return v.name }
+func (v *domainVertex) FailureDomain() string { return v.domain }
+
+func TestWalker_maxDomainFailures(t *testing.T) {
+	var g AcyclicGraph[*domainVertex]
+	a1 := g.Add(&domainVertex{"a1", "a"})
+	a2 := g.Add(&domainVertex{"a2", "a"})
+	a3 := g.Add(&domainVertex{"a3", "a"})
+	b1 := g.Add(&domainVertex{"b1", "b"})
+	b2 := g.Add(&domainVertex{"b2", "b"})
+	gate := g.Add(&domainVertex{"gate", ""})
+
+	// a3 and b2 wait for the gate, which waits for the failures
+	g.Connect(BasicEdge(a3, gate))
+	g.Connect(BasicEdge(b2, gate))
+
+	var lock sync.Mutex
+	var ran []string
+	var failures sync.WaitGroup
+	failures.Add(3)
+	cb := func(v *domainVertex) error {
+		switch v {
+		case a1, a2, b1:
+			defer failures.Done()
+			return fmt.Errorf("error %s", v.name)
+		case gate:
+			failures.Wait()
+			time.Sleep(10 * time.Millisecond)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		ran = append(ran, v.name)
+		return nil
+	}
+
+	w := &Walker[*domainVertex]{Callback: cb, Reverse: true, MaxDomainFailures: 2}
+	w.Update(&g)
+
+	err := w.Wait()
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	if !strings.Contains(err.Error(), "1 vertices skipped") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	// domain a reached the threshold, so a3 is skipped; domain b only had
+	// one failure, so b2 still runs
+	sort.Strings(ran)
+	expected := []string{"b2", "gate"}
+	if !reflect.DeepEqual(ran, expected) {
+		t.Fatalf("bad: %#v", ran)
+	}
+}
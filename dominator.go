@@ -0,0 +1,119 @@
+package dagg
+
+import (
+	"fmt"
+)
+
+// Dominators returns the immediate dominator of every vertex reachable from
+// root, keyed by the hashcode of the vertex. A vertex d dominates v if every
+// path from root to v, following edges from source to target, passes
+// through d. The immediate dominator of v is the dominator of v closest to
+// it. The root itself has no immediate dominator, and is not included.
+//
+// This uses the Lengauer-Tarjan algorithm.
+//
+// Complexity: O((V+E) log V)
+func (g *AcyclicGraph[T]) Dominators(root T) (map[string]T, error) {
+	if !g.HasVertex(root) {
+		return nil, fmt.Errorf("vertex %q not found", VertexName(root))
+	}
+
+	// Number the vertices in depth-first order. Vertices are referred to by
+	// their number from here on.
+	var vertex []T
+	number := make(map[string]int)
+	var parent []int
+	type frame struct {
+		v      T
+		parent int
+	}
+	stack := []frame{{v: root, parent: -1}}
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := number[f.v.Hashcode()]; ok {
+			continue
+		}
+
+		n := len(vertex)
+		number[f.v.Hashcode()] = n
+		vertex = append(vertex, f.v)
+		parent = append(parent, f.parent)
+
+		for _, t := range g.downEdgesNoCopy(f.v) {
+			if _, ok := number[t.Hashcode()]; !ok && g.HasVertex(t) {
+				stack = append(stack, frame{v: t, parent: n})
+			}
+		}
+	}
+
+	n := len(vertex)
+	semi := make([]int, n)
+	idom := make([]int, n)
+	ancestor := make([]int, n)
+	label := make([]int, n)
+	bucket := make([][]int, n)
+	for i := range semi {
+		semi[i] = i
+		label[i] = i
+		ancestor[i] = -1
+	}
+
+	// eval returns the vertex with the lowest semidominator on the path from
+	// v to the root of its tree in the forest, compressing the path as it
+	// goes.
+	eval := func(v int) int {
+		if ancestor[v] == -1 {
+			return v
+		}
+
+		// collect the path to the root of the tree, then compress it from
+		// the top down
+		var path []int
+		for u := v; ancestor[ancestor[u]] != -1; u = ancestor[u] {
+			path = append(path, u)
+		}
+		for i := len(path) - 1; i >= 0; i-- {
+			u := path[i]
+			if semi[label[ancestor[u]]] < semi[label[u]] {
+				label[u] = label[ancestor[u]]
+			}
+			ancestor[u] = ancestor[ancestor[u]]
+		}
+		return label[v]
+	}
+
+	for w := n - 1; w > 0; w-- {
+		for _, raw := range g.upEdgesNoCopy(vertex[w]) {
+			v, ok := number[raw.Hashcode()]
+			if !ok {
+				continue
+			}
+			if u := eval(v); semi[u] < semi[w] {
+				semi[w] = semi[u]
+			}
+		}
+		bucket[semi[w]] = append(bucket[semi[w]], w)
+		ancestor[w] = parent[w]
+
+		p := parent[w]
+		for _, v := range bucket[p] {
+			if u := eval(v); semi[u] < semi[v] {
+				idom[v] = u
+			} else {
+				idom[v] = p
+			}
+		}
+		bucket[p] = nil
+	}
+
+	result := make(map[string]T, n-1)
+	for w := 1; w < n; w++ {
+		if idom[w] != semi[w] {
+			idom[w] = idom[idom[w]]
+		}
+		result[vertex[w].Hashcode()] = vertex[idom[w]]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package dagg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAcyclicGraphDominators(t *testing.T) {
+	//     1
+	//    / \
+	//   2   3
+	//   |\ /
+	//   | 4
+	//    \|
+	//     5
+	//     |
+	//     6
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 6; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(4)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+	g.Connect(BasicEdge(myint(2), myint(5)))
+	g.Connect(BasicEdge(myint(4), myint(5)))
+	g.Connect(BasicEdge(myint(5), myint(6)))
+
+	doms, err := g.Dominators(myint(1))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]myint{
+		"2": 1,
+		"3": 1,
+		"4": 1,
+		"5": 1,
+		"6": 5,
+	}
+	if !reflect.DeepEqual(doms, expected) {
+		t.Fatalf("bad: %#v", doms)
+	}
+
+	doms, err = g.Dominators(myint(2))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected = map[string]myint{
+		"4": 2,
+		"5": 2,
+		"6": 5,
+	}
+	if !reflect.DeepEqual(doms, expected) {
+		t.Fatalf("bad: %#v", doms)
+	}
+
+	if _, err := g.Dominators(myint(7)); err == nil {
+		t.Fatal("should error on missing vertex")
+	}
+}
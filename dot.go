@@ -16,9 +16,33 @@ type DotOpts struct {
 	// Highlight Cycles
 	DrawCycles bool
 
-	// How many levels to expand modules as we draw
+	// How many levels to expand modules as we draw. The graph of each
+	// Subgrapher vertex is drawn as a "subgraph cluster_<name>" block,
+	// nested inside the block of the graph it belongs to, down to MaxDepth
+	// levels. Less than zero expands every level. Zero also expands every
+	// level, but names the blocks without the cluster_ prefix, so they
+	// aren't drawn as clusters.
 	MaxDepth int
 
+	// Theme used to style the vertices. Vertices which aren't a
+	// GraphNodeDotter are drawn if the theme gives them a style.
+	Theme *Theme
+
+	// Include the annotations of vertices from Graph.SetVertexData as
+	// attributes. Vertices which aren't a GraphNodeDotter are drawn if they
+	// have annotations.
+	VertexData bool
+
+	// VertexAttrs, if set, is called with each vertex drawn, and returns
+	// extra attributes for it, such as a color. EdgeAttrs is the same for
+	// each edge. Since DotOpts isn't specific to the type of the graph, the
+	// vertex is passed as an interface{} holding a T, and the edge as one
+	// holding an Edge[T]. These attributes override all others, and
+	// vertices which aren't a GraphNodeDotter are drawn if they are given
+	// any.
+	VertexAttrs func(v interface{}) map[string]string
+	EdgeAttrs   func(e interface{}) map[string]string
+
 	// use this to keep the cluster_ naming convention from the previous dot writer
 	cluster bool
 }
@@ -62,6 +86,8 @@ func (g *marshalGraph) Dot(opts *DotOpts) []byte {
 	// the top level graph is written as the first subgraph
 	w.WriteString(`subgraph "root" {` + "\n")
 	g.writeBody(opts, &w)
+	w.Unindent()
+	w.WriteString("}\n")
 
 	// cluster isn't really used other than for naming purposes in some graphs
 	opts.cluster = opts.MaxDepth != 0
@@ -79,6 +105,22 @@ func (g *marshalGraph) Dot(opts *DotOpts) []byte {
 	return w.Bytes()
 }
 
+// drawn returns true if the vertex is included in the dot output: if it is a
+// GraphNodeDotter, or the options give it any attributes.
+func (v *marshalVertex) drawn(opts *DotOpts) bool {
+	switch {
+	case v.graphNodeDotter != nil:
+		return true
+	case !opts.Theme.style(v.kind, v.state).empty():
+		return true
+	case opts.VertexData && len(v.data) > 0:
+		return true
+	case opts.VertexAttrs != nil && v.raw != nil:
+		return len(opts.VertexAttrs(v.raw)) > 0
+	}
+	return false
+}
+
 func (v *marshalVertex) dot(g *marshalGraph, opts *DotOpts) []byte {
 	var buf bytes.Buffer
 	graphName := g.Name
@@ -88,6 +130,23 @@ func (v *marshalVertex) dot(g *marshalGraph, opts *DotOpts) []byte {
 
 	name := v.Name
 	attrs := v.Attrs
+	if opts.VertexData && len(v.data) > 0 {
+		newAttrs := make(map[string]string)
+		for k, v := range v.data {
+			newAttrs[k] = v
+		}
+		for k, v := range attrs {
+			newAttrs[k] = v
+		}
+		attrs = newAttrs
+	}
+	if style := opts.Theme.style(v.kind, v.state); !style.empty() {
+		newAttrs := style.dotAttrs()
+		for k, v := range attrs {
+			newAttrs[k] = v
+		}
+		attrs = newAttrs
+	}
 	if v.graphNodeDotter != nil {
 		node := v.graphNodeDotter.DotNode(name, opts)
 		if node == nil {
@@ -106,6 +165,10 @@ func (v *marshalVertex) dot(g *marshalGraph, opts *DotOpts) []byte {
 		attrs = newAttrs
 	}
 
+	if opts.VertexAttrs != nil && v.raw != nil {
+		attrs = mergeAttrs(attrs, opts.VertexAttrs(v.raw))
+	}
+
 	buf.WriteString(fmt.Sprintf(`"[%s] %s"`, graphName, name))
 	writeAttrs(&buf, attrs)
 	buf.WriteByte('\n')
@@ -113,7 +176,7 @@ func (v *marshalVertex) dot(g *marshalGraph, opts *DotOpts) []byte {
 	return buf.Bytes()
 }
 
-func (e *marshalEdge) dot(g *marshalGraph) string {
+func (e *marshalEdge) dot(g *marshalGraph, opts *DotOpts) string {
 	var buf bytes.Buffer
 	graphName := g.Name
 	if graphName == "" {
@@ -124,17 +187,23 @@ func (e *marshalEdge) dot(g *marshalGraph) string {
 	targetName := g.vertexByID(e.Target).Name
 	s := fmt.Sprintf(`"[%s] %s" -> "[%s] %s"`, graphName, sourceName, graphName, targetName)
 	buf.WriteString(s)
-	writeAttrs(&buf, e.Attrs)
+
+	attrs := e.Attrs
+	if opts.EdgeAttrs != nil && e.raw != nil {
+		attrs = mergeAttrs(attrs, opts.EdgeAttrs(e.raw))
+	}
+	writeAttrs(&buf, attrs)
 
 	return buf.String()
 }
 
-func cycleDot(e *marshalEdge, g *marshalGraph) string {
-	return e.dot(g) + ` [color = "red", penwidth = "2.0"]`
+func cycleDot(e *marshalEdge, g *marshalGraph, opts *DotOpts) string {
+	return e.dot(g, opts) + ` [color = "red", penwidth = "2.0"]`
 }
 
 // Write the subgraph body. The is recursive, and the depth argument is used to
-// record the current depth of iteration.
+// record the current depth of iteration. The subgraphs of a subgraph are
+// nested inside it, so that their clusters are drawn inside its cluster.
 func (g *marshalGraph) writeSubgraph(sg *marshalGraph, opts *DotOpts, depth int, w *indentWriter) {
 	if depth == 0 {
 		return
@@ -150,9 +219,12 @@ func (g *marshalGraph) writeSubgraph(sg *marshalGraph, opts *DotOpts, depth int,
 	w.WriteString(fmt.Sprintf("subgraph %q {\n", name))
 	sg.writeBody(opts, w)
 
-	for _, sg := range sg.Subgraphs {
-		g.writeSubgraph(sg, opts, depth, w)
+	for _, child := range sg.Subgraphs {
+		sg.writeSubgraph(child, opts, depth, w)
 	}
+
+	w.Unindent()
+	w.WriteString("}\n")
 }
 
 func (g *marshalGraph) writeBody(opts *DotOpts, w *indentWriter) {
@@ -166,7 +238,7 @@ func (g *marshalGraph) writeBody(opts *DotOpts, w *indentWriter) {
 	skip := map[string]bool{}
 
 	for _, v := range g.Vertices {
-		if v.graphNodeDotter == nil {
+		if !v.drawn(opts) {
 			skip[v.ID] = true
 			continue
 		}
@@ -174,7 +246,11 @@ func (g *marshalGraph) writeBody(opts *DotOpts, w *indentWriter) {
 		w.Write(v.dot(g, opts))
 	}
 
-	var dotEdges []string
+	// the ends are kept to order the edges with a custom comparator
+	type dotEdge struct {
+		source, target, line string
+	}
+	var dotEdges []dotEdge
 
 	if opts.DrawCycles {
 		for _, c := range g.Cycles {
@@ -200,25 +276,44 @@ func (g *marshalGraph) writeBody(opts *DotOpts, w *indentWriter) {
 					Attrs:  make(map[string]string),
 				}
 
-				dotEdges = append(dotEdges, cycleDot(e, g))
+				dotEdges = append(dotEdges, dotEdge{src.ID, tgt.ID, cycleDot(e, g, opts)})
 				src = tgt
 			}
 		}
 	}
 
 	for _, e := range g.Edges {
-		dotEdges = append(dotEdges, e.dot(g))
+		dotEdges = append(dotEdges, dotEdge{e.Source, e.Target, e.dot(g, opts)})
 	}
 
 	// srot these again to match the old output
-	sort.Strings(dotEdges)
+	sort.Slice(dotEdges, func(i, j int) bool {
+		a, b := dotEdges[i], dotEdges[j]
+		if g.less != nil && (a.source != b.source || a.target != b.target) {
+			return g.edgeLess(a.source, a.target, b.source, b.target)
+		}
+		return a.line < b.line
+	})
 
 	for _, e := range dotEdges {
-		w.WriteString(e + "\n")
+		w.WriteString(e.line + "\n")
 	}
+}
 
-	w.Unindent()
-	w.WriteString("}\n")
+// mergeAttrs returns a copy of attrs with the extra attributes added.
+func mergeAttrs(attrs, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return attrs
+	}
+
+	result := make(map[string]string, len(attrs)+len(extra))
+	for k, v := range attrs {
+		result[k] = v
+	}
+	for k, v := range extra {
+		result[k] = v
+	}
+	return result
 }
 
 func writeAttrs(buf *bytes.Buffer, attrs map[string]string) {
@@ -1,7 +1,9 @@
 package dagg
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -41,3 +43,127 @@ func (v *testDotVertex) DotNode(title string, opts *DotOpts) *DotNode {
 	v.DotNodeOpts = opts
 	return v.DotNodeReturn
 }
+
+const testGraphDotUndirectedStr = `digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] a"
+		"[root] b"
+		"[root] a" -> "[root] b" [dir = "none"]
+	}
+}`
+
+const testGraphDotEdgeDataStr = `digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] a"
+		"[root] b"
+		"[root] a" -> "[root] b" [label = "42"]
+	}
+}`
+
+func TestGraphDot_attrCallbacks(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(LabeledEdge(myint(2), myint(3), 5))
+
+	opts := &DotOpts{
+		VertexAttrs: func(v interface{}) map[string]string {
+			if v.(myint) == 2 {
+				return map[string]string{"color": "red"}
+			}
+			return nil
+		},
+		EdgeAttrs: func(e interface{}) map[string]string {
+			if data, ok := e.(DataEdge); ok {
+				return map[string]string{"weight": fmt.Sprint(data.EdgeData())}
+			}
+			return nil
+		},
+	}
+
+	actual := strings.TrimSpace(string(g.Dot(opts)))
+	expected := strings.TrimSpace(testGraphDotAttrCallbacksStr)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+}
+
+const testGraphDotAttrCallbacksStr = `
+digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] 2" [color = "red"]
+		"[root] 1" -> "[root] 2"
+		"[root] 2" -> "[root] 3" [label = "5", weight = "5"]
+	}
+}
+`
+
+type testSubgraphVertex struct {
+	name string
+	sub  *Graph[*testSubgraphVertex]
+}
+
+func (v *testSubgraphVertex) Hashcode() string  { return v.name }
+func (v *testSubgraphVertex) Subgraph() Grapher { return v.sub }
+
+func TestGraphDot_subgraphs(t *testing.T) {
+	deepest := &Graph[*testSubgraphVertex]{}
+	deepest.Add(&testSubgraphVertex{name: "z"})
+
+	inner := &Graph[*testSubgraphVertex]{}
+	x := inner.Add(&testSubgraphVertex{name: "x", sub: deepest})
+	y := inner.Add(&testSubgraphVertex{name: "y"})
+	inner.Connect(BasicEdge(x, y))
+
+	var g Graph[*testSubgraphVertex]
+	a := g.Add(&testSubgraphVertex{name: "a", sub: inner})
+	b := g.Add(&testSubgraphVertex{name: "b"})
+	g.Connect(BasicEdge(a, b))
+
+	actual := strings.TrimSpace(string(g.Dot(&DotOpts{MaxDepth: -1})))
+	expected := strings.TrimSpace(testGraphDotSubgraphsStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	actual = strings.TrimSpace(string(g.Dot(&DotOpts{MaxDepth: 1})))
+	expected = strings.TrimSpace(testGraphDotSubgraphsDepthStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+const testGraphDotSubgraphsStr = `digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] a" -> "[root] b"
+	}
+	subgraph "cluster_a" {
+		label = "a"
+		"[a] x" -> "[a] y"
+		subgraph "cluster_x" {
+			label = "x"
+		}
+	}
+}`
+
+const testGraphDotSubgraphsDepthStr = `digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] a" -> "[root] b"
+	}
+	subgraph "cluster_a" {
+		label = "a"
+		"[a] x" -> "[a] y"
+	}
+}`
@@ -0,0 +1,427 @@
+package dagg
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ParseDot reads a graph in the DOT language, such as one written by Dot,
+// calling decode to turn the name of each node into a vertex. decode is
+// called once for each distinct name. Names of the form "[graph] name", as
+// written by Dot, are given to decode without the "[graph] " prefix.
+//
+// Subgraphs are flattened into the one graph, and attributes are ignored,
+// except for edges: those with a "label" are returned as a LabeledEdge with
+// the label as the data, and those in an undirected graph or with
+// dir = "none" are returned as an UndirectedEdge.
+func ParseDot[T Hashable](r io.Reader, decode func(name string) (T, error)) (*Graph[T], error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := dotTokenize(string(src))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &dotParser[T]{
+		tokens:   tokens,
+		decode:   decode,
+		vertices: make(map[string]T),
+		g:        &Graph[T]{},
+	}
+	if err := p.parseGraph(); err != nil {
+		return nil, err
+	}
+	return p.g, nil
+}
+
+// dotToken is a single token of the DOT language. Quoted strings are
+// unquoted, and marked as quoted so they're never taken as a keyword.
+type dotToken struct {
+	text   string
+	quoted bool
+	line   int
+}
+
+func (t dotToken) is(s string) bool {
+	return !t.quoted && t.text == s
+}
+
+func (t dotToken) keyword(s string) bool {
+	return !t.quoted && strings.EqualFold(t.text, s)
+}
+
+func dotTokenize(src string) ([]dotToken, error) {
+	var tokens []dotToken
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+
+		case unicode.IsSpace(rune(c)):
+			i++
+
+		case c == '#' || strings.HasPrefix(src[i:], "//"):
+			// line comment
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+
+		case strings.HasPrefix(src[i:], "->") || strings.HasPrefix(src[i:], "--"):
+			tokens = append(tokens, dotToken{text: src[i : i+2], line: line})
+			i += 2
+
+		case strings.ContainsRune("{}[]=;,:", rune(c)):
+			tokens = append(tokens, dotToken{text: string(c), line: line})
+			i++
+
+		case c == '"':
+			var b strings.Builder
+			start := line
+			i++
+			for {
+				if i >= len(src) {
+					return nil, fmt.Errorf("line %d: unterminated string", start)
+				}
+				if src[i] == '"' {
+					i++
+					break
+				}
+				if src[i] == '\\' && i+1 < len(src) {
+					switch src[i+1] {
+					case '"', '\\':
+						b.WriteByte(src[i+1])
+						i += 2
+						continue
+					case '\n':
+						// line continuation
+						line++
+						i += 2
+						continue
+					}
+				}
+				if src[i] == '\n' {
+					line++
+				}
+				b.WriteByte(src[i])
+				i++
+			}
+			tokens = append(tokens, dotToken{text: b.String(), quoted: true, line: start})
+
+		case c == '<':
+			// HTML string, kept whole
+			depth := 0
+			start := i
+			for ; i < len(src); i++ {
+				if src[i] == '<' {
+					depth++
+				} else if src[i] == '>' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated HTML string", line)
+			}
+			i++
+			tokens = append(tokens, dotToken{text: src[start:i], quoted: true, line: line})
+
+		case c == '_' || c == '.' || c == '-' || c >= 0x80 ||
+			unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)):
+			start := i
+			for i < len(src) {
+				c := src[i]
+				if c == '_' || c == '.' || c >= 0x80 ||
+					unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) ||
+					c == '-' && i == start {
+					i++
+					continue
+				}
+				break
+			}
+			tokens = append(tokens, dotToken{text: src[start:i], line: line})
+
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+		}
+	}
+	return tokens, nil
+}
+
+type dotParser[T Hashable] struct {
+	tokens     []dotToken
+	pos        int
+	undirected bool
+
+	decode   func(string) (T, error)
+	vertices map[string]T
+	g        *Graph[T]
+}
+
+func (p *dotParser[T]) peek() (dotToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return dotToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *dotParser[T]) next() (dotToken, error) {
+	t, ok := p.peek()
+	if !ok {
+		return t, fmt.Errorf("unexpected end of input")
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *dotParser[T]) expect(s string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if !t.is(s) {
+		return fmt.Errorf("line %d: expected %q, found %q", t.line, s, t.text)
+	}
+	return nil
+}
+
+// accept consumes the next token if it is s.
+func (p *dotParser[T]) accept(s string) bool {
+	if t, ok := p.peek(); ok && t.is(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *dotParser[T]) parseGraph() error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.keyword("strict") {
+		if t, err = p.next(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case t.keyword("digraph"):
+	case t.keyword("graph"):
+		p.undirected = true
+	default:
+		return fmt.Errorf("line %d: expected graph or digraph, found %q", t.line, t.text)
+	}
+
+	// optional graph ID
+	if t, ok := p.peek(); ok && !t.is("{") {
+		p.pos++
+	}
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	if _, err := p.parseStmts(); err != nil {
+		return err
+	}
+	if err := p.expect("}"); err != nil {
+		return err
+	}
+
+	if t, ok := p.peek(); ok {
+		return fmt.Errorf("line %d: unexpected %q after graph", t.line, t.text)
+	}
+	return nil
+}
+
+// parseStmts parses statements up to a closing brace, returning every
+// vertex named in them.
+func (p *dotParser[T]) parseStmts() ([]T, error) {
+	var result []T
+	for {
+		t, ok := p.peek()
+		if !ok || t.is("}") {
+			return result, nil
+		}
+		if p.accept(";") {
+			continue
+		}
+
+		vs, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, vs...)
+	}
+}
+
+func (p *dotParser[T]) parseStmt() ([]T, error) {
+	t, _ := p.peek()
+
+	// attribute statements
+	if t.keyword("graph") || t.keyword("node") || t.keyword("edge") {
+		p.pos++
+		_, err := p.parseAttrs()
+		return nil, err
+	}
+	if p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].is("=") {
+		p.pos += 2
+		_, err := p.next()
+		return nil, err
+	}
+
+	// nodes and edges
+	var all []T
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	all = append(all, left...)
+
+	var pairs [][2][]T
+	for {
+		t, ok := p.peek()
+		if !ok || !(t.is("->") || t.is("--")) {
+			break
+		}
+		p.pos++
+
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, right...)
+		pairs = append(pairs, [2][]T{left, right})
+		left = right
+	}
+
+	attrs, err := p.parseAttrs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pair := range pairs {
+		for _, s := range pair[0] {
+			for _, t := range pair[1] {
+				p.g.Connect(p.edge(s, t, attrs))
+			}
+		}
+	}
+	return all, nil
+}
+
+// edge returns the edge for the attributes.
+func (p *dotParser[T]) edge(source, target T, attrs map[string]string) Edge[T] {
+	if p.undirected || attrs["dir"] == "none" {
+		return UndirectedEdge(source, target)
+	}
+	if label, ok := attrs["label"]; ok {
+		return LabeledEdge(source, target, label)
+	}
+	return BasicEdge(source, target)
+}
+
+// parseOperand parses a node ID or a subgraph, returning the vertices.
+func (p *dotParser[T]) parseOperand() ([]T, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.keyword("subgraph") || t.is("{") {
+		if t.keyword("subgraph") {
+			// optional subgraph ID
+			if t, ok := p.peek(); ok && !t.is("{") {
+				p.pos++
+			}
+			if err := p.expect("{"); err != nil {
+				return nil, err
+			}
+		}
+
+		vs, err := p.parseStmts()
+		if err != nil {
+			return nil, err
+		}
+		return vs, p.expect("}")
+	}
+
+	if !t.quoted && strings.ContainsAny(t.text, "{}[]=;,:") {
+		return nil, fmt.Errorf("line %d: unexpected %q", t.line, t.text)
+	}
+
+	// ports are ignored
+	for p.accept(":") {
+		if _, err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	v, err := p.vertex(t.text)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %s", t.line, err)
+	}
+	return []T{v}, nil
+}
+
+// parseAttrs parses any attribute lists.
+func (p *dotParser[T]) parseAttrs() (map[string]string, error) {
+	attrs := make(map[string]string)
+	for p.accept("[") {
+		for !p.accept("]") {
+			key, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			value, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			attrs[key.text] = value.text
+
+			if !p.accept(",") {
+				p.accept(";")
+			}
+		}
+	}
+	return attrs, nil
+}
+
+// dotGraphPrefix matches the graph name prefix written by Dot.
+var dotGraphPrefix = regexp.MustCompile(`^\[[^\]]*\] `)
+
+// vertex returns the vertex for the name, adding it to the graph.
+func (p *dotParser[T]) vertex(name string) (T, error) {
+	name = dotGraphPrefix.ReplaceAllString(name, "")
+	if v, ok := p.vertices[name]; ok {
+		return v, nil
+	}
+
+	v, err := p.decode(name)
+	if err != nil {
+		return v, err
+	}
+	p.vertices[name] = v
+	p.g.Add(v)
+	return v, nil
+}
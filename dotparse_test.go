@@ -0,0 +1,127 @@
+package dagg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseDot_roundTrip(t *testing.T) {
+	var g Graph[mystr]
+	g.Add("a")
+	g.Add("b")
+	g.Add("c")
+	g.Add(`d "quoted"`)
+	g.Connect(BasicEdge[mystr]("a", "b"))
+	g.Connect(LabeledEdge[mystr]("b", "c", 42))
+	g.Connect(UndirectedEdge[mystr]("c", `d "quoted"`))
+
+	expected := string(g.Dot(nil))
+	parsed, err := ParseDot(strings.NewReader(expected), func(name string) (mystr, error) {
+		return mystr(name), nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := string(parsed.Dot(nil))
+	if actual != expected {
+		t.Fatalf("bad:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestParseDot(t *testing.T) {
+	calls := 0
+	g, err := ParseDot(strings.NewReader(testParseDotStr), func(name string) (mystr, error) {
+		calls++
+		return mystr(name), nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if calls != 6 {
+		t.Fatalf("decode called %d times", calls)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testParseDotGraphStr)
+	if actual != expected {
+		t.Fatalf("bad:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestParseDot_undirected(t *testing.T) {
+	g, err := ParseDot(strings.NewReader(`graph { a -- b }`), func(name string) (mystr, error) {
+		return mystr(name), nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !g.HasEdge(UndirectedEdge[mystr]("b", "a")) {
+		t.Fatalf("bad: %s", g.String())
+	}
+}
+
+func TestParseDot_decodeError(t *testing.T) {
+	_, err := ParseDot(strings.NewReader("digraph {\n a -> b\n}"), func(name string) (mystr, error) {
+		if name == "b" {
+			return "", errors.New("bad name")
+		}
+		return mystr(name), nil
+	})
+	if err == nil || err.Error() != "line 2: bad name" {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+func TestParseDot_syntaxError(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`digraph`,
+		`digraph { a -> }`,
+		`digraph { a [label] }`,
+		`digraph { "a }`,
+		`digraph { a } b`,
+		`tree { a }`,
+	} {
+		_, err := ParseDot(strings.NewReader(src), func(name string) (mystr, error) {
+			return mystr(name), nil
+		})
+		if err == nil {
+			t.Fatalf("expected error for %q", src)
+		}
+	}
+}
+
+const testParseDotStr = `
+/* a hand written graph */
+strict digraph "test" {
+	rankdir = LR;
+	node [shape = box]
+
+	# plain and quoted IDs
+	a -> "b" -> c [color = red];
+	c -> d [label = "c to d"]
+
+	// edges to and from subgraphs
+	subgraph cluster_0 {
+		label = "cluster"
+		e; f:port
+	}
+	d -> { e f }
+}
+`
+
+const testParseDotGraphStr = `
+a
+  b
+b
+  c
+c
+  d (c to d)
+d
+  e
+  f
+e
+f
+`
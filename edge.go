@@ -12,6 +12,22 @@ type Edge[T Hashable] interface {
 	Hashable
 }
 
+// DirectedEdge can be implemented by an Edge to declare whether it is
+// directed. Edges that don't implement DirectedEdge are directed.
+type DirectedEdge interface {
+	Directed() bool
+}
+
+// IsDirected returns true if the edge is directed.
+func IsDirected[T Hashable](e Edge[T]) bool {
+	var raw interface{}
+	raw = e
+	if d, ok := raw.(DirectedEdge); ok {
+		return d.Directed()
+	}
+	return true
+}
+
 // BasicEdge returns an Edge implementation that simply tracks the source
 // and target given as-is.
 func BasicEdge[T Hashable](source, target T) Edge[T] {
@@ -35,3 +51,56 @@ func (e *basicEdge[T]) Source() T {
 func (e *basicEdge[T]) Target() T {
 	return e.Trgt
 }
+
+// DataEdge is implemented by edges which carry arbitrary metadata.
+type DataEdge interface {
+	EdgeData() interface{}
+}
+
+// LabeledEdge returns an Edge implementation which carries the given data.
+// The edge is otherwise identical to BasicEdge(source, target), so the data
+// of an existing edge can only be changed by removing and reconnecting it.
+func LabeledEdge[T Hashable, M any](source, target T, data M) Edge[T] {
+	return &labeledEdge[T, M]{
+		basicEdge: basicEdge[T]{Src: source, Trgt: target},
+		Data:      data,
+	}
+}
+
+// labeledEdge is a basicEdge with some attached data.
+type labeledEdge[T Hashable, M any] struct {
+	basicEdge[T]
+	Data M
+}
+
+func (e *labeledEdge[T, M]) EdgeData() interface{} {
+	return e.Data
+}
+
+// UndirectedEdge returns an Edge implementation for a symmetric relationship
+// between two vertices. UndirectedEdge(a, b) and UndirectedEdge(b, a) are the
+// same edge.
+//
+// Undirected edges are not dependencies: they are ignored when ordering,
+// walking, or looking for cycles in the graph, but are included in
+// Neighbors and Components.
+func UndirectedEdge[T Hashable](a, b T) Edge[T] {
+	return &undirectedEdge[T]{basicEdge[T]{Src: a, Trgt: b}}
+}
+
+// undirectedEdge is a basicEdge without a direction.
+type undirectedEdge[T Hashable] struct {
+	basicEdge[T]
+}
+
+func (e *undirectedEdge[T]) Hashcode() string {
+	a, b := e.Src.Hashcode(), e.Trgt.Hashcode()
+	if b < a {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%s~%s", a, b)
+}
+
+func (e *undirectedEdge[T]) Directed() bool {
+	return false
+}
@@ -28,3 +28,17 @@ func TestBasicEdgeHashcode_pointer(t *testing.T) {
 		t.Fatalf("bad")
 	}
 }
+
+func TestUndirectedEdgeHashcode(t *testing.T) {
+	e1 := UndirectedEdge(myint(1), myint(2))
+	e2 := UndirectedEdge(myint(2), myint(1))
+	if e1.Hashcode() != e2.Hashcode() {
+		t.Fatalf("bad")
+	}
+	if e1.Hashcode() == BasicEdge(myint(1), myint(2)).Hashcode() {
+		t.Fatalf("undirected edge should not match directed edge")
+	}
+	if IsDirected(e1) || !IsDirected(BasicEdge(myint(1), myint(2))) {
+		t.Fatalf("bad directedness")
+	}
+}
@@ -0,0 +1,116 @@
+package dagg
+
+import (
+	"sort"
+	"strconv"
+)
+
+// edgeKey identifies an edge by the hashcodes of its ends. The Hashcode of
+// a BasicEdge joins them with a "-", so the edges between "a-b" and "c" and
+// between "a" and "b-c" share a Hashcode, but they never share an edgeKey.
+// The ends of an undirected edge are ordered, so that both ways round have
+// the same key.
+type edgeKey struct {
+	source, target string
+	undirected     bool
+}
+
+// keyOf returns the edgeKey of an edge.
+func keyOf[T Hashable](e Edge[T]) edgeKey {
+	k := edgeKey{source: e.Source().Hashcode(), target: e.Target().Hashcode()}
+	if !IsDirected(e) {
+		k.undirected = true
+		if k.target < k.source {
+			k.source, k.target = k.target, k.source
+		}
+	}
+	return k
+}
+
+// directedKey returns the edgeKey of the directed edge from source to
+// target.
+func directedKey[T Hashable](source, target T) edgeKey {
+	return edgeKey{source: source.Hashcode(), target: target.Hashcode()}
+}
+
+// String encodes the key as a string, prefixing the source with its length
+// so that no two keys have the same encoding.
+func (k edgeKey) String() string {
+	sep := "-"
+	if k.undirected {
+		sep = "~"
+	}
+	return strconv.Itoa(len(k.source)) + ":" + k.source + sep + k.target
+}
+
+// edgeSet is a set of edges, keyed by edgeKey.
+type edgeSet[T Hashable] map[edgeKey]Edge[T]
+
+// Add adds an edge to the set, replacing any edge with the same key.
+func (s edgeSet[T]) Add(e Edge[T]) {
+	s[keyOf(e)] = e
+}
+
+// Delete removes the edge with the key of e from the set.
+func (s edgeSet[T]) Delete(e Edge[T]) {
+	delete(s, keyOf(e))
+}
+
+// Include returns true if the set has an edge with the key of e.
+func (s edgeSet[T]) Include(e Edge[T]) bool {
+	_, ok := s[keyOf(e)]
+	return ok
+}
+
+// Difference returns a set with the edges that s has but other doesn't.
+func (s edgeSet[T]) Difference(other edgeSet[T]) edgeSet[T] {
+	result := make(edgeSet[T])
+	for k, e := range s {
+		if _, ok := other[k]; !ok {
+			result[k] = e
+		}
+	}
+	return result
+}
+
+// Filter returns a set with the edges for which cb returns true.
+func (s edgeSet[T]) Filter(cb func(Edge[T]) bool) edgeSet[T] {
+	result := make(edgeSet[T])
+	for k, e := range s {
+		if cb(e) {
+			result[k] = e
+		}
+	}
+	return result
+}
+
+// Copy returns a shallow copy of the set.
+func (s edgeSet[T]) Copy() edgeSet[T] {
+	c := make(edgeSet[T], len(s))
+	for k, e := range s {
+		c[k] = e
+	}
+	return c
+}
+
+// Slice returns the edges sorted by Hashcode. Edges which share a Hashcode
+// are ordered by their keys, so the order is always the same.
+func (s edgeSet[T]) Slice() []Edge[T] {
+	keys := make([]edgeKey, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := s[keys[i]].Hashcode(), s[keys[j]].Hashcode()
+		if a != b {
+			return a < b
+		}
+		return keys[i].String() < keys[j].String()
+	})
+
+	result := make([]Edge[T], len(keys))
+	for i, k := range keys {
+		result[i] = s[k]
+	}
+	return result
+}
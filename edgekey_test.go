@@ -0,0 +1,77 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphEdges_hashcodeCollision(t *testing.T) {
+	var g AcyclicGraph[mystr]
+	for _, v := range []mystr{"a-b", "c", "a", "b-c"} {
+		g.Add(v)
+	}
+
+	// both edges have the Hashcode "a-b-c"
+	e1 := BasicEdge[mystr]("a-b", "c")
+	e2 := BasicEdge[mystr]("a", "b-c")
+	if e1.Hashcode() != e2.Hashcode() {
+		t.Fatal("the edges should share a hashcode")
+	}
+
+	g.Connect(e1)
+	g.Connect(e2)
+	if len(g.Edges()) != 2 {
+		t.Fatalf("bad: %v", g.Edges())
+	}
+	if !g.HasEdge(e1) || !g.HasEdge(e2) {
+		t.Fatal("should have both edges")
+	}
+
+	g.RemoveEdge(e1)
+	if g.HasEdge(e1) || !g.HasEdge(e2) {
+		t.Fatal("should only remove the first edge")
+	}
+	if actual := strings.TrimSpace(g.String()); actual != strings.TrimSpace(testGraphEdgesHashcodeCollisionStr) {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// copies, snapshots and immutable graphs keep them apart too
+	g.Connect(e1)
+	if len(g.Copy().Edges()) != 2 || len(NewImmutableGraph(&g.Graph).Edges()) != 2 {
+		t.Fatal("should have both edges")
+	}
+
+	var walked []mystr
+	err := g.Walk(func(v mystr) error {
+		walked = append(walked, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(walked) != 4 {
+		t.Fatalf("bad: %v", walked)
+	}
+}
+
+func TestEdgeKeyString(t *testing.T) {
+	k1 := keyOf(BasicEdge[mystr]("a-b", "c"))
+	k2 := keyOf(BasicEdge[mystr]("a", "b-c"))
+	if k1 == k2 || k1.String() == k2.String() {
+		t.Fatalf("bad: %s %s", k1, k2)
+	}
+
+	u1 := keyOf(UndirectedEdge[mystr]("a", "b"))
+	u2 := keyOf(UndirectedEdge[mystr]("b", "a"))
+	if u1 != u2 || u1 == keyOf(BasicEdge[mystr]("a", "b")) {
+		t.Fatalf("bad: %s %s", u1, u2)
+	}
+}
+
+const testGraphEdgesHashcodeCollisionStr = `
+a
+  b-c
+a-b
+b-c
+c
+`
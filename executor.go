@@ -0,0 +1,98 @@
+package dagg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CapableVertex can be implemented by a vertex which can only be run by an
+// Executor providing every one of the returned capabilities, such as "gpu"
+// or "privileged". Vertices which don't implement CapableVertex can be run
+// by any Executor.
+type CapableVertex interface {
+	Capabilities() []string
+}
+
+// Executor runs the vertices of a walk which it has the capabilities for.
+type Executor[T Hashable] struct {
+	// Name identifies the executor in errors.
+	Name string
+
+	// Provides lists the capabilities of this executor.
+	Provides []string
+
+	// Callback is called for each vertex dispatched to this executor.
+	Callback WalkFunc[T]
+}
+
+// CanRun returns true if the executor provides every capability required by
+// the vertex.
+func (e *Executor[T]) CanRun(v T) bool {
+	var raw interface{}
+	raw = v
+	cv, ok := raw.(CapableVertex)
+	if !ok {
+		return true
+	}
+
+	for _, required := range cv.Capabilities() {
+		found := false
+		for _, p := range e.Provides {
+			if p == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// executorFor returns the first executor which can run v, or nil if there
+// is none.
+func executorFor[T Hashable](executors []*Executor[T], v T) *Executor[T] {
+	for _, e := range executors {
+		if e.CanRun(v) {
+			return e
+		}
+	}
+	return nil
+}
+
+// Unrunnable returns the vertices of g which none of the walker's Executors
+// can run, sorted by name. If the walker has no Executors, every vertex is
+// run by the Callback and nil is returned.
+func (w *Walker[T]) Unrunnable(g *AcyclicGraph[T]) []T {
+	if len(w.Executors) == 0 {
+		return nil
+	}
+
+	var result []T
+	for _, v := range g.vertices {
+		if executorFor(w.Executors, v) == nil {
+			result = append(result, v)
+		}
+	}
+	g.sortByName(result)
+	return result
+}
+
+// WalkExecutors walks the graph like Walk, dispatching each vertex to the
+// first of the executors which can run it. If any vertex can't be run by any
+// of the executors, an error listing them is returned before anything is
+// walked.
+func (g *AcyclicGraph[T]) WalkExecutors(executors []*Executor[T]) error {
+	w := &Walker[T]{Executors: executors, Reverse: true}
+	if unrunnable := w.Unrunnable(g); len(unrunnable) > 0 {
+		names := make([]string, len(unrunnable))
+		for i, v := range unrunnable {
+			names[i] = VertexName(v)
+		}
+		return fmt.Errorf("no executor can run: %s", strings.Join(names, ", "))
+	}
+
+	w.Update(g)
+	return w.Wait()
+}
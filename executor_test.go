@@ -0,0 +1,73 @@
+package dagg
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type capableVertex struct {
+	name string
+	caps []string
+}
+
+func (v capableVertex) Hashcode() string       { return v.name }
+func (v capableVertex) Name() string           { return v.name }
+func (v capableVertex) Capabilities() []string { return v.caps }
+
+func TestWalkExecutors(t *testing.T) {
+	var g AcyclicGraph[capableVertex]
+	train := capableVertex{"train", []string{"gpu"}}
+	fetch := capableVertex{"fetch", nil}
+	g.Add(train)
+	g.Add(fetch)
+	g.Connect(BasicEdge(train, fetch))
+
+	var lock sync.Mutex
+	ran := make(map[string]string)
+	record := func(name string) WalkFunc[capableVertex] {
+		return func(v capableVertex) error {
+			lock.Lock()
+			defer lock.Unlock()
+			ran[v.name] = name
+			return nil
+		}
+	}
+
+	cpu := &Executor[capableVertex]{Name: "cpu", Callback: record("cpu")}
+	gpu := &Executor[capableVertex]{Name: "gpu", Provides: []string{"gpu"}, Callback: record("gpu")}
+
+	err := g.WalkExecutors([]*Executor[capableVertex]{cpu, gpu})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]string{"train": "gpu", "fetch": "cpu"}
+	if !reflect.DeepEqual(ran, expected) {
+		t.Fatalf("bad: %#v", ran)
+	}
+}
+
+func TestWalkExecutors_unrunnable(t *testing.T) {
+	var g AcyclicGraph[capableVertex]
+	g.Add(capableVertex{"train", []string{"gpu"}})
+	g.Add(capableVertex{"deploy", []string{"privileged"}})
+	g.Add(capableVertex{"fetch", nil})
+
+	called := false
+	cpu := &Executor[capableVertex]{
+		Name:     "cpu",
+		Callback: func(capableVertex) error { called = true; return nil },
+	}
+
+	err := g.WalkExecutors([]*Executor[capableVertex]{cpu})
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if err.Error() != "no executor can run: deploy, train" {
+		t.Fatalf("bad: %s", err)
+	}
+	if called {
+		t.Fatal("nothing should be walked")
+	}
+}
@@ -0,0 +1,168 @@
+package dagg
+
+import (
+	"container/heap"
+	"time"
+)
+
+// ExpiringEdge is implemented by edges which only last until a given time,
+// such as an edge modelling a lease. Expiry is only applied by Sweep, and by
+// WalkWithOpts before it starts if WalkOpts.Sweep is set: until then an
+// expired edge stays in the graph like any other. Connecting an expiring
+// edge which is already in the graph replaces it, so a lease is renewed by
+// connecting it again with a later expiry time.
+type ExpiringEdge interface {
+	Expires() time.Time
+}
+
+// TemporaryEdge returns an Edge which expires at the given time. The edge
+// is otherwise identical to BasicEdge(source, target).
+func TemporaryEdge[T Hashable](source, target T, expires time.Time) Edge[T] {
+	return &temporaryEdge[T]{
+		basicEdge: basicEdge[T]{Src: source, Trgt: target},
+		At:        expires,
+	}
+}
+
+// temporaryEdge is a basicEdge with an expiry time.
+type temporaryEdge[T Hashable] struct {
+	basicEdge[T]
+	At time.Time
+}
+
+func (e *temporaryEdge[T]) Expires() time.Time {
+	return e.At
+}
+
+// TemporaryLabeledEdge returns an Edge which carries the given data, as
+// LabeledEdge, and expires at the given time, as TemporaryEdge.
+func TemporaryLabeledEdge[T Hashable, M any](source, target T, data M, expires time.Time) Edge[T] {
+	return &temporaryLabeledEdge[T, M]{
+		labeledEdge: labeledEdge[T, M]{
+			basicEdge: basicEdge[T]{Src: source, Trgt: target},
+			Data:      data,
+		},
+		At: expires,
+	}
+}
+
+// temporaryLabeledEdge is a labeledEdge with an expiry time.
+type temporaryLabeledEdge[T Hashable, M any] struct {
+	labeledEdge[T, M]
+	At time.Time
+}
+
+func (e *temporaryLabeledEdge[T, M]) Expires() time.Time {
+	return e.At
+}
+
+// edgeExpiry is the expiry time of an edge, by edge key.
+type edgeExpiry struct {
+	at   time.Time
+	edge edgeKey
+}
+
+// edgeExpiries is a min-heap of edge expiry times, with an entry for each
+// ExpiringEdge in the graph. Entries are dropped when their edges are
+// removed or replaced.
+type edgeExpiries []edgeExpiry
+
+func (h edgeExpiries) Len() int            { return len(h) }
+func (h edgeExpiries) Less(i, j int) bool  { return h[i].at.Before(h[j].at) }
+func (h edgeExpiries) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *edgeExpiries) Push(x interface{}) { *h = append(*h, x.(edgeExpiry)) }
+func (h *edgeExpiries) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func (h edgeExpiries) copy() edgeExpiries {
+	if h == nil {
+		return nil
+	}
+	return append(edgeExpiries(nil), h...)
+}
+
+// trackExpiry records the expiry time of an ExpiringEdge.
+func (g *Graph[T]) trackExpiry(edge Edge[T]) {
+	var raw interface{}
+	raw = edge
+	if ee, ok := raw.(ExpiringEdge); ok {
+		heap.Push(&g.expiries, edgeExpiry{at: ee.Expires(), edge: keyOf(edge)})
+	}
+}
+
+// forgetExpiry drops the entry for the edge with key k, if it has one.
+//
+// Complexity: O(k) for k expiring edges
+func (g *Graph[T]) forgetExpiry(k edgeKey) {
+	for i, x := range g.expiries {
+		if x.edge == k {
+			heap.Remove(&g.expiries, i)
+			return
+		}
+	}
+}
+
+// refreshExpiry replaces the expiring edge in the graph with the key of
+// edge by edge, if edge expires at a different time. It returns true if the
+// edge was replaced.
+func (g *Graph[T]) refreshExpiry(edge Edge[T]) bool {
+	var raw interface{}
+	raw = edge
+	ee, ok := raw.(ExpiringEdge)
+	if !ok {
+		return false
+	}
+	k := keyOf(edge)
+	raw = g.edges[k]
+	old, ok := raw.(ExpiringEdge)
+	if !ok || old.Expires().Equal(ee.Expires()) {
+		return false
+	}
+
+	g.forgetExpiry(k)
+	g.edges.Add(edge)
+	g.trackExpiry(edge)
+	return true
+}
+
+// Sweep removes the edges which have expired, returning them.
+func (g *Graph[T]) Sweep() []Edge[T] {
+	return g.SweepAt(time.Now())
+}
+
+// SweepAt removes the edges which expire at or before now, returning them.
+// The graph's mutation hooks are called with MutationExpireEdge for each
+// one.
+//
+// Complexity: O(k log E) to remove k edges
+func (g *Graph[T]) SweepAt(now time.Time) []Edge[T] {
+	if len(g.expiries) == 0 || g.expiries[0].at.After(now) {
+		return nil
+	}
+
+	g.unshare()
+	var result []Edge[T]
+	for len(g.expiries) > 0 && !g.expiries[0].at.After(now) {
+		x := heap.Pop(&g.expiries).(edgeExpiry)
+
+		// the entries of removed and replaced edges are dropped, but the
+		// edge is checked in case it was changed some other way
+		e, ok := g.edges[x.edge]
+		if !ok {
+			continue
+		}
+		var raw interface{}
+		raw = e
+		if ee, ok := raw.(ExpiringEdge); !ok || !ee.Expires().Equal(x.at) {
+			continue
+		}
+
+		g.removeEdge(e, MutationExpireEdge)
+		result = append(result, e)
+	}
+	return result
+}
@@ -0,0 +1,174 @@
+package dagg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGraphSweepAt(t *testing.T) {
+	start := time.Unix(0, 0)
+
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(TemporaryEdge(myint(1), myint(2), start.Add(time.Minute)))
+	g.Connect(TemporaryEdge(myint(1), myint(3), start.Add(time.Hour)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+
+	var expired []Mutation[myint]
+	g.addHook(func(m Mutation[myint]) {
+		expired = append(expired, m)
+	})
+
+	if edges := g.SweepAt(start); len(edges) != 0 {
+		t.Fatalf("bad: %v", edges)
+	}
+
+	edges := g.SweepAt(start.Add(time.Minute))
+	if len(edges) != 1 || edges[0].Hashcode() != BasicEdge(myint(1), myint(2)).Hashcode() {
+		t.Fatalf("bad: %v", edges)
+	}
+	if len(expired) != 1 || expired[0].Op != MutationExpireEdge {
+		t.Fatalf("bad: %#v", expired)
+	}
+
+	// a removed edge is forgotten, and a reconnected edge keeps its new
+	// expiry
+	g.RemoveEdge(BasicEdge(myint(1), myint(3)))
+	g.Connect(TemporaryEdge(myint(1), myint(3), start.Add(2*time.Hour)))
+	if edges := g.SweepAt(start.Add(time.Hour)); len(edges) != 0 {
+		t.Fatalf("bad: %v", edges)
+	}
+	if !g.HasEdge(BasicEdge(myint(1), myint(3))) {
+		t.Fatal("should keep the reconnected edge")
+	}
+
+	if edges := g.SweepAt(start.Add(3 * time.Hour)); len(edges) != 1 {
+		t.Fatalf("bad: %v", edges)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	if actual != "1\n2\n  3\n3" {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestGraphConnect_refreshExpiry(t *testing.T) {
+	start := time.Unix(0, 0)
+
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(TemporaryEdge(myint(1), myint(2), start.Add(time.Minute)))
+	g.Connect(TemporaryEdge(myint(1), myint(3), start.Add(time.Minute)))
+
+	// reconnecting renews the lease
+	g.Connect(TemporaryEdge(myint(1), myint(2), start.Add(time.Hour)))
+	if len(g.expiries) != 2 {
+		t.Fatalf("bad: %#v", g.expiries)
+	}
+	if edges := g.SweepAt(start.Add(time.Minute)); len(edges) != 1 || edges[0].Target() != myint(3) {
+		t.Fatalf("bad: %v", edges)
+	}
+	if !g.HasEdge(BasicEdge(myint(1), myint(2))) {
+		t.Fatal("should keep the renewed edge")
+	}
+
+	// removing the edge drops its expiry
+	g.RemoveEdge(BasicEdge(myint(1), myint(2)))
+	if len(g.expiries) != 0 {
+		t.Fatalf("bad: %#v", g.expiries)
+	}
+}
+
+func TestGraphSweep_snapshot(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(TemporaryEdge(myint(1), myint(2), time.Now().Add(-time.Second)))
+
+	snapshot := g.ReadSnapshot()
+	if edges := g.Sweep(); len(edges) != 1 {
+		t.Fatalf("bad: %v", edges)
+	}
+	if !snapshot.HasEdge(BasicEdge(myint(1), myint(2))) {
+		t.Fatal("sweeping should not modify the snapshot")
+	}
+	if edges := snapshot.Sweep(); len(edges) != 1 {
+		t.Fatalf("bad: %v", edges)
+	}
+}
+
+func TestAcyclicGraphWalk_sweep(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(TemporaryEdge(myint(1), myint(2), time.Now().Add(-time.Second)))
+
+	// walking doesn't modify the graph unless asked to
+	if err := g.Walk(func(myint) error { return nil }); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(g.Edges()) != 1 {
+		t.Fatalf("bad: %v", g.Edges())
+	}
+
+	if err := g.WalkWithOpts(func(myint) error { return nil }, &WalkOpts[myint]{Sweep: true}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(g.Edges()) != 0 {
+		t.Fatalf("bad: %v", g.Edges())
+	}
+}
+
+func TestGraphPersist_expiry(t *testing.T) {
+	expires := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var g Graph[mystr]
+	var buf bytes.Buffer
+	ps := g.Persist(&LogPersister{W: &buf}, &PersistOpts[mystr]{Encode: encodeMystr})
+	g.Add("a")
+	g.Add("b")
+	g.Connect(TemporaryEdge[mystr]("a", "b", expires))
+	if err := ps.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var replayed Graph[mystr]
+	if err := ReplayLog(&buf, &replayed, decodeMystr); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if edges := replayed.SweepAt(expires); len(edges) != 1 {
+		t.Fatalf("bad: %v", edges)
+	}
+}
+
+func TestGraphPersist_expiryData(t *testing.T) {
+	expires := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// nil opts encode the vertices by Hashcode
+	var g Graph[mystr]
+	var buf bytes.Buffer
+	ps := g.Persist(&LogPersister{W: &buf}, nil)
+	g.Add("a")
+	g.Add("b")
+	g.Connect(TemporaryLabeledEdge[mystr]("a", "b", 42, expires))
+	if err := ps.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var replayed Graph[mystr]
+	if err := ReplayLog(&buf, &replayed, decodeMystr); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if data, ok := replayed.EdgeData("a", "b"); !ok || data != "42" {
+		t.Fatalf("bad: %v", data)
+	}
+	if edges := replayed.SweepAt(expires); len(edges) != 1 {
+		t.Fatalf("bad: %v", edges)
+	}
+}
@@ -0,0 +1,75 @@
+package dagg
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ExternalVertex can be implemented by a vertex representing an external
+// precondition, such as an object existing in storage. Instead of running
+// the walker's callback for it, the walker polls it until it is satisfied,
+// backing off according to its PollPolicy.
+type ExternalVertex interface {
+	// Poll returns true once the precondition is satisfied. An error fails
+	// the vertex without polling again.
+	Poll(context.Context) (bool, error)
+
+	PollPolicy() PollPolicy
+}
+
+// PollPolicy describes how an ExternalVertex is polled.
+type PollPolicy struct {
+	// Interval is the time to wait between the first polls. It defaults to
+	// one second.
+	Interval time.Duration
+
+	// Multiplier, if greater than one, multiplies the interval after each
+	// poll, up to MaxInterval if that is set.
+	Multiplier  float64
+	MaxInterval time.Duration
+
+	// Timeout, if set, fails the vertex if it isn't satisfied in time.
+	Timeout time.Duration
+}
+
+// poll polls an ExternalVertex until it is satisfied, fails, or times out.
+func poll[T Hashable](ctx context.Context, v T, ev ExternalVertex) error {
+	policy := ev.PollPolicy()
+	interval := policy.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	var timeout <-chan time.Time
+	if policy.Timeout > 0 {
+		timer := time.NewTimer(policy.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		ok, err := ev.Poll(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for %q", VertexName(v))
+		case <-time.After(interval):
+		}
+
+		if policy.Multiplier > 1 {
+			interval = time.Duration(float64(interval) * policy.Multiplier)
+			if policy.MaxInterval > 0 && interval > policy.MaxInterval {
+				interval = policy.MaxInterval
+			}
+		}
+	}
+}
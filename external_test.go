@@ -0,0 +1,78 @@
+package dagg
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testExternalVertex struct {
+	name   string
+	ready  int
+	polls  int
+	policy PollPolicy
+}
+
+func (v *testExternalVertex) Hashcode() string       { return v.name }
+func (v *testExternalVertex) Name() string           { return v.name }
+func (v *testExternalVertex) PollPolicy() PollPolicy { return v.policy }
+func (v *testExternalVertex) Poll(context.Context) (bool, error) {
+	v.polls++
+	return v.ready > 0 && v.polls >= v.ready, nil
+}
+
+func TestWalker_externalVertex(t *testing.T) {
+	object := &testExternalVertex{
+		name:   "object",
+		ready:  3,
+		policy: PollPolicy{Interval: time.Millisecond, Multiplier: 2},
+	}
+
+	var g AcyclicGraph[Hashable]
+	g.Add(object)
+	g.Add(myint(1))
+	g.Connect(BasicEdge[Hashable](myint(1), object))
+
+	var lock sync.Mutex
+	var called []Hashable
+	w := &Walker[Hashable]{
+		Reverse: true,
+		Callback: func(v Hashable) error {
+			lock.Lock()
+			defer lock.Unlock()
+			called = append(called, v)
+			return nil
+		},
+	}
+	w.Update(&g)
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if object.polls != 3 {
+		t.Fatalf("bad polls: %d", object.polls)
+	}
+	if !reflect.DeepEqual(called, []Hashable{myint(1)}) {
+		t.Fatalf("bad: %#v", called)
+	}
+}
+
+func TestWalker_externalVertexTimeout(t *testing.T) {
+	object := &testExternalVertex{
+		name:   "object",
+		policy: PollPolicy{Interval: time.Millisecond, Timeout: 10 * time.Millisecond},
+	}
+
+	var g AcyclicGraph[*testExternalVertex]
+	g.Add(object)
+
+	w := &Walker[*testExternalVertex]{}
+	w.Update(&g)
+	err := w.Wait()
+	if err == nil || !strings.Contains(err.Error(), `timed out waiting for "object"`) {
+		t.Fatalf("bad: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package dagg
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fault describes a failure to inject into a vertex during a walk. The
+// Delay is applied first, then the vertex panics with Panic if it is set,
+// otherwise it fails with Err if it is set.
+type Fault struct {
+	Delay time.Duration
+	Panic interface{}
+	Err   error
+}
+
+// FaultInjector is a test harness which injects Faults into selected
+// vertices of a walk, recording what happened to every vertex so error
+// handling policies can be tested deterministically.
+type FaultInjector[T Hashable] struct {
+	lock   sync.Mutex
+	faults map[string]Fault
+	ran    Set[T]
+	failed Set[T]
+}
+
+// FaultReport is the result of a walk with a FaultInjector. Vertices are
+// sorted by name.
+type FaultReport[T Hashable] struct {
+	// Ran holds every vertex which was called, including those that failed.
+	Ran []T
+
+	// Failed holds the vertices which returned an error or panicked.
+	Failed []T
+
+	// Skipped holds the vertices which were never called.
+	Skipped []T
+
+	// Err is the error returned by the walk.
+	Err error
+}
+
+// Inject sets the fault to apply when v is walked.
+func (f *FaultInjector[T]) Inject(v T, fault Fault) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.faults == nil {
+		f.faults = make(map[string]Fault)
+	}
+	f.faults[v.Hashcode()] = fault
+}
+
+// Wrap returns a callback which applies any injected fault before calling
+// cb. Panics, whether injected or from cb, are recovered and returned as
+// errors. A nil cb is treated as a callback which always succeeds.
+func (f *FaultInjector[T]) Wrap(cb WalkFunc[T]) WalkFunc[T] {
+	return func(v T) error {
+		return f.inject(v, func() error {
+			if cb == nil {
+				return nil
+			}
+			return cb(v)
+		})
+	}
+}
+
+// inject applies any fault injected into v before calling run, and records
+// what happened to v.
+func (f *FaultInjector[T]) inject(v T, run func() error) (err error) {
+	f.lock.Lock()
+	if f.ran == nil {
+		f.ran = make(Set[T])
+		f.failed = make(Set[T])
+	}
+	f.ran.Add(v)
+	fault := f.faults[v.Hashcode()]
+	f.lock.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic in %q: %v", VertexName(v), r)
+		}
+		if err != nil {
+			f.lock.Lock()
+			f.failed.Add(v)
+			f.lock.Unlock()
+		}
+	}()
+
+	time.Sleep(fault.Delay)
+	if fault.Panic != nil {
+		panic(fault.Panic)
+	}
+	if fault.Err != nil {
+		return fault.Err
+	}
+	return run()
+}
+
+// Walk walks g with the given walker and reports the result. Faults are
+// injected wherever the walker runs a vertex, so vertices run by its
+// Executors or HeartbeatCallback, and those of its SubWalks, are covered
+// as well as its Callback. If cb is set it replaces the Callback, and if
+// the walker has no Callback either, vertices which reach the Callback
+// succeed.
+func (f *FaultInjector[T]) Walk(w *Walker[T], g *AcyclicGraph[T], cb WalkFunc[T]) *FaultReport[T] {
+	if cb != nil {
+		w.Callback = cb
+	}
+	if w.Callback == nil {
+		w.Callback = func(T) error { return nil }
+	}
+	w.dispatch = f.inject
+	w.Update(g)
+	err := w.Wait()
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	report := &FaultReport[T]{Err: err}
+	for _, v := range g.vertices {
+		switch {
+		case f.failed.Include(v):
+			report.Ran = append(report.Ran, v)
+			report.Failed = append(report.Failed, v)
+		case f.ran.Include(v):
+			report.Ran = append(report.Ran, v)
+		default:
+			report.Skipped = append(report.Skipped, v)
+		}
+	}
+	sort.Sort(byVertexName[T](report.Ran))
+	sort.Sort(byVertexName[T](report.Failed))
+	sort.Sort(byVertexName[T](report.Skipped))
+	return report
+}
@@ -0,0 +1,99 @@
+package dagg
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFaultInjector(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 5; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(BasicEdge(myint(3), myint(2)))
+	g.Connect(BasicEdge(myint(5), myint(4)))
+
+	var f FaultInjector[myint]
+	f.Inject(myint(1), Fault{Delay: time.Millisecond, Err: fmt.Errorf("injected")})
+	f.Inject(myint(4), Fault{Panic: "boom"})
+
+	report := f.Walk(&Walker[myint]{Reverse: true}, &g, nil)
+
+	if !reflect.DeepEqual(report.Ran, []myint{1, 4}) {
+		t.Fatalf("bad ran: %#v", report.Ran)
+	}
+	if !reflect.DeepEqual(report.Failed, []myint{1, 4}) {
+		t.Fatalf("bad failed: %#v", report.Failed)
+	}
+	if !reflect.DeepEqual(report.Skipped, []myint{2, 3, 5}) {
+		t.Fatalf("bad skipped: %#v", report.Skipped)
+	}
+	if report.Err == nil || !strings.Contains(report.Err.Error(), `panic in "4": boom`) {
+		t.Fatalf("bad err: %v", report.Err)
+	}
+}
+
+func TestFaultInjector_dispatch(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+
+	var f FaultInjector[myint]
+	f.Inject(myint(3), Fault{Err: fmt.Errorf("injected")})
+
+	// the faults reach the executors, which the callback never does
+	called := make(chan myint, 3)
+	w := &Walker[myint]{
+		Reverse: true,
+		Executors: []*Executor[myint]{{
+			Name: "default",
+			Callback: func(v myint) error {
+				called <- v
+				return nil
+			},
+		}},
+	}
+	report := f.Walk(w, &g, nil)
+	close(called)
+
+	if !reflect.DeepEqual(report.Ran, []myint{1, 2, 3}) {
+		t.Fatalf("bad ran: %#v", report.Ran)
+	}
+	if !reflect.DeepEqual(report.Failed, []myint{3}) {
+		t.Fatalf("bad failed: %#v", report.Failed)
+	}
+	if report.Err == nil || !strings.Contains(report.Err.Error(), "injected") {
+		t.Fatalf("bad err: %v", report.Err)
+	}
+	var ran []myint
+	for v := range called {
+		ran = append(ran, v)
+	}
+	if len(ran) != 2 {
+		t.Fatalf("bad: %#v", ran)
+	}
+
+	// and the heartbeat callback
+	var hf FaultInjector[myint]
+	hf.Inject(myint(1), Fault{Panic: "boom"})
+	w = &Walker[myint]{
+		Reverse: true,
+		HeartbeatCallback: func(ctx context.Context, v myint, hb *Heartbeat) error {
+			return nil
+		},
+	}
+	report = hf.Walk(w, &g, nil)
+	if !reflect.DeepEqual(report.Failed, []myint{1}) {
+		t.Fatalf("bad failed: %#v", report.Failed)
+	}
+	if !reflect.DeepEqual(report.Skipped, []myint{2}) {
+		t.Fatalf("bad skipped: %#v", report.Skipped)
+	}
+}
@@ -0,0 +1,116 @@
+package dagg
+
+import (
+	"sort"
+)
+
+// SuggestFeedbackEdges returns a set of edges whose removal makes the
+// graph acyclic, sorted by Hashcode, so that a cycle can be reported along
+// with the edges that could be cut to break it. Self-loops are always
+// included. Undirected edges take no part in cycles, so are never
+// returned.
+//
+// Finding the smallest such set is NP-hard, so this uses the greedy
+// heuristic of Eades, Lin and Smyth: vertices are ordered by repeatedly
+// taking sinks from the end, sources from the front, and otherwise the
+// vertex with the most outgoing edges relative to incoming ones, and the
+// edges pointing backwards in that order are returned. Ties are broken by
+// Hashcode, so the result is deterministic.
+//
+// Complexity: O(V^2+E)
+func (g *Graph[T]) SuggestFeedbackEdges() []Edge[T] {
+	idx := g.index()
+	n := len(idx.vertices)
+
+	// candidates are considered in Hashcode order to break ties
+	byHash := make([]int, n)
+	for i := range byHash {
+		byHash[i] = i
+	}
+	sort.Slice(byHash, func(i, j int) bool {
+		return idx.vertices[byHash[i]].Hashcode() < idx.vertices[byHash[j]].Hashcode()
+	})
+
+	// degrees ignore self-loops, which are always feedback edges
+	in := make([]int, n)
+	out := make([]int, n)
+	for u := range idx.vertices {
+		for _, v := range idx.down[u] {
+			if u != v {
+				out[u]++
+				in[v]++
+			}
+		}
+	}
+
+	removed := make([]bool, n)
+	remove := func(u int) {
+		removed[u] = true
+		for _, v := range idx.down[u] {
+			if v != u {
+				in[v]--
+			}
+		}
+		for _, v := range idx.up[u] {
+			if v != u {
+				out[v]--
+			}
+		}
+	}
+
+	var front, back []int
+	for left := n; left > 0; {
+		progress := true
+		for progress {
+			progress = false
+			for _, u := range byHash {
+				switch {
+				case removed[u]:
+				case out[u] == 0:
+					back = append(back, u)
+					remove(u)
+					left--
+					progress = true
+				case in[u] == 0:
+					front = append(front, u)
+					remove(u)
+					left--
+					progress = true
+				}
+			}
+		}
+		if left == 0 {
+			break
+		}
+
+		best := -1
+		for _, u := range byHash {
+			if !removed[u] && (best < 0 || out[u]-in[u] > out[best]-in[best]) {
+				best = u
+			}
+		}
+		front = append(front, best)
+		remove(best)
+		left--
+	}
+
+	// the sinks were collected from the end of the order
+	pos := make([]int, n)
+	for i, u := range front {
+		pos[u] = i
+	}
+	for i, u := range back {
+		pos[u] = n - 1 - i
+	}
+
+	var result []Edge[T]
+	for u := range idx.vertices {
+		for _, v := range idx.down[u] {
+			if pos[u] >= pos[v] {
+				result = append(result, g.edges[directedKey(idx.vertices[u], idx.vertices[v])])
+			}
+		}
+	}
+	sort.Sort(byHashcode[Edge[T]](result))
+	return result
+}
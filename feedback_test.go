@@ -0,0 +1,75 @@
+package dagg
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGraphSuggestFeedbackEdges(t *testing.T) {
+	var g Graph[myint]
+	for i := 1; i <= 6; i++ {
+		g.Add(myint(i))
+	}
+
+	// 1 -> 2 -> 3 -> 1, and 4 <-> 5 with a self-loop on 6
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(1)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+	g.Connect(BasicEdge(myint(4), myint(5)))
+	g.Connect(BasicEdge(myint(5), myint(4)))
+	g.Connect(BasicEdge(myint(6), myint(6)))
+	g.Connect(UndirectedEdge(myint(6), myint(1)))
+
+	edges := g.SuggestFeedbackEdges()
+	if len(edges) != 3 {
+		t.Fatalf("bad: %s", fmt.Sprint(edges))
+	}
+
+	// the result is deterministic
+	for i := 0; i < 10; i++ {
+		again := g.SuggestFeedbackEdges()
+		for j := range edges {
+			if again[j].Hashcode() != edges[j].Hashcode() {
+				t.Fatalf("bad: %v != %v", again, edges)
+			}
+		}
+	}
+
+	for _, e := range edges {
+		g.RemoveEdge(e)
+	}
+	if cycles := (&AcyclicGraph[myint]{g}).Cycles(); len(cycles) != 0 {
+		t.Fatalf("bad: %v", cycles)
+	}
+}
+
+func TestGraphSuggestFeedbackEdges_acyclic(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+
+	if edges := g.SuggestFeedbackEdges(); len(edges) != 0 {
+		t.Fatalf("bad: %v", edges)
+	}
+}
+
+func TestGraphSuggestFeedbackEdges_labeled(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(LabeledEdge(myint(1), myint(2), "a"))
+	g.Connect(LabeledEdge(myint(2), myint(1), "b"))
+
+	edges := g.SuggestFeedbackEdges()
+	if len(edges) != 1 {
+		t.Fatalf("bad: %v", edges)
+	}
+	if _, ok := edges[0].(DataEdge); !ok {
+		t.Fatalf("should return the stored edge, got %#v", edges[0])
+	}
+}
@@ -0,0 +1,140 @@
+package dagg
+
+import (
+	"sort"
+)
+
+// RunStats are the outcomes of a vertex over many walks.
+type RunStats struct {
+	DurationStats
+
+	// Failures is the number of walks the vertex failed in. Flips is the
+	// number of times its outcome changed from the walk before, so a
+	// vertex which alternates between passing and failing has many flips
+	// while a broken one has few.
+	Failures int
+	Flips    int
+
+	lastFailed bool
+}
+
+// FailureRate returns the fraction of runs which failed.
+func (s *RunStats) FailureRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Runs)
+}
+
+// FlipRate returns the fraction of runs whose outcome differed from the run
+// before.
+func (s *RunStats) FlipRate() float64 {
+	if s.Runs < 2 {
+		return 0
+	}
+	return float64(s.Flips) / float64(s.Runs-1)
+}
+
+// RunHistory aggregates the walk logs of many walks, to find the vertices
+// which are flaky. The zero value is ready to use.
+type RunHistory struct {
+	runs runHistory[RunStats, *RunStats]
+}
+
+// Add adds the outcomes of a single walk. Walks should be added in the
+// order they ran, so the flips of each vertex are counted correctly.
+// Skipped vertices didn't run, so they aren't counted.
+func (h *RunHistory) Add(l *WalkLog) {
+	for id, v := range l.Vertices {
+		if v.Skipped || v.Finished.IsZero() {
+			continue
+		}
+
+		s := h.runs.add(id, v.Name, v.Duration())
+		failed := v.Error != ""
+		if s.Runs > 1 && failed != s.lastFailed {
+			s.Flips++
+		}
+		s.lastFailed = failed
+		if failed {
+			s.Failures++
+		}
+	}
+}
+
+// Stats returns the statistics of every vertex, sorted by hashcode.
+func (h *RunHistory) Stats() []*RunStats {
+	return h.runs.sorted()
+}
+
+// FlakyOpts are the thresholds for RunHistory.Flaky. A threshold of zero
+// is not checked.
+type FlakyOpts struct {
+	// MinRuns is the number of runs a vertex needs before it is judged.
+	MinRuns int
+
+	// FailureRate and FlipRate flag vertices which fail, or change between
+	// passing and failing, in at least this fraction of their runs.
+	FailureRate float64
+	FlipRate    float64
+
+	// Variation flags vertices whose durations vary by at least this
+	// fraction of their mean, as returned by RunStats.Variation.
+	Variation float64
+
+	// QuarantineRate suggests quarantining vertices which fail in at least
+	// this fraction of their runs.
+	QuarantineRate float64
+}
+
+// FlakyVertex is a vertex flagged by RunHistory.Flaky.
+type FlakyVertex struct {
+	*RunStats
+
+	// Reasons lists the thresholds the vertex reached: "failures",
+	// "flips" or "variation".
+	Reasons []string
+
+	// Prioritize hints that the vertex should be started as early as it
+	// can, because its duration is unpredictable and it may hold up the
+	// rest of the walk.
+	Prioritize bool
+
+	// Quarantine suggests that the vertex should be taken out of the walk,
+	// or its failures ignored, until it is fixed.
+	Quarantine bool
+}
+
+// Flaky returns the vertices which reached any of the thresholds in opts,
+// sorted from the highest failure rate.
+func (h *RunHistory) Flaky(opts *FlakyOpts) []*FlakyVertex {
+	var result []*FlakyVertex
+	for _, s := range h.Stats() {
+		if s.Runs < opts.MinRuns {
+			continue
+		}
+
+		f := &FlakyVertex{RunStats: s}
+		if opts.FailureRate > 0 && s.Failures > 0 && s.FailureRate() >= opts.FailureRate {
+			f.Reasons = append(f.Reasons, "failures")
+		}
+		if opts.FlipRate > 0 && s.Flips > 0 && s.FlipRate() >= opts.FlipRate {
+			f.Reasons = append(f.Reasons, "flips")
+		}
+		if opts.Variation > 0 && s.Variation() >= opts.Variation {
+			f.Reasons = append(f.Reasons, "variation")
+			f.Prioritize = true
+		}
+		if opts.QuarantineRate > 0 && s.Failures > 0 && s.FailureRate() >= opts.QuarantineRate {
+			f.Quarantine = true
+		}
+
+		if len(f.Reasons) > 0 || f.Quarantine {
+			result = append(result, f)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].FailureRate() > result[j].FailureRate()
+	})
+	return result
+}
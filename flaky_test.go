@@ -0,0 +1,83 @@
+package dagg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testRunLog returns a WalkLog with a vertex for each outcome, each run for
+// the given duration in seconds.
+func testRunLog(outcomes map[string]string, durations map[string]int) *WalkLog {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := &WalkLog{Vertices: make(map[string]*WalkLogVertex)}
+	for id, err := range outcomes {
+		v := &WalkLogVertex{Name: id, Started: start, Error: err}
+		if err == "skipped" {
+			v.Skipped = true
+		} else {
+			v.Finished = start.Add(time.Duration(durations[id]) * time.Second)
+		}
+		l.Vertices[id] = v
+	}
+	return l
+}
+
+func TestRunHistoryFlaky(t *testing.T) {
+	var h RunHistory
+	runs := []map[string]string{
+		{"stable": "", "flaky": "", "broken": "err", "slow": ""},
+		{"stable": "", "flaky": "err", "broken": "err", "slow": ""},
+		{"stable": "", "flaky": "", "broken": "err", "slow": ""},
+		{"stable": "", "flaky": "err", "broken": "skipped", "slow": ""},
+	}
+	for i, outcomes := range runs {
+		durations := map[string]int{"stable": 10, "flaky": 10, "broken": 10, "slow": 10}
+		if i%2 == 1 {
+			durations["slow"] = 30
+		}
+		h.Add(testRunLog(outcomes, durations))
+	}
+
+	stats := h.Stats()
+	if len(stats) != 4 {
+		t.Fatalf("bad: %#v", stats)
+	}
+	broken := stats[0]
+	if broken.Vertex != "broken" || broken.Runs != 3 || broken.Failures != 3 || broken.Flips != 0 {
+		t.Fatalf("bad: %#v", broken)
+	}
+	flaky := stats[1]
+	if flaky.Vertex != "flaky" || flaky.Flips != 3 || flaky.FlipRate() != 1 {
+		t.Fatalf("bad: %#v", flaky)
+	}
+	slow := stats[2]
+	if slow.Mean() != 20*time.Second || slow.StdDev() != 10*time.Second {
+		t.Fatalf("bad: %s %s", slow.Mean(), slow.StdDev())
+	}
+
+	result := h.Flaky(&FlakyOpts{
+		MinRuns:        3,
+		FailureRate:    0.5,
+		FlipRate:       0.5,
+		Variation:      0.25,
+		QuarantineRate: 0.9,
+	})
+	var actual []string
+	for _, f := range result {
+		actual = append(actual, f.Vertex)
+	}
+	if !reflect.DeepEqual(actual, []string{"broken", "flaky", "slow"}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if !result[0].Quarantine || result[0].Prioritize {
+		t.Fatalf("bad: %#v", result[0])
+	}
+	if !reflect.DeepEqual(result[1].Reasons, []string{"failures", "flips"}) || result[1].Quarantine {
+		t.Fatalf("bad: %#v", result[1])
+	}
+	if !reflect.DeepEqual(result[2].Reasons, []string{"variation"}) || !result[2].Prioritize {
+		t.Fatalf("bad: %#v", result[2])
+	}
+}
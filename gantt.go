@@ -0,0 +1,115 @@
+package dagg
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// walkTiming records when a vertex of a walk ran.
+type walkTiming[T Hashable] struct {
+	v          T
+	level      int
+	start, end time.Time
+	err        error
+}
+
+// clock returns the current time, using now if it has been replaced for
+// tests.
+func (w *Walker[T]) clock() time.Time {
+	if w.now != nil {
+		return w.now()
+	}
+	return time.Now()
+}
+
+// startTiming records that v has started, at one level below the deepest of
+// its dependencies.
+func (w *Walker[T]) startTiming(v T, deps []string) {
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+	if w.timings == nil {
+		w.timings = make(map[string]*walkTiming[T])
+	}
+
+	level := 0
+	for _, dep := range deps {
+		if t, ok := w.timings[dep]; ok && t.level+1 > level {
+			level = t.level + 1
+		}
+	}
+	w.timings[v.Hashcode()] = &walkTiming[T]{v: v, level: level, start: w.clock()}
+}
+
+// endTiming records that v has finished.
+func (w *Walker[T]) endTiming(v T, err error) {
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+	if t, ok := w.timings[v.Hashcode()]; ok {
+		t.end = w.clock()
+		t.err = err
+		checkSLO(v, t.end.Sub(t.start))
+	}
+}
+
+// Gantt returns a mermaid gantt chart of when each vertex of the walk ran,
+// with a section for each level of the graph. The level of a vertex is one
+// more than the deepest of its dependencies. Failed vertices are marked as
+// critical, and vertices which are still running are marked as active and
+// drawn up to the current time. Vertices which haven't started aren't
+// drawn.
+func (w *Walker[T]) Gantt() []byte {
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+
+	now := w.clock()
+	levels := make(map[int][]*walkTiming[T])
+	for _, t := range w.timings {
+		levels[t.level] = append(levels[t.level], t)
+	}
+	keys := make([]int, 0, len(levels))
+	for l := range levels {
+		keys = append(keys, l)
+	}
+	sort.Ints(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("gantt\n")
+	buf.WriteString("    dateFormat x\n")
+	buf.WriteString("    axisFormat %H:%M:%S\n")
+	for _, l := range keys {
+		ts := levels[l]
+		sort.Slice(ts, func(i, j int) bool {
+			if !ts[i].start.Equal(ts[j].start) {
+				return ts[i].start.Before(ts[j].start)
+			}
+			return VertexName(ts[i].v) < VertexName(ts[j].v)
+		})
+
+		buf.WriteString(fmt.Sprintf("    section Level %d\n", l))
+		for _, t := range ts {
+			var tags []string
+			end := t.end
+			if end.IsZero() {
+				tags = append(tags, "active")
+				end = now
+			} else if t.err != nil {
+				tags = append(tags, "crit")
+			}
+			tags = append(tags, fmt.Sprint(t.start.UnixMilli()), fmt.Sprint(end.UnixMilli()))
+
+			buf.WriteString(fmt.Sprintf("    %s :%s\n", ganttLabel(VertexName(t.v)), strings.Join(tags, ", ")))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// ganttLabel escapes the characters of a name which have a meaning in a
+// mermaid gantt task.
+func ganttLabel(name string) string {
+	name = strings.ReplaceAll(name, "#", "#35;")
+	return strings.ReplaceAll(name, ":", "#58;")
+}
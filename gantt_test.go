@@ -0,0 +1,72 @@
+package dagg
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWalkerGantt(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(BasicEdge(myint(3), myint(1)))
+
+	// every call to the clock advances it by a second
+	var lock sync.Mutex
+	now := time.UnixMilli(0)
+	w := &Walker[myint]{
+		Reverse: true,
+		Callback: func(v myint) error {
+			if v == 3 {
+				return fmt.Errorf("error")
+			}
+			return nil
+		},
+		now: func() time.Time {
+			lock.Lock()
+			defer lock.Unlock()
+			now = now.Add(time.Second)
+			return now
+		},
+	}
+
+	// run 1 on its own, then 2 and 3 one at a time, so the clock is
+	// deterministic
+	w.Update(&AcyclicGraph[myint]{*g.Filter(func(v myint) bool { return v == 1 })})
+	w.Wait()
+	g2 := g.Filter(func(v myint) bool { return v != 3 })
+	w.Update(&AcyclicGraph[myint]{*g2})
+	w.Wait()
+	w.Update(&g)
+	if err := w.Wait(); err == nil {
+		t.Fatal("expect error")
+	}
+
+	actual := strings.TrimSpace(string(w.Gantt()))
+	expected := strings.TrimSpace(testWalkerGanttStr)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+}
+
+func TestGanttLabel(t *testing.T) {
+	if l := ganttLabel("a:b#c"); l != "a#58;b#35;c" {
+		t.Fatalf("bad: %s", l)
+	}
+}
+
+const testWalkerGanttStr = `
+gantt
+    dateFormat x
+    axisFormat %H:%M:%S
+    section Level 0
+    1 :1000, 2000
+    section Level 1
+    2 :3000, 4000
+    3 :crit, 5000, 6000
+`
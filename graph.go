@@ -8,10 +8,46 @@ import (
 
 // Graph is used to represent a dependency graph.
 type Graph[T Hashable] struct {
-	vertices  Set[T]
-	edges     Set[Edge[T]]
-	downEdges map[string]Set[T]
-	upEdges   map[string]Set[T]
+	vertices Set[T]
+	edges    edgeSet[T]
+
+	// ids interns the hashcode of every vertex with edges, including the
+	// ends of dangling edges, to a small integer which keys the adjacency
+	// below, so following an edge doesn't hash a string. An ID is put on
+	// free for reuse once its vertex has no edges left.
+	ids  map[string]int
+	free []int
+
+	downEdges map[int]idSet[T]
+	upEdges   map[int]idSet[T]
+
+	// neighbors records the vertices joined by undirected edges, in both
+	// directions. Undirected edges are not part of downEdges and upEdges, so
+	// they take no part in dependency ordering or cycle detection.
+	neighbors map[int]idSet[T]
+
+	// data holds the annotations of each vertex, by hashcode.
+	data map[string]map[string]interface{}
+
+	// expiries holds the expiry time of each ExpiringEdge, soonest first.
+	expiries edgeExpiries
+
+	// shared is set when the storage above is shared with a snapshot, and
+	// must be copied before it is modified.
+	shared bool
+
+	// policy is how Connect treats vertices which aren't in the graph.
+	policy VertexPolicy
+
+	// version counts the mutations of the graph, as returned by Version.
+	version uint64
+
+	// less orders vertex names, as set by WithVertexLess.
+	less func(a, b string) bool
+
+	// hooks are called with every mutation of the graph. They belong to
+	// this graph alone, and aren't given to copies or snapshots.
+	hooks []*mutationHook[T]
 }
 
 // Subgrapher allows a Vertex to be a Graph itself, by returning a Grapher.
@@ -59,12 +95,35 @@ func (g *Graph[T]) Edges() []Edge[T] {
 	return result
 }
 
+// SortedVertices returns the list of all the vertices in the graph, sorted
+// by Hashcode.
+func (g *Graph[T]) SortedVertices() []T {
+	result := g.Vertices()
+	sort.Sort(byHashcode[T](result))
+	return result
+}
+
+// SortedEdges returns the list of all the edges in the graph, sorted by
+// Hashcode.
+func (g *Graph[T]) SortedEdges() []Edge[T] {
+	result := g.Edges()
+	sort.Sort(byHashcode[Edge[T]](result))
+	return result
+}
+
 // EdgesFrom returns the list of edges from the given source.
+//
+// Complexity: O(deg(v))
 func (g *Graph[T]) EdgesFrom(v T) []Edge[T] {
 	var result []Edge[T]
 	from := v.Hashcode()
-	for _, e := range g.Edges() {
-		if e.Source().Hashcode() == from {
+	for _, target := range g.downEdgesNoCopy(v) {
+		if e, ok := g.edgeBetween(BasicEdge(v, target), from, target.Hashcode()); ok {
+			result = append(result, e)
+		}
+	}
+	for _, n := range g.adjacent(g.neighbors, v) {
+		if e, ok := g.edgeBetween(UndirectedEdge(v, n), from, n.Hashcode()); ok {
 			result = append(result, e)
 		}
 	}
@@ -73,15 +132,78 @@ func (g *Graph[T]) EdgesFrom(v T) []Edge[T] {
 }
 
 // EdgesTo returns the list of edges to the given target.
+//
+// Complexity: O(deg(v))
 func (g *Graph[T]) EdgesTo(v T) []Edge[T] {
 	var result []Edge[T]
-	search := v.Hashcode()
-	for _, e := range g.Edges() {
-		if e.Target().Hashcode() == search {
+	to := v.Hashcode()
+	for _, source := range g.upEdgesNoCopy(v) {
+		if e, ok := g.edgeBetween(BasicEdge(source, v), source.Hashcode(), to); ok {
+			result = append(result, e)
+		}
+	}
+	for _, n := range g.adjacent(g.neighbors, v) {
+		if e, ok := g.edgeBetween(UndirectedEdge(n, v), n.Hashcode(), to); ok {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+// edgeBetween returns the stored edge with the same key as key, if it
+// runs from source to target. An undirected edge is only stored one way
+// round, so it's only returned for the source and target it was connected
+// with.
+func (g *Graph[T]) edgeBetween(key Edge[T], source, target string) (Edge[T], bool) {
+	e, ok := g.edges[keyOf(key)]
+	if !ok || e.Source().Hashcode() != source || e.Target().Hashcode() != target {
+		return nil, false
+	}
+	return e, true
+}
+
+// EdgeData returns the data of the edge from source to target, if that edge
+// implements DataEdge.
+func (g *Graph[T]) EdgeData(source, target T) (interface{}, bool) {
+	e, ok := g.edges[directedKey(source, target)]
+	if !ok {
+		return nil, false
+	}
+
+	var raw interface{}
+	raw = e
+	de, ok := raw.(DataEdge)
+	if !ok {
+		return nil, false
+	}
+	return de.EdgeData(), true
+}
+
+// DanglingEdges returns the edges whose source or target is not a vertex of
+// the graph, sorted by Hashcode. These edges can only be connected with the
+// AllowDanglingEdges VertexPolicy, and can exist in an otherwise valid
+// graph.
+func (g *Graph[T]) DanglingEdges() []Edge[T] {
+	var result []Edge[T]
+	for _, e := range g.edges {
+		if !g.HasVertex(e.Source()) || !g.HasVertex(e.Target()) {
 			result = append(result, e)
 		}
 	}
 
+	sort.Sort(byHashcode[Edge[T]](result))
+	return result
+}
+
+// SelfLoops returns the edges from a vertex to itself, sorted by hashcode.
+func (g *Graph[T]) SelfLoops() []Edge[T] {
+	var result []Edge[T]
+	for _, e := range g.SortedEdges() {
+		if e.Source().Hashcode() == e.Target().Hashcode() {
+			result = append(result, e)
+		}
+	}
 	return result
 }
 
@@ -98,16 +220,21 @@ func (g *Graph[T]) HasEdge(e Edge[T]) bool {
 // Add adds a vertex to the graph. This is safe to call multiple time with
 // the same Vertex.
 func (g *Graph[T]) Add(v T) T {
-	g.init()
+	g.unshare()
 	g.vertices.Add(v)
+	g.notify(Mutation[T]{Op: MutationAdd, Vertex: v})
 	return v
 }
 
 // Remove removes a vertex from the graph. This will also remove any
 // edges with this vertex as a source or target.
 func (g *Graph[T]) Remove(v T) T {
+	g.unshare()
+
 	// Delete the vertex itself
+	existed := g.vertices.Include(v)
 	g.vertices.Delete(v)
+	delete(g.data, v.Hashcode())
 
 	// Delete the edges to non-existent things
 	for _, target := range g.downEdgesNoCopy(v) {
@@ -116,6 +243,13 @@ func (g *Graph[T]) Remove(v T) T {
 	for _, source := range g.upEdgesNoCopy(v) {
 		g.RemoveEdge(BasicEdge(source, v))
 	}
+	for _, n := range g.adjacent(g.neighbors, v) {
+		g.RemoveEdge(UndirectedEdge(v, n))
+	}
+
+	if existed {
+		g.notify(Mutation[T]{Op: MutationRemove, Vertex: v})
+	}
 	var new T
 	return new
 
@@ -124,6 +258,10 @@ func (g *Graph[T]) Remove(v T) T {
 // Replace replaces the original Vertex with replacement. If the original
 // does not exist within the graph, then false is returned. Otherwise, true
 // is returned.
+//
+// The edges of the original are moved to replacement whatever the graph's
+// VertexPolicy, as they are already in the graph: a dangling edge stays
+// dangling, even with StrictVertices.
 func (g *Graph[T]) Replace(original, replacement T) bool {
 	// If we don't have the original, we can't do anything
 	if !g.vertices.Include(original) {
@@ -135,13 +273,19 @@ func (g *Graph[T]) Replace(original, replacement T) bool {
 		return true
 	}
 
-	// Add our new vertex, then copy all the edges
+	// Add our new vertex, then copy all the edges and annotations
 	g.Add(replacement)
+	if data, ok := g.data[original.Hashcode()]; ok {
+		g.data[replacement.Hashcode()] = data
+	}
 	for _, target := range g.downEdgesNoCopy(original) {
-		g.Connect(BasicEdge(replacement, target))
+		g.connect(BasicEdge(replacement, target))
 	}
 	for _, source := range g.upEdgesNoCopy(original) {
-		g.Connect(BasicEdge(source, replacement))
+		g.connect(BasicEdge(source, replacement))
+	}
+	for _, n := range g.adjacent(g.neighbors, original) {
+		g.connect(UndirectedEdge(replacement, n))
 	}
 
 	// Remove our old vertex, which will also remove all the edges
@@ -150,84 +294,251 @@ func (g *Graph[T]) Replace(original, replacement T) bool {
 	return true
 }
 
+// Filter returns a new graph containing only the vertices for which cb
+// returns true, and the edges between those vertices.
+func (g *Graph[T]) Filter(cb func(T) bool) *Graph[T] {
+	result := &Graph[T]{}
+	for _, v := range g.vertices {
+		if cb(v) {
+			result.Add(v)
+		}
+	}
+
+	for _, e := range g.edges {
+		if result.HasVertex(e.Source()) && result.HasVertex(e.Target()) {
+			result.Connect(e)
+		}
+	}
+
+	return result
+}
+
 // RemoveEdge removes an edge from the graph.
 func (g *Graph[T]) RemoveEdge(edge Edge[T]) {
-	g.init()
+	g.removeEdge(edge, MutationRemoveEdge)
+}
+
+// removeEdge removes an edge, notifying the hooks with the given operation.
+func (g *Graph[T]) removeEdge(edge Edge[T], op string) {
+	g.unshare()
 
-	// Delete the edge from the set
+	// Delete the edge from the set, noting the stored edge for the
+	// mutation hooks.
+	stored, existed := g.edges[keyOf(edge)]
 	g.edges.Delete(edge)
+	if existed {
+		var raw interface{}
+		raw = stored
+		if _, ok := raw.(ExpiringEdge); ok {
+			g.forgetExpiry(keyOf(stored))
+		}
+		defer g.notify(Mutation[T]{Op: op, Edge: stored})
+	}
 
-	// Delete the up/down edges
-	if s, ok := g.downEdges[edge.Source().Hashcode()]; ok {
-		s.Delete(edge.Target())
+	source, ok := g.ids[edge.Source().Hashcode()]
+	if !ok {
+		return
 	}
-	if s, ok := g.upEdges[edge.Target().Hashcode()]; ok {
-		s.Delete(edge.Source())
+	target, ok := g.ids[edge.Target().Hashcode()]
+	if !ok {
+		return
 	}
+
+	if !IsDirected(edge) {
+		removeAdjacent(g.neighbors, source, target)
+		removeAdjacent(g.neighbors, target, source)
+	} else {
+		// Delete the up/down edges
+		removeAdjacent(g.downEdges, source, target)
+		removeAdjacent(g.upEdges, target, source)
+	}
+	g.forget(edge.Source())
+	g.forget(edge.Target())
 }
 
 // UpEdges returns the vertices connected to the outward edges from the source
 // Vertex v.
 func (g *Graph[T]) UpEdges(v T) Set[T] {
-	return g.upEdgesNoCopy(v).Copy()
+	return g.upEdgesNoCopy(v).Set()
 }
 
 // DownEdges returns the vertices connected from the inward edges to Vertex v.
 func (g *Graph[T]) DownEdges(v T) Set[T] {
-	return g.downEdgesNoCopy(v).Copy()
+	return g.downEdgesNoCopy(v).Set()
 }
 
-// downEdgesNoCopy returns the outward edges from the source Vertex v as a Set.
-// This Set is the same as used internally bu the Graph to prevent a copy, and
-// must not be modified by the caller.
-func (g *Graph[T]) downEdgesNoCopy(v T) Set[T] {
+// InDegree returns the number of directed edges to v.
+func (g *Graph[T]) InDegree(v T) int {
+	return g.upEdgesNoCopy(v).Len()
+}
+
+// OutDegree returns the number of directed edges from v.
+func (g *Graph[T]) OutDegree(v T) int {
+	return g.downEdgesNoCopy(v).Len()
+}
+
+// Roots returns every vertex with no directed edges to it.
+//
+// Complexity: O(V)
+func (g *Graph[T]) Roots() []T {
+	var result []T
+	for _, v := range g.vertices {
+		if g.InDegree(v) == 0 {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Leaves returns every vertex with no directed edges from it.
+//
+// Complexity: O(V)
+func (g *Graph[T]) Leaves() []T {
+	var result []T
+	for _, v := range g.vertices {
+		if g.OutDegree(v) == 0 {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Neighbors returns every vertex joined to v by an edge, ignoring the
+// direction of the edge. This includes both directed and undirected edges.
+func (g *Graph[T]) Neighbors(v T) Set[T] {
 	g.init()
-	return g.downEdges[v.Hashcode()]
+
+	result := make(Set[T])
+	for _, adj := range []map[int]idSet[T]{g.downEdges, g.upEdges, g.neighbors} {
+		for _, n := range g.adjacent(adj, v) {
+			result.Add(n)
+		}
+	}
+	return result
 }
 
-// upEdgesNoCopy returns the inward edges to the destination Vertex v as a Set.
-// This Set is the same as used internally bu the Graph to prevent a copy, and
+// Components returns the weakly connected components of the graph: groups
+// of vertices that are joined by edges when the direction of the edges is
+// ignored.
+//
+// Complexity: O(V+E)
+func (g *Graph[T]) Components() [][]T {
+	seen := make(map[string]struct{})
+
+	var components [][]T
+	for _, v := range g.Vertices() {
+		if _, ok := seen[v.Hashcode()]; ok {
+			continue
+		}
+		seen[v.Hashcode()] = struct{}{}
+
+		var component []T
+		frontier := []T{v}
+		for len(frontier) > 0 {
+			n := len(frontier)
+			current := frontier[n-1]
+			frontier = frontier[:n-1]
+			component = append(component, current)
+
+			for k, next := range g.Neighbors(current) {
+				if _, ok := seen[k]; ok {
+					continue
+				}
+				seen[k] = struct{}{}
+				frontier = append(frontier, next)
+			}
+		}
+		components = append(components, component)
+	}
+
+	return components
+}
+
+// downEdgesNoCopy returns the outward edges from the source Vertex v as a set.
+// This set is the same as used internally bu the Graph to prevent a copy, and
 // must not be modified by the caller.
-func (g *Graph[T]) upEdgesNoCopy(v T) Set[T] {
-	g.init()
-	return g.upEdges[v.Hashcode()]
+func (g *Graph[T]) downEdgesNoCopy(v T) idSet[T] {
+	return g.adjacent(g.downEdges, v)
+}
+
+// upEdgesNoCopy returns the inward edges to the destination Vertex v as a set.
+// This set is the same as used internally bu the Graph to prevent a copy, and
+// must not be modified by the caller.
+func (g *Graph[T]) upEdgesNoCopy(v T) idSet[T] {
+	return g.adjacent(g.upEdges, v)
 }
 
 // Connect adds an edge with the given source and target. This is safe to
 // call multiple times with the same value. Note that the same value is
 // verified through pointer equality of the vertices, not through the
 // value of the edge itself.
-func (g *Graph[T]) Connect(edge Edge[T]) {
-	g.init()
+//
+// Vertices of the edge which aren't in the graph are handled according to
+// the graph's VertexPolicy: by default they are added, but with
+// StrictVertices an error is returned and the edge isn't added.
+func (g *Graph[T]) Connect(edge Edge[T]) error {
+	if err := g.checkVertices(edge); err != nil {
+		return err
+	}
+	g.connect(edge)
+	return nil
+}
+
+// connect adds an edge, regardless of the VertexPolicy.
+func (g *Graph[T]) connect(edge Edge[T]) {
+	g.unshare()
 
 	source := edge.Source()
 	target := edge.Target()
-	sourceCode := source.Hashcode()
-	targetCode := target.Hashcode()
 
-	// Do we have this already? If so, don't add it again.
-	if s, ok := g.downEdges[sourceCode]; ok && s.Include(target) {
+	if !IsDirected(edge) {
+		g.connectUndirected(edge)
+		return
+	}
+
+	// Do we have this already? If so, don't add it again, but refresh the
+	// expiry of a temporary edge.
+	sourceID := g.intern(source)
+	targetID := g.intern(target)
+	if _, ok := g.downEdges[sourceID][targetID]; ok {
+		if g.refreshExpiry(edge) {
+			g.notify(Mutation[T]{Op: MutationConnect, Edge: edge})
+		}
 		return
 	}
 
 	// Add the edge to the set
 	g.edges.Add(edge)
+	g.trackExpiry(edge)
 
-	// Add the down edge
-	s, ok := g.downEdges[sourceCode]
-	if !ok {
-		s = make(Set[T])
-		g.downEdges[sourceCode] = s
-	}
-	s.Add(target)
+	// Add the up and down edges
+	addAdjacent(g.downEdges, sourceID, targetID, target)
+	addAdjacent(g.upEdges, targetID, sourceID, source)
 
-	// Add the up edge
-	s, ok = g.upEdges[targetCode]
-	if !ok {
-		s = make(Set[T])
-		g.upEdges[targetCode] = s
+	g.notify(Mutation[T]{Op: MutationConnect, Edge: edge})
+}
+
+// connectUndirected adds an undirected edge, which is recorded as a neighbor
+// of both of its vertices.
+func (g *Graph[T]) connectUndirected(edge Edge[T]) {
+	source := edge.Source()
+	target := edge.Target()
+
+	sourceID := g.intern(source)
+	targetID := g.intern(target)
+	if _, ok := g.neighbors[sourceID][targetID]; ok {
+		if g.refreshExpiry(edge) {
+			g.notify(Mutation[T]{Op: MutationConnect, Edge: edge})
+		}
+		return
 	}
-	s.Add(source)
+
+	g.edges.Add(edge)
+	g.trackExpiry(edge)
+	addAdjacent(g.neighbors, sourceID, targetID, target)
+	addAdjacent(g.neighbors, targetID, sourceID, source)
+
+	g.notify(Mutation[T]{Op: MutationConnect, Edge: edge})
 }
 
 // String outputs some human-friendly output for the graph structure.
@@ -244,12 +555,12 @@ func (g *Graph[T]) StringWithNodeTypes() string {
 		names = append(names, name)
 		mapping[name] = v
 	}
-	sort.Strings(names)
+	sort.Slice(names, func(i, j int) bool { return g.nameLess(names[i], names[j]) })
 
 	// Write each node in order...
 	for _, name := range names {
 		v := mapping[name]
-		targets := g.downEdges[v.Hashcode()]
+		targets := g.downEdgesNoCopy(v)
 
 		buf.WriteString(fmt.Sprintf("%s - %T\n", name, v))
 
@@ -261,7 +572,7 @@ func (g *Graph[T]) StringWithNodeTypes() string {
 			deps = append(deps, dep)
 			targetNodes[dep] = target
 		}
-		sort.Strings(deps)
+		sort.Slice(deps, func(i, j int) bool { return g.nameLess(deps[i], deps[j]) })
 
 		// Write dependencies
 		for _, d := range deps {
@@ -275,39 +586,7 @@ func (g *Graph[T]) StringWithNodeTypes() string {
 // String outputs some human-friendly output for the graph structure.
 func (g *Graph[T]) String() string {
 	var buf bytes.Buffer
-
-	// Build the list of node names and a mapping so that we can more
-	// easily alphabetize the output to remain deterministic.
-	vertices := g.Vertices()
-	names := make([]string, 0, len(vertices))
-	mapping := make(map[string]T, len(vertices))
-	for _, v := range vertices {
-		name := VertexName(v)
-		names = append(names, name)
-		mapping[name] = v
-	}
-	sort.Strings(names)
-
-	// Write each node in order...
-	for _, name := range names {
-		v := mapping[name]
-		targets := g.downEdges[v.Hashcode()]
-
-		buf.WriteString(fmt.Sprintf("%s\n", name))
-
-		// Alphabetize dependencies
-		deps := make([]string, 0, targets.Len())
-		for _, target := range targets {
-			deps = append(deps, VertexName(target))
-		}
-		sort.Strings(deps)
-
-		// Write dependencies
-		for _, d := range deps {
-			buf.WriteString(fmt.Sprintf("  %s\n", d))
-		}
-	}
-
+	g.WriteTo(&buf)
 	return buf.String()
 }
 
@@ -316,13 +595,104 @@ func (g *Graph[T]) init() {
 		g.vertices = make(Set[T])
 	}
 	if g.edges == nil {
-		g.edges = make(Set[Edge[T]])
+		g.edges = make(edgeSet[T])
+	}
+	if g.ids == nil {
+		g.ids = make(map[string]int)
 	}
 	if g.downEdges == nil {
-		g.downEdges = make(map[string]Set[T])
+		g.downEdges = make(map[int]idSet[T])
 	}
 	if g.upEdges == nil {
-		g.upEdges = make(map[string]Set[T])
+		g.upEdges = make(map[int]idSet[T])
+	}
+	if g.neighbors == nil {
+		g.neighbors = make(map[int]idSet[T])
+	}
+}
+
+// unshare makes sure the graph is the only owner of its storage, copying it
+// if it is still shared with a snapshot.
+func (g *Graph[T]) unshare() {
+	g.init()
+	if !g.shared {
+		return
+	}
+
+	g.vertices = g.vertices.Copy()
+	g.edges = g.edges.Copy()
+	g.ids = copyIDs(g.ids)
+	g.free = append([]int(nil), g.free...)
+	g.downEdges = copyAdjacency(g.downEdges)
+	g.upEdges = copyAdjacency(g.upEdges)
+	g.neighbors = copyAdjacency(g.neighbors)
+	g.data = copyVertexData(g.data)
+	g.expiries = g.expiries.copy()
+	g.shared = false
+}
+
+func copyAdjacency[T Hashable](m map[int]idSet[T]) map[int]idSet[T] {
+	c := make(map[int]idSet[T], len(m))
+	for k, s := range m {
+		c[k] = s.Copy()
+	}
+	return c
+}
+
+func copyIDs(m map[string]int) map[string]int {
+	c := make(map[string]int, len(m))
+	for k, id := range m {
+		c[k] = id
+	}
+	return c
+}
+
+// Copy returns a copy of the graph. The vertices and edges themselves are
+// not copied, but the graph structure is, so either graph can be modified
+// without affecting the other.
+func (g *Graph[T]) Copy() *Graph[T] {
+	g.init()
+	return &Graph[T]{
+		vertices:  g.vertices.Copy(),
+		edges:     g.edges.Copy(),
+		ids:       copyIDs(g.ids),
+		free:      append([]int(nil), g.free...),
+		downEdges: copyAdjacency(g.downEdges),
+		upEdges:   copyAdjacency(g.upEdges),
+		neighbors: copyAdjacency(g.neighbors),
+		data:      copyVertexData(g.data),
+		expiries:  g.expiries.copy(),
+		version:   g.version,
+		policy:    g.policy,
+		less:      g.less,
+	}
+}
+
+// ReadSnapshot returns a read-only snapshot of the graph. The snapshot
+// shares storage with g until g is next modified, at which point g copies
+// its storage. This makes taking a snapshot O(1), while the first
+// modification after a snapshot is O(V+E).
+//
+// The snapshot may be read concurrently with modifications to g, but
+// ReadSnapshot itself must not be called concurrently with them.
+func (g *Graph[T]) ReadSnapshot() *Graph[T] {
+	g.init()
+	g.shared = true
+
+	return &Graph[T]{
+		vertices:  g.vertices,
+		edges:     g.edges,
+		ids:       g.ids,
+		free:      g.free,
+		downEdges: g.downEdges,
+		upEdges:   g.upEdges,
+		neighbors: g.neighbors,
+		data:      g.data,
+		expiries:  g.expiries,
+		version:   g.version,
+		policy:    g.policy,
+		less:      g.less,
+		shared:    true,
 	}
 }
 
@@ -343,3 +713,13 @@ func VertexName[T Hashable](raw T) string {
 
 	return raw.Hashcode()
 }
+
+// byHashcode implements sort.Interface so a list of Hashable values can be
+// sorted consistently by their Hashcode.
+type byHashcode[T Hashable] []T
+
+func (b byHashcode[T]) Len() int      { return len(b) }
+func (b byHashcode[T]) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byHashcode[T]) Less(i, j int) bool {
+	return b[i].Hashcode() < b[j].Hashcode()
+}
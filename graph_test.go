@@ -2,6 +2,8 @@ package dagg
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -71,6 +73,27 @@ func TestGraph_replace(t *testing.T) {
 	}
 }
 
+func TestGraph_replaceStrictVertices(t *testing.T) {
+	var g Graph[myint]
+	g.SetVertexPolicy(AllowDanglingEdges)
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.SetVertexPolicy(StrictVertices)
+
+	// the edges are already in the graph, so they move with the vertex
+	if !g.Replace(myint(2), myint(42)) {
+		t.Fatal("should replace")
+	}
+	if !g.HasEdge(BasicEdge(myint(1), myint(42))) || !g.HasEdge(BasicEdge(myint(42), myint(3))) {
+		t.Fatalf("bad: %#v", g.Edges())
+	}
+	if len(g.Edges()) != 2 {
+		t.Fatalf("bad: %#v", g.Edges())
+	}
+}
+
 func TestGraph_replaceSelf(t *testing.T) {
 	var g Graph[myint]
 	g.Add(myint(1))
@@ -177,6 +200,38 @@ func TestGraphEdgesTo(t *testing.T) {
 	}
 }
 
+func TestGraphEdgesFromTo_undirected(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(LabeledEdge(myint(1), myint(2), "data"))
+	g.Connect(UndirectedEdge(myint(1), myint(3)))
+
+	from := g.EdgesFrom(myint(1))
+	if len(from) != 2 {
+		t.Fatalf("bad: %#v", from)
+	}
+	for _, e := range from {
+		if IsDirected(e) {
+			if _, ok := e.(DataEdge); !ok {
+				t.Fatalf("should return the stored edge, got %#v", e)
+			}
+		}
+	}
+
+	// the undirected edge is only returned as it was connected
+	if to := g.EdgesTo(myint(3)); len(to) != 1 {
+		t.Fatalf("bad: %#v", to)
+	}
+	if from := g.EdgesFrom(myint(3)); len(from) != 0 {
+		t.Fatalf("bad: %#v", from)
+	}
+	if to := g.EdgesTo(myint(1)); len(to) != 0 {
+		t.Fatalf("bad: %#v", to)
+	}
+}
+
 func TestGraphUpdownEdges(t *testing.T) {
 	// Verify that we can't inadvertently modify the internal graph sets
 	var g Graph[myint]
@@ -213,6 +268,242 @@ func TestGraphUpdownEdges(t *testing.T) {
 	}
 }
 
+func TestGraphReadSnapshot(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+
+	snap := g.ReadSnapshot()
+
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Remove(myint(1))
+
+	actual := strings.TrimSpace(snap.String())
+	expected := strings.TrimSpace(testGraphReadSnapshotStr)
+	if actual != expected {
+		t.Fatalf("bad snapshot: %s", actual)
+	}
+
+	actual = strings.TrimSpace(g.String())
+	expected = strings.TrimSpace(testGraphReadSnapshotLiveStr)
+	if actual != expected {
+		t.Fatalf("bad graph: %s", actual)
+	}
+
+	// modifying the snapshot must not change the original either
+	snap.Add(myint(4))
+	if g.HasVertex(myint(4)) {
+		t.Fatal("graph should not have 4")
+	}
+}
+
+func TestGraphFilter(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+
+	filtered := g.Filter(func(v myint) bool {
+		return v != myint(2)
+	})
+
+	actual := strings.TrimSpace(filtered.String())
+	expected := strings.TrimSpace(testGraphFilterStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// the original graph must be left intact
+	if !g.HasVertex(myint(2)) || !g.HasEdge(BasicEdge(myint(1), myint(2))) {
+		t.Fatalf("original graph modified: %s", g.String())
+	}
+}
+
+func TestGraphUndirectedEdges(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(UndirectedEdge(myint(2), myint(3)))
+	g.Connect(UndirectedEdge(myint(3), myint(2)))
+
+	if len(g.Edges()) != 2 {
+		t.Fatalf("bad edges: %#v", g.Edges())
+	}
+
+	// undirected edges are not dependencies, so they can't form cycles
+	if err := g.Validate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if g.DownEdges(myint(2)).Len() != 0 {
+		t.Fatalf("bad down edges: %#v", g.DownEdges(myint(2)))
+	}
+
+	n := g.Neighbors(myint(2))
+	if n.Len() != 2 || !n.Include(myint(1)) || !n.Include(myint(3)) {
+		t.Fatalf("bad neighbors: %#v", n)
+	}
+
+	actual := testSCCStr(g.Components())
+	expected := "1,2,3\n4"
+	if actual != expected {
+		t.Fatalf("bad components: %s", actual)
+	}
+
+	g.RemoveEdge(UndirectedEdge(myint(3), myint(2)))
+	if g.Neighbors(myint(3)).Len() != 0 || len(g.Edges()) != 1 {
+		t.Fatalf("undirected edge not removed")
+	}
+
+	g.Connect(UndirectedEdge(myint(2), myint(3)))
+	g.Remove(myint(3))
+	if g.Neighbors(myint(2)).Len() != 1 || len(g.Edges()) != 1 {
+		t.Fatalf("undirected edge not removed with vertex")
+	}
+}
+
+func TestGraphSortedVertices(t *testing.T) {
+	var g Graph[mystr]
+	g.Add(mystr("c"))
+	g.Add(mystr("a"))
+	g.Add(mystr("b"))
+	g.Connect(BasicEdge(mystr("c"), mystr("a")))
+	g.Connect(BasicEdge(mystr("a"), mystr("b")))
+	g.Connect(BasicEdge(mystr("b"), mystr("c")))
+
+	expected := []mystr{"a", "b", "c"}
+	if actual := g.SortedVertices(); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	var actual []string
+	for _, e := range g.SortedEdges() {
+		actual = append(actual, e.Hashcode())
+	}
+	expectedEdges := []string{"a-b", "b-c", "c-a"}
+	if !reflect.DeepEqual(actual, expectedEdges) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestGraphEdgeData(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(LabeledEdge(myint(1), myint(2), "requires"))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+
+	data, ok := g.EdgeData(myint(1), myint(2))
+	if !ok || data != "requires" {
+		t.Fatalf("bad: %#v", data)
+	}
+	if _, ok := g.EdgeData(myint(1), myint(3)); ok {
+		t.Fatal("basic edge should have no data")
+	}
+	if _, ok := g.EdgeData(myint(2), myint(3)); ok {
+		t.Fatal("missing edge should have no data")
+	}
+
+	// a labeled edge is the same edge as a basic edge
+	if !g.HasEdge(BasicEdge(myint(1), myint(2))) {
+		t.Fatal("should have 1,2")
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testGraphEdgeDataStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestGraphDegree(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+
+	if d := g.InDegree(myint(3)); d != 2 {
+		t.Fatalf("bad in degree: %d", d)
+	}
+	if d := g.OutDegree(myint(1)); d != 2 {
+		t.Fatalf("bad out degree: %d", d)
+	}
+	if d := g.OutDegree(myint(4)); d != 0 {
+		t.Fatalf("bad out degree: %d", d)
+	}
+
+	roots := g.Roots()
+	sort.Sort(byVertexName[myint](roots))
+	if !reflect.DeepEqual(roots, []myint{1, 4}) {
+		t.Fatalf("bad roots: %#v", roots)
+	}
+
+	leaves := g.Leaves()
+	sort.Sort(byVertexName[myint](leaves))
+	if !reflect.DeepEqual(leaves, []myint{3, 4}) {
+		t.Fatalf("bad leaves: %#v", leaves)
+	}
+}
+
+func TestGraphSelfLoops(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(2)))
+
+	loops := g.SelfLoops()
+	if len(loops) != 1 || !reflect.DeepEqual(loops[0], BasicEdge(myint(2), myint(2))) {
+		t.Fatalf("bad: %#v", loops)
+	}
+}
+
+func TestGraphInternedIDs(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Connect(UndirectedEdge(myint(1), myint(2)))
+
+	// churning edges reuses the freed IDs rather than growing
+	for i := 3; i < 100; i++ {
+		g.Connect(BasicEdge(myint(1), myint(i)))
+		g.Remove(myint(i))
+		if len(g.ids)+len(g.free) > 3 {
+			t.Fatalf("bad: %d IDs after %d", len(g.ids)+len(g.free), i)
+		}
+	}
+	if _, ok := g.ids[myint(3).Hashcode()]; ok {
+		t.Fatal("3 should have no ID")
+	}
+
+	g.Connect(BasicEdge(myint(3), myint(1)))
+	if up := g.UpEdges(myint(1)); up.Len() != 1 || !up.Include(myint(3)) {
+		t.Fatalf("bad: %#v", up)
+	}
+	if n := g.Neighbors(myint(1)); n.Len() != 2 || !n.Include(myint(2)) {
+		t.Fatalf("bad: %#v", n)
+	}
+
+	g.RemoveEdge(UndirectedEdge(myint(2), myint(1)))
+	g.Remove(myint(3))
+	if len(g.ids) != 0 {
+		t.Fatalf("bad: %#v", g.ids)
+	}
+}
+
 type hashVertex struct {
 	code interface{}
 }
@@ -258,3 +549,31 @@ const testGraphReplaceSelfStr = `
   3
 3
 `
+
+const testGraphReadSnapshotStr = `
+1
+  2
+2
+`
+
+const testGraphReadSnapshotLiveStr = `
+2
+  3
+3
+`
+
+const testGraphFilterStr = `
+1
+  3
+3
+  4
+4
+`
+
+const testGraphEdgeDataStr = `
+1
+  2 (requires)
+  3
+2
+3
+`
@@ -0,0 +1,123 @@
+package dagg
+
+import (
+	"sort"
+	"sync"
+)
+
+// GroupedVertex is a vertex that belongs to named concurrency groups. The
+// Walker won't start the vertex while any of its groups are at their limit,
+// as set by SetGroupLimit.
+type GroupedVertex interface {
+	ConcurrencyGroups() []string
+}
+
+// concurrencyGroups holds the limits for every group in the process, so that
+// concurrent walks are limited together.
+var concurrencyGroups = struct {
+	sync.Mutex
+	cond    *sync.Cond
+	limits  map[string]int
+	running map[string]int
+}{
+	limits:  make(map[string]int),
+	running: make(map[string]int),
+}
+
+func init() {
+	concurrencyGroups.cond = sync.NewCond(&concurrencyGroups.Mutex)
+}
+
+// SetGroupLimit sets the number of vertices in the named group that may run
+// at once, across every walk in the process. A limit of 0 or less removes
+// the limit.
+func SetGroupLimit(name string, n int) {
+	concurrencyGroups.Lock()
+	defer concurrencyGroups.Unlock()
+
+	if n <= 0 {
+		delete(concurrencyGroups.limits, name)
+	} else {
+		concurrencyGroups.limits[name] = n
+	}
+	concurrencyGroups.cond.Broadcast()
+}
+
+// GroupLimit returns the limit of the named group, or 0 if it has none.
+func GroupLimit(name string) int {
+	concurrencyGroups.Lock()
+	defer concurrencyGroups.Unlock()
+	return concurrencyGroups.limits[name]
+}
+
+// GroupRunning returns the number of vertices in the named group that are
+// running.
+func GroupRunning(name string) int {
+	concurrencyGroups.Lock()
+	defer concurrencyGroups.Unlock()
+	return concurrencyGroups.running[name]
+}
+
+// vertexGroups returns the sorted, unique concurrency groups of v.
+func vertexGroups[T Hashable](v T) []string {
+	var raw interface{}
+	raw = v
+	gv, ok := raw.(GroupedVertex)
+	if !ok {
+		return nil
+	}
+
+	var groups []string
+	seen := make(map[string]bool)
+	for _, g := range gv.ConcurrencyGroups() {
+		if !seen[g] {
+			seen[g] = true
+			groups = append(groups, g)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// acquireGroups blocks until every group has room, then takes a place in
+// all of them at once, so that vertices sharing several groups can't
+// deadlock. The returned func gives the places back.
+func acquireGroups(groups []string) func() {
+	if len(groups) == 0 {
+		return func() {}
+	}
+
+	cg := &concurrencyGroups
+	cg.Lock()
+	for !groupsFree(groups) {
+		cg.cond.Wait()
+	}
+	for _, g := range groups {
+		cg.running[g]++
+	}
+	cg.Unlock()
+
+	return func() {
+		cg.Lock()
+		for _, g := range groups {
+			cg.running[g]--
+			if cg.running[g] == 0 {
+				delete(cg.running, g)
+			}
+		}
+		cg.cond.Broadcast()
+		cg.Unlock()
+	}
+}
+
+// groupsFree returns true if none of the groups are at their limit. The
+// caller must hold the concurrencyGroups lock.
+func groupsFree(groups []string) bool {
+	for _, g := range groups {
+		limit, ok := concurrencyGroups.limits[g]
+		if ok && concurrencyGroups.running[g] >= limit {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package dagg
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type groupVertex struct {
+	name   string
+	groups []string
+}
+
+func (v *groupVertex) Hashcode() string            { return v.name }
+func (v *groupVertex) ConcurrencyGroups() []string { return v.groups }
+
+func TestWalker_concurrencyGroups(t *testing.T) {
+	SetGroupLimit("test-db", 2)
+	defer SetGroupLimit("test-db", 0)
+
+	var lock sync.Mutex
+	running, max := 0, 0
+	cb := func(v *groupVertex) error {
+		lock.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+		return nil
+	}
+
+	// several walks at once share the one limit
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		var g AcyclicGraph[*groupVertex]
+		g.Add(&groupVertex{name: "a", groups: []string{"test-db"}})
+		g.Add(&groupVertex{name: "b", groups: []string{"test-db", "test-db"}})
+		g.Add(&groupVertex{name: "c", groups: []string{"test-db", "test-other"}})
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := g.Walk(cb); err != nil {
+				t.Errorf("err: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if max != 2 {
+		t.Fatalf("bad: %d running at once", max)
+	}
+	if n := GroupRunning("test-db"); n != 0 {
+		t.Fatalf("bad: %d still running", n)
+	}
+}
+
+func TestSetGroupLimit(t *testing.T) {
+	SetGroupLimit("test-limit", 1)
+	release := acquireGroups([]string{"test-limit"})
+
+	acquired := make(chan struct{})
+	go func() {
+		acquireGroups([]string{"test-limit"})()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("should wait for the group")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// raising the limit lets the waiting vertex run
+	SetGroupLimit("test-limit", 2)
+	<-acquired
+	release()
+
+	SetGroupLimit("test-limit", 0)
+	if n := GroupLimit("test-limit"); n != 0 {
+		t.Fatalf("bad: %d", n)
+	}
+}
@@ -0,0 +1,132 @@
+package dagg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HeartbeatFunc is a walk callback which is given a Heartbeat to report
+// progress with. The context is cancelled if the vertex stalls and the
+// walker is set to CancelStalled.
+type HeartbeatFunc[T Hashable] func(context.Context, T, *Heartbeat) error
+
+// Heartbeat is used by a running vertex to report that it is still alive.
+type Heartbeat struct {
+	lock     sync.Mutex
+	last     time.Time
+	progress string
+}
+
+// Beat records that the vertex is still making progress.
+func (h *Heartbeat) Beat() {
+	h.Progress("")
+}
+
+// Progress records that the vertex is still making progress, along with a
+// description of that progress.
+func (h *Heartbeat) Progress(msg string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.last = time.Now()
+	h.progress = msg
+}
+
+// Last returns the time of the last heartbeat, and the last progress
+// reported.
+func (h *Heartbeat) Last() (time.Time, string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.last, h.progress
+}
+
+func (h *Heartbeat) stalled(timeout time.Duration) bool {
+	last, _ := h.Last()
+	return time.Since(last) > timeout
+}
+
+// Stalled returns the running vertices which have not sent a heartbeat
+// within the HeartbeatTimeout, sorted by name.
+func (w *Walker[T]) Stalled() []T {
+	if w.HeartbeatTimeout <= 0 {
+		return nil
+	}
+
+	w.heartbeatLock.Lock()
+	defer w.heartbeatLock.Unlock()
+
+	var result []T
+	for _, r := range w.heartbeats {
+		if r.hb.stalled(w.HeartbeatTimeout) {
+			result = append(result, r.v)
+		}
+	}
+	sort.Sort(byVertexName[T](result))
+	return result
+}
+
+// runningVertex is a vertex currently being executed by a HeartbeatFunc.
+type runningVertex[T Hashable] struct {
+	v  T
+	hb *Heartbeat
+}
+
+// executeHeartbeat runs the HeartbeatCallback for v, watching for it to
+// stall if CancelStalled is set.
+func (w *Walker[T]) executeHeartbeat(ctx context.Context, v T) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	hb := &Heartbeat{}
+	hb.Beat()
+
+	w.heartbeatLock.Lock()
+	if w.heartbeats == nil {
+		w.heartbeats = make(map[string]*runningVertex[T])
+	}
+	w.heartbeats[v.Hashcode()] = &runningVertex[T]{v: v, hb: hb}
+	w.heartbeatLock.Unlock()
+
+	defer func() {
+		w.heartbeatLock.Lock()
+		delete(w.heartbeats, v.Hashcode())
+		w.heartbeatLock.Unlock()
+	}()
+
+	var stalled bool
+	var stallLock sync.Mutex
+	if w.CancelStalled && w.HeartbeatTimeout > 0 {
+		done := make(chan struct{})
+		defer close(done)
+
+		go func() {
+			ticker := time.NewTicker(w.HeartbeatTimeout / 2)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					if hb.stalled(w.HeartbeatTimeout) {
+						stallLock.Lock()
+						stalled = true
+						stallLock.Unlock()
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	err := w.HeartbeatCallback(ctx, v, hb)
+
+	stallLock.Lock()
+	defer stallLock.Unlock()
+	if stalled {
+		return fmt.Errorf("vertex %q stalled: no heartbeat within %s", VertexName(v), w.HeartbeatTimeout)
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package dagg
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWalker_heartbeatStalled(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	w := &Walker[myint]{
+		HeartbeatTimeout: 20 * time.Millisecond,
+		HeartbeatCallback: func(ctx context.Context, v myint, hb *Heartbeat) error {
+			started <- struct{}{}
+			for {
+				if v == 1 {
+					hb.Beat()
+				}
+				select {
+				case <-release:
+					return nil
+				case <-time.After(5 * time.Millisecond):
+				}
+			}
+		},
+	}
+	w.Update(&g)
+	<-started
+	<-started
+
+	time.Sleep(50 * time.Millisecond)
+	stalled := w.Stalled()
+	close(release)
+
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(stalled, []myint{2}) {
+		t.Fatalf("bad: %#v", stalled)
+	}
+	if stalled := w.Stalled(); len(stalled) != 0 {
+		t.Fatalf("bad: %#v", stalled)
+	}
+}
+
+func TestWalker_heartbeatCancelStalled(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+
+	w := &Walker[myint]{
+		HeartbeatTimeout: 10 * time.Millisecond,
+		CancelStalled:    true,
+		HeartbeatCallback: func(ctx context.Context, v myint, hb *Heartbeat) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	w.Update(&g)
+
+	err := w.Wait()
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	if !strings.Contains(err.Error(), `vertex "1" stalled`) {
+		t.Fatalf("bad: %s", err)
+	}
+}
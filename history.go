@@ -0,0 +1,116 @@
+package dagg
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DurationStats are the runs of a vertex over many walks, and how long they
+// took. They're shared by the statistics of SLOHistory and RunHistory.
+type DurationStats struct {
+	Vertex string
+	Name   string
+
+	// Runs is the number of runs of the vertex.
+	Runs int
+
+	// Worst is the longest run of the vertex, and Total the sum of all its
+	// runs.
+	Worst time.Duration
+	Total time.Duration
+
+	// squares is the sum of the squares of the durations in seconds, for
+	// the variance.
+	squares float64
+}
+
+// Mean returns the mean duration of the runs.
+func (s *DurationStats) Mean() time.Duration {
+	if s.Runs == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Runs)
+}
+
+// StdDev returns the standard deviation of the durations of the runs.
+func (s *DurationStats) StdDev() time.Duration {
+	if s.Runs == 0 {
+		return 0
+	}
+	mean := s.Total.Seconds() / float64(s.Runs)
+	variance := s.squares/float64(s.Runs) - mean*mean
+	if variance <= 0 {
+		return 0
+	}
+	return time.Duration(math.Sqrt(variance) * float64(time.Second))
+}
+
+// Variation returns the standard deviation of the durations of the runs as
+// a fraction of their mean, so vertices of different lengths can be
+// compared.
+func (s *DurationStats) Variation() float64 {
+	mean := s.Mean()
+	if mean == 0 {
+		return 0
+	}
+	return float64(s.StdDev()) / float64(mean)
+}
+
+func (s *DurationStats) durations() *DurationStats {
+	return s
+}
+
+// withDurations is a pointer to statistics which embed DurationStats.
+type withDurations[S any] interface {
+	*S
+	durations() *DurationStats
+}
+
+// runHistory aggregates the runs of each vertex over many walks, by
+// hashcode, into statistics which embed DurationStats. The zero value is
+// ready to use.
+type runHistory[S any, PS withDurations[S]] struct {
+	stats map[string]PS
+}
+
+// add counts a run of the vertex with the hashcode id which took d, and
+// returns its statistics so the caller can record the rest of the run.
+func (h *runHistory[S, PS]) add(id, name string, d time.Duration) PS {
+	if h.stats == nil {
+		h.stats = make(map[string]PS)
+	}
+
+	s, ok := h.stats[id]
+	if !ok {
+		s = PS(new(S))
+		s.durations().Vertex = id
+		h.stats[id] = s
+	}
+
+	ds := s.durations()
+	if name != "" {
+		ds.Name = name
+	}
+	ds.Runs++
+	ds.Total += d
+	ds.squares += d.Seconds() * d.Seconds()
+	if d > ds.Worst {
+		ds.Worst = d
+	}
+	return s
+}
+
+// sorted returns copies of the statistics of every vertex, sorted by
+// hashcode.
+func (h *runHistory[S, PS]) sorted() []PS {
+	result := make([]PS, 0, len(h.stats))
+	for _, s := range h.stats {
+		c := *s
+		result = append(result, PS(&c))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].durations().Vertex < result[j].durations().Vertex
+	})
+	return result
+}
@@ -0,0 +1,256 @@
+package dagg
+
+import "fmt"
+
+// ImmutableGraph is a persistent graph. Rather than modifying the graph,
+// Add, Remove, Connect and RemoveEdge return a new graph which shares its
+// structure with the original, so keeping every version of a large graph
+// only costs memory in proportion to the changes between them.
+//
+// The zero value is an empty graph. An ImmutableGraph is safe for
+// concurrent use, since it is never modified.
+type ImmutableGraph[T Hashable] struct {
+	vertices pmap[T]
+	edges    pmap[Edge[T]]
+
+	// downEdges and upEdges map the hashcode of a vertex to the vertices at
+	// the other end of its directed edges.
+	downEdges pmap[pmap[T]]
+	upEdges   pmap[pmap[T]]
+
+	// neighbors records the vertices joined by undirected edges, in both
+	// directions, as in Graph.
+	neighbors pmap[pmap[T]]
+
+	// policy is how Connect treats vertices which aren't in the graph.
+	policy VertexPolicy
+}
+
+// NewImmutableGraph returns an ImmutableGraph with the vertices, edges and
+// VertexPolicy of g.
+func NewImmutableGraph[T Hashable](g *Graph[T]) *ImmutableGraph[T] {
+	result := &ImmutableGraph[T]{policy: g.policy}
+	for _, v := range g.vertices {
+		result = result.Add(v)
+	}
+
+	// the edges were connected according to the policy of g, so they're
+	// kept whatever it is
+	for _, e := range g.edges {
+		result = result.connect(e)
+	}
+	return result
+}
+
+// WithVertexPolicy returns a graph which treats vertices which aren't in
+// the graph according to p in Connect.
+func (g *ImmutableGraph[T]) WithVertexPolicy(p VertexPolicy) *ImmutableGraph[T] {
+	result := *g
+	result.policy = p
+	return &result
+}
+
+// VertexPolicy returns the graph's VertexPolicy.
+func (g *ImmutableGraph[T]) VertexPolicy() VertexPolicy {
+	return g.policy
+}
+
+// Len returns the number of vertices in the graph.
+func (g *ImmutableGraph[T]) Len() int {
+	return g.vertices.Len()
+}
+
+// Vertices returns the list of all the vertices in the graph.
+func (g *ImmutableGraph[T]) Vertices() []T {
+	result := make([]T, 0, g.vertices.Len())
+	g.vertices.Each(func(_ string, v T) bool {
+		result = append(result, v)
+		return true
+	})
+	return result
+}
+
+// Edges returns the list of all the edges in the graph.
+func (g *ImmutableGraph[T]) Edges() []Edge[T] {
+	result := make([]Edge[T], 0, g.edges.Len())
+	g.edges.Each(func(_ string, e Edge[T]) bool {
+		result = append(result, e)
+		return true
+	})
+	return result
+}
+
+// HasVertex checks if the given vertex is present in the graph.
+func (g *ImmutableGraph[T]) HasVertex(v T) bool {
+	_, ok := g.vertices.Get(v.Hashcode())
+	return ok
+}
+
+// HasEdge checks if the given edge is present in the graph.
+func (g *ImmutableGraph[T]) HasEdge(e Edge[T]) bool {
+	_, ok := g.edges.Get(keyOf(e).String())
+	return ok
+}
+
+// DownEdges returns the vertices that are *targets* of directed edges
+// originating from the given vertex.
+func (g *ImmutableGraph[T]) DownEdges(v T) Set[T] {
+	down, _ := g.downEdges.Get(v.Hashcode())
+	return pmapSet(down)
+}
+
+// UpEdges returns the vertices that are *sources* of directed edges that
+// target the given vertex.
+func (g *ImmutableGraph[T]) UpEdges(v T) Set[T] {
+	up, _ := g.upEdges.Get(v.Hashcode())
+	return pmapSet(up)
+}
+
+// Neighbors returns every vertex joined to v by an edge, ignoring the
+// direction of the edge. This includes both directed and undirected edges.
+func (g *ImmutableGraph[T]) Neighbors(v T) Set[T] {
+	result := make(Set[T])
+	for _, adj := range []pmap[pmap[T]]{g.downEdges, g.upEdges, g.neighbors} {
+		s, _ := adj.Get(v.Hashcode())
+		s.Each(func(k string, n T) bool {
+			result[k] = n
+			return true
+		})
+	}
+	return result
+}
+
+// Add returns a graph with the vertex added.
+func (g *ImmutableGraph[T]) Add(v T) *ImmutableGraph[T] {
+	result := *g
+	result.vertices = g.vertices.Set(v.Hashcode(), v)
+	return &result
+}
+
+// Remove returns a graph without the vertex or any of its edges.
+func (g *ImmutableGraph[T]) Remove(v T) *ImmutableGraph[T] {
+	result := g
+	for _, target := range g.DownEdges(v) {
+		result = result.RemoveEdge(BasicEdge(v, target))
+	}
+	for _, source := range g.UpEdges(v) {
+		result = result.RemoveEdge(BasicEdge(source, v))
+	}
+	neighbors, _ := g.neighbors.Get(v.Hashcode())
+	neighbors.Each(func(_ string, n T) bool {
+		result = result.RemoveEdge(UndirectedEdge(v, n))
+		return true
+	})
+
+	removed := *result
+	removed.vertices = result.vertices.Delete(v.Hashcode())
+	return &removed
+}
+
+// Connect returns a graph with the edge added.
+//
+// Vertices of the edge which aren't in the graph are handled according to
+// the graph's VertexPolicy, as by Graph.Connect: by default they are added,
+// but with StrictVertices an error is returned and no graph.
+func (g *ImmutableGraph[T]) Connect(edge Edge[T]) (*ImmutableGraph[T], error) {
+	result := g
+	if g.policy != AllowDanglingEdges {
+		for _, v := range []T{edge.Source(), edge.Target()} {
+			if result.HasVertex(v) {
+				continue
+			}
+			if g.policy == StrictVertices {
+				return nil, fmt.Errorf("vertex %q not found", VertexName(v))
+			}
+			result = result.Add(v)
+		}
+	}
+	return result.connect(edge), nil
+}
+
+// connect returns a graph with the edge added, regardless of the
+// VertexPolicy.
+func (g *ImmutableGraph[T]) connect(edge Edge[T]) *ImmutableGraph[T] {
+	if g.HasEdge(edge) {
+		return g
+	}
+
+	source, target := edge.Source(), edge.Target()
+	result := *g
+	result.edges = g.edges.Set(keyOf(edge).String(), edge)
+	if !IsDirected(edge) {
+		result.neighbors = pmapAdd(g.neighbors, source.Hashcode(), target)
+		result.neighbors = pmapAdd(result.neighbors, target.Hashcode(), source)
+		return &result
+	}
+	result.downEdges = pmapAdd(g.downEdges, source.Hashcode(), target)
+	result.upEdges = pmapAdd(g.upEdges, target.Hashcode(), source)
+	return &result
+}
+
+// RemoveEdge returns a graph without the edge.
+func (g *ImmutableGraph[T]) RemoveEdge(edge Edge[T]) *ImmutableGraph[T] {
+	key := keyOf(edge).String()
+	stored, ok := g.edges.Get(key)
+	if !ok {
+		return g
+	}
+
+	// an undirected edge may be removed either way round, so the ends are
+	// taken from the edge as it was connected
+	source, target := stored.Source(), stored.Target()
+	result := *g
+	result.edges = g.edges.Delete(key)
+	if !IsDirected(stored) {
+		result.neighbors = pmapRemove(g.neighbors, source.Hashcode(), target)
+		result.neighbors = pmapRemove(result.neighbors, target.Hashcode(), source)
+		return &result
+	}
+	result.downEdges = pmapRemove(g.downEdges, source.Hashcode(), target)
+	result.upEdges = pmapRemove(g.upEdges, target.Hashcode(), source)
+	return &result
+}
+
+// Graph returns a mutable copy of the graph, with the same VertexPolicy.
+func (g *ImmutableGraph[T]) Graph() *Graph[T] {
+	result := &Graph[T]{}
+	result.SetVertexPolicy(g.policy)
+	g.vertices.Each(func(_ string, v T) bool {
+		result.Add(v)
+		return true
+	})
+
+	// the edges were connected according to the policy, so they're kept
+	// whatever it is, and a dangling edge stays dangling
+	g.edges.Each(func(_ string, e Edge[T]) bool {
+		result.connect(e)
+		return true
+	})
+	return result
+}
+
+// pmapAdd adds v to the set stored at key in an adjacency map.
+func pmapAdd[T Hashable](m pmap[pmap[T]], key string, v T) pmap[pmap[T]] {
+	s, _ := m.Get(key)
+	return m.Set(key, s.Set(v.Hashcode(), v))
+}
+
+// pmapRemove removes v from the set stored at key in an adjacency map.
+func pmapRemove[T Hashable](m pmap[pmap[T]], key string, v T) pmap[pmap[T]] {
+	s, _ := m.Get(key)
+	s = s.Delete(v.Hashcode())
+	if s.Len() == 0 {
+		return m.Delete(key)
+	}
+	return m.Set(key, s)
+}
+
+// pmapSet copies a persistent set of vertices into a Set.
+func pmapSet[T Hashable](m pmap[T]) Set[T] {
+	result := make(Set[T], m.Len())
+	m.Each(func(k string, v T) bool {
+		result[k] = v
+		return true
+	})
+	return result
+}
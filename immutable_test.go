@@ -0,0 +1,160 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImmutableGraph(t *testing.T) {
+	var empty ImmutableGraph[myint]
+	g1 := empty.Add(myint(1)).Add(myint(2)).Add(myint(3))
+	g2 := testImmutableConnect(t, g1,
+		BasicEdge(myint(1), myint(2)),
+		BasicEdge(myint(2), myint(3)))
+	g3 := g2.Remove(myint(2))
+
+	if empty.Len() != 0 || g1.Len() != 3 || g3.Len() != 2 {
+		t.Fatalf("bad lens: %d %d %d", empty.Len(), g1.Len(), g3.Len())
+	}
+	if len(g1.Edges()) != 0 {
+		t.Fatalf("bad: %#v", g1.Edges())
+	}
+
+	actual := strings.TrimSpace(g2.Graph().String())
+	expected := strings.TrimSpace(testImmutableGraphStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	if g3.HasVertex(myint(2)) || len(g3.Edges()) != 0 {
+		t.Fatalf("bad: %s", g3.Graph())
+	}
+	if g3.DownEdges(myint(1)).Len() != 0 || g3.UpEdges(myint(3)).Len() != 0 {
+		t.Fatalf("bad: %s", g3.Graph())
+	}
+
+	// the previous version is unchanged
+	if !g2.HasVertex(myint(2)) || !g2.DownEdges(myint(1)).Include(myint(2)) {
+		t.Fatalf("bad: %s", g2.Graph())
+	}
+}
+
+func TestImmutableGraph_undirected(t *testing.T) {
+	var empty ImmutableGraph[myint]
+	g := testImmutableConnect(t, empty.Add(myint(1)).Add(myint(2)).Add(myint(3)),
+		UndirectedEdge(myint(1), myint(2)),
+		UndirectedEdge(myint(3), myint(2)))
+
+	removed := g.Remove(myint(1))
+	if edges := removed.Edges(); len(edges) != 1 {
+		t.Fatalf("bad: %#v", edges)
+	}
+	if n := removed.Neighbors(myint(2)); n.Include(myint(1)) || !n.Include(myint(3)) {
+		t.Fatalf("bad: %#v", n)
+	}
+
+	// either way round
+	removed = removed.RemoveEdge(UndirectedEdge(myint(2), myint(3)))
+	if edges := removed.Edges(); len(edges) != 0 {
+		t.Fatalf("bad: %#v", edges)
+	}
+	if n := removed.Neighbors(myint(3)); n.Len() != 0 {
+		t.Fatalf("bad: %#v", n)
+	}
+}
+
+func TestImmutableGraph_directedAndUndirected(t *testing.T) {
+	var empty ImmutableGraph[myint]
+	g := testImmutableConnect(t, empty.Add(myint(1)).Add(myint(2)),
+		BasicEdge(myint(1), myint(2)),
+		UndirectedEdge(myint(1), myint(2)))
+
+	// removing either edge keeps the other
+	directed := g.RemoveEdge(UndirectedEdge(myint(1), myint(2)))
+	if !directed.DownEdges(myint(1)).Include(myint(2)) || !directed.UpEdges(myint(2)).Include(myint(1)) {
+		t.Fatalf("bad: %s", directed.Graph())
+	}
+	if !directed.HasEdge(BasicEdge(myint(1), myint(2))) {
+		t.Fatalf("bad: %#v", directed.Edges())
+	}
+
+	undirected := g.RemoveEdge(BasicEdge(myint(1), myint(2)))
+	if undirected.DownEdges(myint(1)).Len() != 0 || !undirected.Neighbors(myint(2)).Include(myint(1)) {
+		t.Fatalf("bad: %#v", undirected.Edges())
+	}
+	if !undirected.HasEdge(UndirectedEdge(myint(2), myint(1))) {
+		t.Fatalf("bad: %#v", undirected.Edges())
+	}
+}
+
+func TestImmutableGraph_vertexPolicy(t *testing.T) {
+	var empty ImmutableGraph[myint]
+	edge := BasicEdge(myint(1), myint(2))
+
+	// by default the vertices are added
+	g, err := empty.Add(myint(1)).Connect(edge)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !g.HasVertex(myint(2)) {
+		t.Fatalf("bad: %#v", g.Vertices())
+	}
+
+	strict := empty.Add(myint(1)).WithVertexPolicy(StrictVertices)
+	if _, err := strict.Connect(edge); err == nil {
+		t.Fatal("should error")
+	}
+
+	// a dangling edge is kept by Graph, and by NewImmutableGraph
+	dangling, err := empty.Add(myint(1)).WithVertexPolicy(AllowDanglingEdges).Connect(edge)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if dangling.HasVertex(myint(2)) {
+		t.Fatalf("bad: %#v", dangling.Vertices())
+	}
+	mutable := dangling.Graph()
+	if mutable.VertexPolicy() != AllowDanglingEdges || len(mutable.DanglingEdges()) != 1 {
+		t.Fatalf("bad: %s", mutable)
+	}
+	again := NewImmutableGraph(mutable)
+	if again.VertexPolicy() != AllowDanglingEdges || again.HasVertex(myint(2)) || !again.HasEdge(edge) {
+		t.Fatalf("bad: %#v", again.Edges())
+	}
+}
+
+func TestNewImmutableGraph(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+
+	ig := NewImmutableGraph(&g)
+	actual := strings.TrimSpace(ig.Graph().String())
+	expected := strings.TrimSpace(testImmutableGraphStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+// testImmutableConnect connects each of the edges in turn.
+func testImmutableConnect(t *testing.T, g *ImmutableGraph[myint], edges ...Edge[myint]) *ImmutableGraph[myint] {
+	t.Helper()
+	for _, e := range edges {
+		var err error
+		if g, err = g.Connect(e); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	return g
+}
+
+const testImmutableGraphStr = `
+1
+  2
+2
+  3
+3
+`
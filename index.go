@@ -0,0 +1,187 @@
+package dagg
+
+// idSet is a set of vertices keyed by their interned ID, as held in the
+// adjacency of a Graph.
+type idSet[T Hashable] map[int]T
+
+// Len is the number of items in the set.
+func (s idSet[T]) Len() int {
+	return len(s)
+}
+
+// List returns the list of set elements.
+func (s idSet[T]) List() []T {
+	if s == nil {
+		return nil
+	}
+
+	r := make([]T, 0, len(s))
+	for _, v := range s {
+		r = append(r, v)
+	}
+	return r
+}
+
+// Set returns the elements as a Set, keyed by Hashcode.
+func (s idSet[T]) Set() Set[T] {
+	result := make(Set[T], len(s))
+	for _, v := range s {
+		result.Add(v)
+	}
+	return result
+}
+
+// Copy returns a shallow copy of the set.
+func (s idSet[T]) Copy() idSet[T] {
+	c := make(idSet[T], len(s))
+	for k, v := range s {
+		c[k] = v
+	}
+	return c
+}
+
+// intern returns the ID of v, giving it one if it doesn't have one yet.
+// The graph's storage must not be shared.
+func (g *Graph[T]) intern(v T) int {
+	h := v.Hashcode()
+	if id, ok := g.ids[h]; ok {
+		return id
+	}
+
+	var id int
+	if n := len(g.free); n > 0 {
+		id = g.free[n-1]
+		g.free = g.free[:n-1]
+	} else {
+		id = len(g.ids)
+	}
+	g.ids[h] = id
+	return id
+}
+
+// forget frees the ID of v for reuse once it has no edges left, so the IDs
+// of a graph with a lot of churn stay dense. The graph's storage must not
+// be shared.
+func (g *Graph[T]) forget(v T) {
+	h := v.Hashcode()
+	id, ok := g.ids[h]
+	if !ok {
+		return
+	}
+	for _, adj := range []map[int]idSet[T]{g.downEdges, g.upEdges, g.neighbors} {
+		if _, ok := adj[id]; ok {
+			return
+		}
+	}
+	delete(g.ids, h)
+	g.free = append(g.free, id)
+}
+
+// adjacent returns the vertices adj holds for v, without copying them.
+func (g *Graph[T]) adjacent(adj map[int]idSet[T], v T) idSet[T] {
+	id, ok := g.ids[v.Hashcode()]
+	if !ok {
+		return nil
+	}
+	return adj[id]
+}
+
+// addAdjacent records b in the adjacency of a.
+func addAdjacent[T Hashable](adj map[int]idSet[T], a, b int, v T) {
+	s, ok := adj[a]
+	if !ok {
+		s = make(idSet[T])
+		adj[a] = s
+	}
+	s[b] = v
+}
+
+// removeAdjacent removes b from the adjacency of a, dropping the set of a
+// once it's empty.
+func removeAdjacent[T Hashable](adj map[int]idSet[T], a, b int) {
+	if s, ok := adj[a]; ok {
+		delete(s, b)
+		if len(s) == 0 {
+			delete(adj, a)
+		}
+	}
+}
+
+// graphIndex numbers the vertices of a graph densely from zero, with the
+// directed adjacency held in slices. The IDs interned by the graph itself
+// can have gaps, and include the ends of dangling edges, so whole-graph
+// algorithms build one up front for their inner loops. An index is a
+// snapshot, and isn't updated when the graph changes.
+type graphIndex[T Hashable] struct {
+	ids      map[string]int
+	vertices []T
+	down, up [][]int
+}
+
+// index builds a graphIndex of the vertices in the graph. Edges to vertices
+// which are not in the graph are left out.
+//
+// Complexity: O(V+E)
+func (g *Graph[T]) index() *graphIndex[T] {
+	idx := &graphIndex[T]{
+		ids:      make(map[string]int, len(g.vertices)),
+		vertices: make([]T, 0, len(g.vertices)),
+	}
+
+	// dense maps the graph's IDs to the index's, or -1 for the ends of
+	// dangling edges
+	dense := make([]int, len(g.ids)+len(g.free))
+	for i := range dense {
+		dense[i] = -1
+	}
+	for h, v := range g.vertices {
+		if id, ok := g.ids[h]; ok {
+			dense[id] = len(idx.vertices)
+		}
+		idx.ids[h] = len(idx.vertices)
+		idx.vertices = append(idx.vertices, v)
+	}
+
+	idx.down = make([][]int, len(idx.vertices))
+	idx.up = make([][]int, len(idx.vertices))
+	for id, targets := range g.downEdges {
+		i := dense[id]
+		if i < 0 {
+			continue
+		}
+		for t := range targets {
+			if j := dense[t]; j >= 0 {
+				idx.down[i] = append(idx.down[i], j)
+				idx.up[j] = append(idx.up[j], i)
+			}
+		}
+	}
+	return idx
+}
+
+// topological returns the IDs ordered so that every vertex comes before the
+// targets of its edges, or false if the graph contains a cycle.
+//
+// Complexity: O(V+E)
+func (idx *graphIndex[T]) topological() ([]int, bool) {
+	inDegree := make([]int, len(idx.vertices))
+	order := make([]int, 0, len(idx.vertices))
+	for i := range idx.vertices {
+		inDegree[i] = len(idx.up[i])
+		if inDegree[i] == 0 {
+			order = append(order, i)
+		}
+	}
+
+	// order doubles as the queue
+	for next := 0; next < len(order); next++ {
+		for _, j := range idx.down[order[next]] {
+			inDegree[j]--
+			if inDegree[j] == 0 {
+				order = append(order, j)
+			}
+		}
+	}
+
+	return order, len(order) == len(idx.vertices)
+}
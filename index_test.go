@@ -0,0 +1,45 @@
+package dagg
+
+import (
+	"testing"
+)
+
+func TestGraphIndex(t *testing.T) {
+	var g Graph[myint]
+	g.SetVertexPolicy(AllowDanglingEdges)
+	g.Add(1)
+	g.Add(2)
+	g.Add(3)
+	g.Connect(BasicEdge[myint](1, 2))
+	g.Connect(BasicEdge[myint](2, 3))
+	g.Connect(BasicEdge[myint](1, 3))
+	g.Connect(BasicEdge[myint](3, 4))
+
+	idx := g.index()
+	if len(idx.vertices) != 3 {
+		t.Fatalf("bad: %#v", idx.vertices)
+	}
+
+	// the dangling edge to 4 is left out
+	id := func(v myint) int { return idx.ids[v.Hashcode()] }
+	if len(idx.down[id(1)]) != 2 || len(idx.down[id(3)]) != 0 || len(idx.up[id(3)]) != 2 {
+		t.Fatalf("bad: down %v, up %v", idx.down, idx.up)
+	}
+
+	order, ok := idx.topological()
+	if !ok {
+		t.Fatal("should be acyclic")
+	}
+	var actual []myint
+	for _, i := range order {
+		actual = append(actual, idx.vertices[i])
+	}
+	if len(actual) != 3 || actual[0] != 1 || actual[1] != 2 || actual[2] != 3 {
+		t.Fatalf("bad: %v", actual)
+	}
+
+	g.Connect(BasicEdge[myint](3, 1))
+	if _, ok := g.index().topological(); ok {
+		t.Fatal("should find the cycle")
+	}
+}
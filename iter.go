@@ -0,0 +1,104 @@
+//go:build go1.23
+
+package dagg
+
+import (
+	"iter"
+)
+
+// VerticesSeq returns an iterator over the vertices of the graph, in no
+// particular order. The graph must not be modified during iteration.
+func (g *Graph[T]) VerticesSeq() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range g.vertices {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// EdgesSeq returns an iterator over the edges of the graph, in no particular
+// order. The graph must not be modified during iteration.
+func (g *Graph[T]) EdgesSeq() iter.Seq[Edge[T]] {
+	return func(yield func(Edge[T]) bool) {
+		for _, e := range g.edges {
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// DFS returns an iterator doing a depth-first walk of the graph from start,
+// iterating the targets of each vertex in a consistent order. The graph must
+// not be modified during iteration.
+func (g *Graph[T]) DFS(start T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		seen := make(map[string]struct{})
+		frontier := []T{start}
+		for len(frontier) > 0 {
+			// Pop the current vertex
+			n := len(frontier)
+			current := frontier[n-1]
+			frontier = frontier[:n-1]
+
+			if _, ok := seen[current.Hashcode()]; ok {
+				continue
+			}
+			seen[current.Hashcode()] = struct{}{}
+
+			if !yield(current) {
+				return
+			}
+
+			// Push the targets in reverse order so the first target is
+			// visited first.
+			targets := g.downEdgesNoCopy(current).List()
+			g.sortByName(targets)
+			for i, j := 0, len(targets)-1; i < j; i, j = i+1, j-1 {
+				targets[i], targets[j] = targets[j], targets[i]
+			}
+			frontier = append(frontier, targets...)
+		}
+	}
+}
+
+// TopologicalSeq returns an iterator over the vertices of the graph ordered
+// so that every vertex comes before the targets of its edges. Vertices which
+// are part of, or depend on, a cycle are never reached, so the graph should
+// be validated first. The graph must not be modified during iteration.
+func (g *AcyclicGraph[T]) TopologicalSeq() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		inDegree := make(map[string]int, len(g.vertices))
+		var queue []T
+		for _, v := range g.vertices {
+			for _, u := range g.upEdgesNoCopy(v) {
+				if g.HasVertex(u) {
+					inDegree[v.Hashcode()]++
+				}
+			}
+			if inDegree[v.Hashcode()] == 0 {
+				queue = append(queue, v)
+			}
+		}
+
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			if !yield(current) {
+				return
+			}
+
+			for _, v := range g.downEdgesNoCopy(current) {
+				if !g.HasVertex(v) {
+					continue
+				}
+				inDegree[v.Hashcode()]--
+				if inDegree[v.Hashcode()] == 0 {
+					queue = append(queue, v)
+				}
+			}
+		}
+	}
+}
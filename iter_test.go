@@ -0,0 +1,64 @@
+//go:build go1.23
+
+package dagg
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGraphSeq(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(4)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+
+	var vertices []myint
+	for v := range g.VerticesSeq() {
+		vertices = append(vertices, v)
+	}
+	sort.Sort(byVertexName[myint](vertices))
+	if !reflect.DeepEqual(vertices, []myint{1, 2, 3, 4}) {
+		t.Fatalf("bad: %#v", vertices)
+	}
+
+	edges := 0
+	for range g.EdgesSeq() {
+		edges++
+	}
+	if edges != 4 {
+		t.Fatalf("bad: %d", edges)
+	}
+
+	var dfs []myint
+	for v := range g.DFS(myint(1)) {
+		dfs = append(dfs, v)
+	}
+	if !reflect.DeepEqual(dfs, []myint{1, 2, 4, 3}) {
+		t.Fatalf("bad: %#v", dfs)
+	}
+
+	var topo []myint
+	for v := range g.TopologicalSeq() {
+		topo = append(topo, v)
+	}
+	if len(topo) != 4 || topo[0] != myint(1) || topo[3] != myint(4) {
+		t.Fatalf("bad: %#v", topo)
+	}
+
+	// breaking out early must stop the iteration
+	var first []myint
+	for v := range g.TopologicalSeq() {
+		first = append(first, v)
+		break
+	}
+	if !reflect.DeepEqual(first, []myint{1}) {
+		t.Fatalf("bad: %#v", first)
+	}
+}
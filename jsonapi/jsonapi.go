@@ -0,0 +1,181 @@
+// Package jsonapi is a facade over dagg which takes and returns JSON, for
+// callers which can't use the generic API, such as JavaScript through the
+// WebAssembly build in cmd/dagg-wasm. Vertices are named by strings, and as
+// in dagg the source of an edge depends on its target.
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/streemtech/dagg"
+)
+
+// Vertex is a vertex named by a string.
+type Vertex string
+
+func (v Vertex) Hashcode() string {
+	return string(v)
+}
+
+// Graph is the JSON form of a graph. Vertices named by edges don't need to
+// be listed in Vertices.
+type Graph struct {
+	Vertices []string `json:"vertices"`
+	Edges    []Edge   `json:"edges"`
+}
+
+// Edge is an edge whose Source depends on its Target.
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// Result is the JSON result of every function of the package. Error is set
+// if the function failed, along with any fields that explain why.
+type Result struct {
+	Error string `json:"error,omitempty"`
+
+	// Cycles lists the cycles found by Validate, each as the names of its
+	// vertices.
+	Cycles [][]string `json:"cycles,omitempty"`
+
+	// Order lists the vertices with their dependencies first, as returned
+	// by Order and Walk, and Generations groups the vertices which can run
+	// at the same time.
+	Order       []string   `json:"order,omitempty"`
+	Generations [][]string `json:"generations,omitempty"`
+
+	// Errors holds the error of each vertex which failed in Walk.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Build decodes a graph from its JSON form.
+func Build(data []byte) (*dagg.AcyclicGraph[Vertex], error) {
+	var spec Graph
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+
+	g := &dagg.AcyclicGraph[Vertex]{}
+	for _, v := range spec.Vertices {
+		g.Add(Vertex(v))
+	}
+	for _, e := range spec.Edges {
+		if err := g.Connect(dagg.BasicEdge(Vertex(e.Source), Vertex(e.Target))); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Validate checks that the graph is a valid DAG, returning its cycles if
+// it isn't.
+func Validate(data []byte) []byte {
+	g, err := Build(data)
+	if err != nil {
+		return encode(&Result{Error: err.Error()})
+	}
+	return encode(validate(g))
+}
+
+func validate(g *dagg.AcyclicGraph[Vertex]) *Result {
+	err := g.Validate()
+	if err == nil {
+		return &Result{}
+	}
+
+	r := &Result{Error: err.Error()}
+	var verr *dagg.ValidationError[Vertex]
+	if errors.As(err, &verr) {
+		for _, cycle := range verr.Cycles() {
+			r.Cycles = append(r.Cycles, names(cycle))
+		}
+	}
+	return r
+}
+
+// Order returns the vertices of the graph with their dependencies first,
+// in the same order on every call, or the cycles which prevent it.
+func Order(data []byte) []byte {
+	g, err := Build(data)
+	if err != nil {
+		return encode(&Result{Error: err.Error()})
+	}
+	if r := validate(g); r.Error != "" {
+		return encode(r)
+	}
+	return encode(order(g))
+}
+
+func order(g *dagg.AcyclicGraph[Vertex]) *Result {
+	generations, err := g.TopologicalGenerations()
+	if err != nil {
+		return &Result{Error: err.Error()}
+	}
+
+	r := &Result{}
+	for _, gen := range generations {
+		r.Order = append(r.Order, names(gen)...)
+		r.Generations = append(r.Generations, names(gen))
+	}
+	return r
+}
+
+// Walk walks the graph, calling cb with the name of each vertex once its
+// dependencies have succeeded. Vertices are walked in parallel where they
+// can be, so cb must be safe to call concurrently. The result lists the
+// vertices in the order they were called, and the error of each which
+// failed.
+func Walk(data []byte, cb func(name string) error) []byte {
+	g, err := Build(data)
+	if err != nil {
+		return encode(&Result{Error: err.Error()})
+	}
+	if r := validate(g); r.Error != "" {
+		return encode(r)
+	}
+
+	var lock sync.Mutex
+	r := &Result{}
+	err = g.Walk(func(v Vertex) error {
+		lock.Lock()
+		r.Order = append(r.Order, string(v))
+		lock.Unlock()
+
+		err := cb(string(v))
+		if err != nil {
+			lock.Lock()
+			if r.Errors == nil {
+				r.Errors = make(map[string]string)
+			}
+			r.Errors[string(v)] = err.Error()
+			lock.Unlock()
+		}
+		return err
+	})
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return encode(r)
+}
+
+// names returns the names of the vertices.
+func names(vs []Vertex) []string {
+	result := make([]string, len(vs))
+	for i, v := range vs {
+		result[i] = string(v)
+	}
+	return result
+}
+
+func encode(r *Result) []byte {
+	data, err := json.Marshal(r)
+	if err != nil {
+		// a Result only holds strings, so this can't happen
+		panic(fmt.Sprintf("encoding result: %s", err))
+	}
+	return data
+}
@@ -0,0 +1,86 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const testGraph = `{
+	"vertices": ["a", "b", "c", "d"],
+	"edges": [
+		{"source": "b", "target": "a"},
+		{"source": "c", "target": "a"},
+		{"source": "d", "target": "b"},
+		{"source": "d", "target": "c"}
+	]
+}`
+
+const testCyclicGraph = `{
+	"edges": [
+		{"source": "a", "target": "b"},
+		{"source": "b", "target": "a"}
+	]
+}`
+
+func decode(t *testing.T, data []byte) *Result {
+	var r Result
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return &r
+}
+
+func TestValidate(t *testing.T) {
+	if r := decode(t, Validate([]byte(testGraph))); r.Error != "" {
+		t.Fatalf("bad: %#v", r)
+	}
+
+	r := decode(t, Validate([]byte(testCyclicGraph)))
+	if r.Error == "" || len(r.Cycles) != 1 || len(r.Cycles[0]) != 2 {
+		t.Fatalf("bad: %#v", r)
+	}
+
+	if r := decode(t, Validate([]byte(`{"vertices": 1}`))); r.Error == "" {
+		t.Fatalf("bad: %#v", r)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	r := decode(t, Order([]byte(testGraph)))
+	if r.Error != "" {
+		t.Fatalf("err: %s", r.Error)
+	}
+	if !reflect.DeepEqual(r.Order, []string{"a", "b", "c", "d"}) {
+		t.Fatalf("bad: %#v", r.Order)
+	}
+	expected := [][]string{{"a"}, {"b", "c"}, {"d"}}
+	if !reflect.DeepEqual(r.Generations, expected) {
+		t.Fatalf("bad: %#v", r.Generations)
+	}
+
+	if r := decode(t, Order([]byte(testCyclicGraph))); r.Error == "" || len(r.Cycles) != 1 {
+		t.Fatalf("bad: %#v", r)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	r := decode(t, Walk([]byte(testGraph), func(name string) error {
+		if name == "b" {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	}))
+	if r.Error == "" {
+		t.Fatal("expect error")
+	}
+	if !reflect.DeepEqual(r.Errors, map[string]string{"b": "failed"}) {
+		t.Fatalf("bad: %#v", r.Errors)
+	}
+
+	// d depends on b, so it is never called
+	if len(r.Order) != 3 || r.Order[0] != "a" {
+		t.Fatalf("bad: %#v", r.Order)
+	}
+}
@@ -0,0 +1,237 @@
+package dagg
+
+import "fmt"
+
+// KeyedGraph is a directed graph whose vertices are stored under a
+// comparable key, as returned by a key function, rather than under a
+// Hashcode string. This avoids formatting a string for every vertex and edge
+// lookup, which dominates the cost of Graph in hot paths. Edges carry no
+// data, and are identified by the keys of their ends.
+//
+// As with Graph, the source of an edge depends on its target. A KeyedGraph
+// must be created with NewKeyedGraph.
+type KeyedGraph[K comparable, T any] struct {
+	key      func(T) K
+	vertices map[K]T
+	down, up map[K]map[K]struct{}
+	edges    int
+}
+
+// NewKeyedGraph returns an empty KeyedGraph which stores each vertex under
+// key(v).
+func NewKeyedGraph[K comparable, T any](key func(T) K) *KeyedGraph[K, T] {
+	return &KeyedGraph[K, T]{
+		key:      key,
+		vertices: make(map[K]T),
+		down:     make(map[K]map[K]struct{}),
+		up:       make(map[K]map[K]struct{}),
+	}
+}
+
+// Key returns the key of a vertex.
+func (g *KeyedGraph[K, T]) Key(v T) K {
+	return g.key(v)
+}
+
+// Add adds a vertex to the graph, replacing any vertex with the same key.
+func (g *KeyedGraph[K, T]) Add(v T) T {
+	g.vertices[g.key(v)] = v
+	return v
+}
+
+// Remove removes a vertex from the graph, along with its edges.
+func (g *KeyedGraph[K, T]) Remove(v T) {
+	k := g.key(v)
+	delete(g.vertices, k)
+	for t := range g.down[k] {
+		g.removeEdge(k, t)
+	}
+	for s := range g.up[k] {
+		g.removeEdge(s, k)
+	}
+}
+
+// HasVertex checks if the graph has a vertex with the key of v.
+func (g *KeyedGraph[K, T]) HasVertex(v T) bool {
+	_, ok := g.vertices[g.key(v)]
+	return ok
+}
+
+// Vertex returns the vertex stored under k.
+func (g *KeyedGraph[K, T]) Vertex(k K) (T, bool) {
+	v, ok := g.vertices[k]
+	return v, ok
+}
+
+// Vertices returns the vertices of the graph, in no particular order.
+func (g *KeyedGraph[K, T]) Vertices() []T {
+	result := make([]T, 0, len(g.vertices))
+	for _, v := range g.vertices {
+		result = append(result, v)
+	}
+	return result
+}
+
+// Len returns the number of vertices in the graph.
+func (g *KeyedGraph[K, T]) Len() int {
+	return len(g.vertices)
+}
+
+// EdgeCount returns the number of edges in the graph.
+func (g *KeyedGraph[K, T]) EdgeCount() int {
+	return g.edges
+}
+
+// Connect adds an edge from source to target. This is safe to call multiple
+// times with the same vertices.
+func (g *KeyedGraph[K, T]) Connect(source, target T) {
+	s, t := g.key(source), g.key(target)
+	if _, ok := g.down[s][t]; ok {
+		return
+	}
+
+	if g.down[s] == nil {
+		g.down[s] = make(map[K]struct{})
+	}
+	if g.up[t] == nil {
+		g.up[t] = make(map[K]struct{})
+	}
+	g.down[s][t] = struct{}{}
+	g.up[t][s] = struct{}{}
+	g.edges++
+}
+
+// RemoveEdge removes the edge from source to target, if there is one.
+func (g *KeyedGraph[K, T]) RemoveEdge(source, target T) {
+	g.removeEdge(g.key(source), g.key(target))
+}
+
+func (g *KeyedGraph[K, T]) removeEdge(s, t K) {
+	if _, ok := g.down[s][t]; !ok {
+		return
+	}
+	delete(g.down[s], t)
+	delete(g.up[t], s)
+	g.edges--
+}
+
+// HasEdge checks if the graph has an edge from source to target.
+func (g *KeyedGraph[K, T]) HasEdge(source, target T) bool {
+	_, ok := g.down[g.key(source)][g.key(target)]
+	return ok
+}
+
+// DownEdges returns the vertices in the graph which are targets of edges
+// from v.
+func (g *KeyedGraph[K, T]) DownEdges(v T) []T {
+	return g.lookup(g.down[g.key(v)])
+}
+
+// UpEdges returns the vertices in the graph which are sources of edges to v.
+func (g *KeyedGraph[K, T]) UpEdges(v T) []T {
+	return g.lookup(g.up[g.key(v)])
+}
+
+// lookup returns the vertices in the graph with the given keys.
+func (g *KeyedGraph[K, T]) lookup(keys map[K]struct{}) []T {
+	result := make([]T, 0, len(keys))
+	for k := range keys {
+		if v, ok := g.vertices[k]; ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Roots returns the vertices which no other vertex in the graph depends on.
+func (g *KeyedGraph[K, T]) Roots() []T {
+	var result []T
+	for k, v := range g.vertices {
+		if len(g.lookup(g.up[k])) == 0 {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Descendents returns the vertices reachable by following edges down from
+// v, not including v itself unless it is part of a cycle.
+//
+// Complexity: O(V+E)
+func (g *KeyedGraph[K, T]) Descendents(v T) []T {
+	return g.reach(v, g.down)
+}
+
+// Ancestors returns the vertices which can reach v by following edges down,
+// not including v itself unless it is part of a cycle.
+//
+// Complexity: O(V+E)
+func (g *KeyedGraph[K, T]) Ancestors(v T) []T {
+	return g.reach(v, g.up)
+}
+
+func (g *KeyedGraph[K, T]) reach(v T, next map[K]map[K]struct{}) []T {
+	seen := make(map[K]struct{})
+	var result []T
+	frontier := []K{g.key(v)}
+	for len(frontier) > 0 {
+		n := len(frontier)
+		current := frontier[n-1]
+		frontier = frontier[:n-1]
+
+		for k := range next[current] {
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			if v, ok := g.vertices[k]; ok {
+				result = append(result, v)
+				frontier = append(frontier, k)
+			}
+		}
+	}
+	return result
+}
+
+// TopologicalOrder returns the vertices of the graph ordered so that every
+// vertex comes before the targets of its edges, or an error if the graph
+// contains a cycle. Edges to vertices which are not in the graph are
+// ignored.
+//
+// Complexity: O(V+E)
+func (g *KeyedGraph[K, T]) TopologicalOrder() ([]T, error) {
+	inDegree := make(map[K]int, len(g.vertices))
+	order := make([]K, 0, len(g.vertices))
+	for k := range g.vertices {
+		for s := range g.up[k] {
+			if _, ok := g.vertices[s]; ok {
+				inDegree[k]++
+			}
+		}
+		if inDegree[k] == 0 {
+			order = append(order, k)
+		}
+	}
+
+	// order doubles as the queue
+	for next := 0; next < len(order); next++ {
+		for t := range g.down[order[next]] {
+			if _, ok := g.vertices[t]; !ok {
+				continue
+			}
+			inDegree[t]--
+			if inDegree[t] == 0 {
+				order = append(order, t)
+			}
+		}
+	}
+	if len(order) != len(g.vertices) {
+		return nil, fmt.Errorf("graph contains a cycle")
+	}
+
+	result := make([]T, len(order))
+	for i, k := range order {
+		result[i] = g.vertices[k]
+	}
+	return result, nil
+}
@@ -0,0 +1,101 @@
+package dagg
+
+import (
+	"sort"
+	"testing"
+)
+
+type keyedJob struct {
+	ID   int
+	Name string
+}
+
+func keyedJobID(j *keyedJob) int { return j.ID }
+
+func keyedNames(jobs []*keyedJob) []string {
+	names := make([]string, len(jobs))
+	for i, j := range jobs {
+		names[i] = j.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestKeyedGraph(t *testing.T) {
+	g := NewKeyedGraph(keyedJobID)
+	a := g.Add(&keyedJob{1, "a"})
+	b := g.Add(&keyedJob{2, "b"})
+	c := g.Add(&keyedJob{3, "c"})
+	g.Connect(a, b)
+	g.Connect(b, c)
+	g.Connect(a, c)
+	g.Connect(a, c)
+
+	if g.Len() != 3 || g.EdgeCount() != 3 {
+		t.Fatalf("bad: %d %d", g.Len(), g.EdgeCount())
+	}
+	if !g.HasEdge(a, c) || g.HasEdge(c, a) {
+		t.Fatal("bad edges")
+	}
+
+	// vertices are looked up by key, not identity
+	if !g.HasVertex(&keyedJob{ID: 2}) {
+		t.Fatal("should have vertex 2")
+	}
+	if v, ok := g.Vertex(2); !ok || v != b {
+		t.Fatalf("bad: %v", v)
+	}
+
+	if actual := keyedNames(g.Descendents(a)); len(actual) != 2 || actual[0] != "b" || actual[1] != "c" {
+		t.Fatalf("bad: %v", actual)
+	}
+	if actual := keyedNames(g.Ancestors(c)); len(actual) != 2 || actual[0] != "a" || actual[1] != "b" {
+		t.Fatalf("bad: %v", actual)
+	}
+	if actual := keyedNames(g.Roots()); len(actual) != 1 || actual[0] != "a" {
+		t.Fatalf("bad: %v", actual)
+	}
+
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if order[0] != a || order[1] != b || order[2] != c {
+		t.Fatalf("bad: %v", order)
+	}
+
+	g.Remove(b)
+	if g.Len() != 2 || g.EdgeCount() != 1 {
+		t.Fatalf("bad: %d %d", g.Len(), g.EdgeCount())
+	}
+	if actual := g.DownEdges(a); len(actual) != 1 || actual[0] != c {
+		t.Fatalf("bad: %v", actual)
+	}
+	if actual := g.UpEdges(c); len(actual) != 1 || actual[0] != a {
+		t.Fatalf("bad: %v", actual)
+	}
+
+	g.Connect(c, a)
+	if _, err := g.TopologicalOrder(); err == nil {
+		t.Fatal("should error on a cycle")
+	}
+	g.RemoveEdge(c, a)
+	if _, err := g.TopologicalOrder(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func BenchmarkKeyedGraph(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		g := NewKeyedGraph(func(v int) int { return v })
+		for v := 0; v < 1000; v++ {
+			g.Add(v)
+			if v > 0 {
+				g.Connect(v, v-1)
+			}
+		}
+		if _, err := g.TopologicalOrder(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package dagg
+
+import (
+	"fmt"
+	"time"
+)
+
+// LatencyEdge can be implemented by an Edge which takes time to traverse,
+// such as the transfer of data between two pipeline stages.
+type LatencyEdge interface {
+	Latency() time.Duration
+}
+
+// edgeLatency returns the latency of the edge from source to target. This
+// is either the Latency of a LatencyEdge, or the data of a LabeledEdge
+// holding a time.Duration. All other edges have no latency.
+func (g *Graph[T]) edgeLatency(source, target T) time.Duration {
+	var raw interface{}
+	raw = g.edges[directedKey(source, target)]
+	if le, ok := raw.(LatencyEdge); ok {
+		return le.Latency()
+	}
+
+	if data, ok := g.EdgeData(source, target); ok {
+		if d, ok := data.(time.Duration); ok {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// EstimateLatency returns the longest time it can take to get from the start
+// of vertex from to the end of vertex to, following the direction of the
+// edges. This is the sum of the durations of the vertices and the latencies
+// of the edges along the slowest path. An error is returned if to can't be
+// reached from from, or if the graph contains a cycle.
+//
+// The source of each edge is taken to run before its target, as in a
+// pipeline. In a graph where the source of an edge depends on its target,
+// as walked by a Walker with Reverse set, from is the vertex which runs
+// last and to the one which runs first.
+//
+// Complexity: O(V+E)
+func (g *AcyclicGraph[T]) EstimateLatency(from, to T, duration func(T) time.Duration) (time.Duration, error) {
+	if !g.HasVertex(from) {
+		return 0, fmt.Errorf("vertex %q not found", VertexName(from))
+	}
+
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return 0, err
+	}
+
+	finish := map[string]time.Duration{
+		from.Hashcode(): duration(from),
+	}
+	g.longestPaths(order, finish, duration)
+
+	d, ok := finish[to.Hashcode()]
+	if !ok {
+		return 0, fmt.Errorf("%q is not reachable from %q", VertexName(to), VertexName(from))
+	}
+	return d, nil
+}
+
+// EstimateMakespan returns the time it takes to run the entire graph
+// assuming unlimited parallelism: the length of the slowest path through
+// the graph, including vertex durations and edge latencies. The slowest
+// path is as long whichever way its edges are followed, so the result is
+// the same whether the source of an edge runs before or after its target.
+// Edges from vertices which aren't in the graph are ignored.
+//
+// Complexity: O(V+E)
+func (g *AcyclicGraph[T]) EstimateMakespan(duration func(T) time.Duration) (time.Duration, error) {
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return 0, err
+	}
+
+	// a vertex starts the slowest path if no vertex of the graph has an
+	// edge to it, so a dangling source doesn't stop it from starting
+	finish := make(map[string]time.Duration, len(order))
+	for _, v := range order {
+		first := true
+		for _, u := range g.upEdgesNoCopy(v) {
+			if g.HasVertex(u) {
+				first = false
+				break
+			}
+		}
+		if first {
+			finish[v.Hashcode()] = duration(v)
+		}
+	}
+	g.longestPaths(order, finish, duration)
+
+	var makespan time.Duration
+	for _, d := range finish {
+		if d > makespan {
+			makespan = d
+		}
+	}
+	return makespan, nil
+}
+
+// longestPaths extends the finish times of the started vertices to every
+// vertex reachable from them. The vertices must be given in topological
+// order.
+func (g *AcyclicGraph[T]) longestPaths(order []T, finish map[string]time.Duration, duration func(T) time.Duration) {
+	for _, u := range order {
+		start, ok := finish[u.Hashcode()]
+		if !ok {
+			continue
+		}
+
+		for _, v := range g.downEdgesNoCopy(u) {
+			if !g.HasVertex(v) {
+				continue
+			}
+
+			d := start + g.edgeLatency(u, v) + duration(v)
+			if current, ok := finish[v.Hashcode()]; !ok || d > current {
+				finish[v.Hashcode()] = d
+			}
+		}
+	}
+}
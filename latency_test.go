@@ -0,0 +1,88 @@
+package dagg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcyclicGraphEstimateLatency(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Add(myint(5))
+	g.Connect(LabeledEdge(myint(1), myint(2), 10*time.Millisecond))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(4)))
+	g.Connect(&testLatencyEdge{BasicEdge(myint(3), myint(4)), 100 * time.Millisecond})
+
+	// every vertex takes its value in milliseconds
+	duration := func(v myint) time.Duration {
+		return time.Duration(v) * time.Millisecond
+	}
+
+	// 1 + 100 + 3 + 4 via vertex 3
+	actual, err := g.EstimateLatency(myint(1), myint(4), duration)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual != 108*time.Millisecond {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// 2 + 4
+	actual, err = g.EstimateLatency(myint(2), myint(4), duration)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual != 6*time.Millisecond {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	if _, err := g.EstimateLatency(myint(4), myint(1), duration); err == nil {
+		t.Fatal("should error when unreachable")
+	}
+
+	actual, err = g.EstimateMakespan(duration)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual != 108*time.Millisecond {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	g.Connect(BasicEdge(myint(4), myint(1)))
+	if _, err := g.EstimateMakespan(duration); err == nil {
+		t.Fatal("should error on cycle")
+	}
+}
+
+func TestAcyclicGraphEstimateMakespan_dangling(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.SetVertexPolicy(AllowDanglingEdges)
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(9), myint(1)))
+
+	duration := func(v myint) time.Duration {
+		return time.Duration(v) * time.Millisecond
+	}
+
+	// 1 starts the path though the missing 9 has an edge to it
+	actual, err := g.EstimateMakespan(duration)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual != 3*time.Millisecond {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+type testLatencyEdge struct {
+	Edge[myint]
+	latency time.Duration
+}
+
+func (e *testLatencyEdge) Latency() time.Duration { return e.latency }
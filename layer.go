@@ -0,0 +1,44 @@
+package dagg
+
+import (
+	"fmt"
+)
+
+// LayeredVertex can be implemented by a vertex to place it in an
+// architectural layer. Edges may only point from a vertex to another vertex
+// in the same or a lower layer, so a layer can never depend on the layers
+// above it. Vertices which don't implement LayeredVertex are unconstrained.
+type LayeredVertex interface {
+	Layer() int
+}
+
+// CheckLayering returns an error if the edge points from a vertex to a
+// vertex in a higher layer.
+func CheckLayering[T Hashable](e Edge[T]) error {
+	var src, tgt interface{}
+	src, tgt = e.Source(), e.Target()
+
+	s, ok := src.(LayeredVertex)
+	if !ok {
+		return nil
+	}
+	t, ok := tgt.(LayeredVertex)
+	if !ok {
+		return nil
+	}
+
+	if s.Layer() < t.Layer() {
+		return fmt.Errorf("Layer violation: %s (layer %d) -> %s (layer %d)",
+			VertexName(e.Source()), s.Layer(), VertexName(e.Target()), t.Layer())
+	}
+	return nil
+}
+
+// ConnectLayered is like Connect, but returns an error instead of adding
+// the edge if it violates the layering of the vertices.
+func (g *Graph[T]) ConnectLayered(e Edge[T]) error {
+	if err := CheckLayering(e); err != nil {
+		return err
+	}
+	return g.Connect(e)
+}
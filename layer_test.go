@@ -0,0 +1,72 @@
+package dagg
+
+import (
+	"testing"
+)
+
+func TestCheckLayering(t *testing.T) {
+	ui := &testLayerVertex{"ui", 2}
+	api := &testLayerVertex{"api", 1}
+	db := &testLayerVertex{"db", 0}
+	otherAPI := &testLayerVertex{"other-api", 1}
+
+	var g AcyclicGraph[*testLayerVertex]
+	g.Add(ui)
+	g.Add(api)
+	g.Add(db)
+	g.Add(otherAPI)
+
+	for _, e := range []Edge[*testLayerVertex]{
+		BasicEdge(ui, api),
+		BasicEdge(api, db),
+		BasicEdge(api, otherAPI),
+	} {
+		if err := g.ConnectLayered(e); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	if err := g.ConnectLayered(BasicEdge(db, api)); err == nil {
+		t.Fatal("should error")
+	}
+	if g.HasEdge(BasicEdge(db, api)) {
+		t.Fatal("edge should not be added")
+	}
+
+	if err := g.ValidateWithOpts(&ValidateOpts{Layering: true}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cache := g.Add(&testLayerVertex{"cache", 0})
+	g.Connect(BasicEdge(cache, ui))
+	if err := g.Validate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := g.ValidateWithOpts(&ValidateOpts{Layering: true}); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestGraphConnectLayered_strictVertices(t *testing.T) {
+	api := &testLayerVertex{"api", 1}
+	db := &testLayerVertex{"db", 0}
+
+	var g Graph[*testLayerVertex]
+	g.SetVertexPolicy(StrictVertices)
+	g.Add(api)
+
+	if err := g.ConnectLayered(BasicEdge(api, db)); err == nil {
+		t.Fatal("should error")
+	}
+	if g.HasEdge(BasicEdge(api, db)) {
+		t.Fatal("edge should not be added")
+	}
+}
+
+type testLayerVertex struct {
+	name  string
+	layer int
+}
+
+func (v *testLayerVertex) Hashcode() string { return v.name }
+func (v *testLayerVertex) Layer() int       { return v.layer }
@@ -0,0 +1,101 @@
+package dagg
+
+import "sync"
+
+// PayloadStore loads vertex payloads by reference, such as a URI or a
+// database key, for LazyVertex.
+type PayloadStore[P any] interface {
+	Load(ref string) (P, error)
+}
+
+// PayloadStoreFunc is a function which implements PayloadStore.
+type PayloadStoreFunc[P any] func(ref string) (P, error)
+
+func (f PayloadStoreFunc[P]) Load(ref string) (P, error) {
+	return f(ref)
+}
+
+// LazyVertex is a vertex which holds a reference to its payload, rather
+// than the payload itself. The payload is loaded from a PayloadStore the
+// first time it is asked for, so a graph can be read and walked by its
+// topology alone without decoding every payload.
+//
+// The reference is the Hashcode and name of the vertex, and is all that is
+// written when the graph is serialized with EncodeLazy.
+type LazyVertex[P any] struct {
+	ref   string
+	store PayloadStore[P]
+
+	lock     sync.Mutex
+	hydrated bool
+	payload  P
+}
+
+// NewLazyVertex returns a vertex for the payload stored under ref.
+func NewLazyVertex[P any](ref string, store PayloadStore[P]) *LazyVertex[P] {
+	return &LazyVertex[P]{ref: ref, store: store}
+}
+
+// HydratedVertex returns a vertex for a payload which is already loaded,
+// such as one which has just been written to a store under ref.
+func HydratedVertex[P any](ref string, payload P) *LazyVertex[P] {
+	return &LazyVertex[P]{ref: ref, hydrated: true, payload: payload}
+}
+
+func (v *LazyVertex[P]) Hashcode() string { return v.ref }
+func (v *LazyVertex[P]) Name() string     { return v.ref }
+
+// Ref returns the reference to the payload.
+func (v *LazyVertex[P]) Ref() string { return v.ref }
+
+// Payload returns the payload of the vertex, loading it from the store the
+// first time it is called. The payload is kept once it has been loaded, but
+// an error is not, so a failed load is tried again by the next call.
+// Payload is safe to call concurrently, as from a walk.
+func (v *LazyVertex[P]) Payload() (P, error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.hydrated {
+		return v.payload, nil
+	}
+
+	p, err := v.store.Load(v.ref)
+	if err != nil {
+		var zero P
+		return zero, err
+	}
+	v.payload, v.hydrated = p, true
+	return p, nil
+}
+
+// Hydrated returns true if the payload has been loaded.
+func (v *LazyVertex[P]) Hydrated() bool {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return v.hydrated
+}
+
+// EncodeLazy encodes a LazyVertex as its reference, for the encode
+// functions of PersistOpts and the store package.
+func EncodeLazy[P any](v *LazyVertex[P]) (string, error) {
+	return v.ref, nil
+}
+
+// LazyDecoder returns a decode function which turns a reference back into a
+// LazyVertex without loading its payload, for ReadFrom, ReplayLog, ParseDot
+// and the store package. Each reference is decoded to the same vertex, so
+// its payload is loaded at most once.
+func LazyDecoder[P any](store PayloadStore[P]) func(string) (*LazyVertex[P], error) {
+	var lock sync.Mutex
+	vertices := make(map[string]*LazyVertex[P])
+	return func(ref string) (*LazyVertex[P], error) {
+		lock.Lock()
+		defer lock.Unlock()
+		v, ok := vertices[ref]
+		if !ok {
+			v = NewLazyVertex(ref, store)
+			vertices[ref] = v
+		}
+		return v, nil
+	}
+}
@@ -0,0 +1,76 @@
+package dagg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLazyVertex(t *testing.T) {
+	payloads := map[string]int{"a": 1, "b": 2}
+	var loads []string
+	store := PayloadStoreFunc[int](func(ref string) (int, error) {
+		loads = append(loads, ref)
+		p, ok := payloads[ref]
+		if !ok {
+			return 0, fmt.Errorf("%s not found", ref)
+		}
+		return p, nil
+	})
+
+	// write a graph by reference
+	var g AcyclicGraph[*LazyVertex[int]]
+	a := g.Add(HydratedVertex("a", 1))
+	b := g.Add(HydratedVertex("b", 2))
+	g.Connect(BasicEdge(a, b))
+
+	var buf bytes.Buffer
+	if _, err := g.WriteTo(&buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// reading it back, and walking the topology, loads nothing
+	read, err := ReadFrom(&buf, LazyDecoder[int](store))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := strings.TrimSpace(read.String()); actual != "a\n  b\nb" {
+		t.Fatalf("bad: %s", actual)
+	}
+	if len(loads) != 0 {
+		t.Fatalf("bad: %v", loads)
+	}
+
+	// the payload is loaded once, on first access
+	for _, v := range read.Vertices() {
+		if v.Ref() != "b" {
+			continue
+		}
+		if v.Hydrated() {
+			t.Fatal("should not be hydrated yet")
+		}
+		for i := 0; i < 2; i++ {
+			if p, err := v.Payload(); err != nil || p != 2 {
+				t.Fatalf("bad: %d %v", p, err)
+			}
+		}
+		if !v.Hydrated() {
+			t.Fatal("should be hydrated")
+		}
+	}
+	if strings.Join(loads, ",") != "b" {
+		t.Fatalf("bad: %v", loads)
+	}
+
+	// a failed load is tried again
+	missing := NewLazyVertex[int]("missing", store)
+	for i := 0; i < 2; i++ {
+		if _, err := missing.Payload(); err == nil {
+			t.Fatal("should error")
+		}
+	}
+	if strings.Join(loads, ",") != "b,missing,missing" {
+		t.Fatalf("bad: %v", loads)
+	}
+}
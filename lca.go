@@ -0,0 +1,111 @@
+package dagg
+
+import (
+	"fmt"
+)
+
+// LCA returns the lowest common ancestors of a and b, sorted by name. A
+// common ancestor is a vertex with a path to both a and b, where every
+// vertex is considered an ancestor of itself. The lowest common ancestors
+// are those which aren't an ancestor of any other common ancestor. In a DAG
+// there may be more than one, or none.
+//
+// Use an LCAIndex when making repeated queries on an unchanging graph.
+//
+// Complexity: O(V+E)
+func (g *AcyclicGraph[T]) LCA(a, b T) ([]T, error) {
+	ancestors := func(v T) (Set[T], error) {
+		if !g.HasVertex(v) {
+			return nil, fmt.Errorf("vertex %q not found", VertexName(v))
+		}
+		s, err := g.Ancestors(v)
+		if err != nil {
+			return nil, err
+		}
+		s.Add(v)
+		return s, nil
+	}
+
+	ancA, err := ancestors(a)
+	if err != nil {
+		return nil, err
+	}
+	ancB, err := ancestors(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.lowest(ancA.Intersection(ancB)), nil
+}
+
+// lowest returns the members of common which have no edges to another
+// member, sorted by name. When common is closed under ancestry, these are
+// the members which aren't an ancestor of any other member, since the path
+// from one member to another only passes through members.
+func (g *AcyclicGraph[T]) lowest(common Set[T]) []T {
+	var result []T
+	for _, c := range common {
+		lowest := true
+		for _, t := range g.downEdgesNoCopy(c) {
+			if common.Include(t) {
+				lowest = false
+				break
+			}
+		}
+		if lowest {
+			result = append(result, c)
+		}
+	}
+	g.sortByName(result)
+	return result
+}
+
+// LCAIndex answers lowest common ancestor queries on a graph without
+// walking it for each query, by precomputing the ancestors of every vertex.
+// The index is not updated when the graph is modified.
+type LCAIndex[T Hashable] struct {
+	g         *AcyclicGraph[T]
+	ancestors map[string]Set[T]
+}
+
+// NewLCAIndex builds an LCAIndex of the graph, which must not contain
+// cycles.
+//
+// Complexity: O(V*(V+E)) time, O(V^2) memory
+func (g *AcyclicGraph[T]) NewLCAIndex() (*LCAIndex[T], error) {
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &LCAIndex[T]{
+		g:         g,
+		ancestors: make(map[string]Set[T], len(order)),
+	}
+	for _, v := range order {
+		s := make(Set[T])
+		s.Add(v)
+		for _, u := range g.upEdgesNoCopy(v) {
+			for k, a := range idx.ancestors[u.Hashcode()] {
+				s[k] = a
+			}
+		}
+		idx.ancestors[v.Hashcode()] = s
+	}
+	return idx, nil
+}
+
+// LCA returns the lowest common ancestors of a and b, as
+// AcyclicGraph.LCA does.
+func (idx *LCAIndex[T]) LCA(a, b T) ([]T, error) {
+	ancA, ok := idx.ancestors[a.Hashcode()]
+	if !ok {
+		return nil, fmt.Errorf("vertex %q not found", VertexName(a))
+	}
+	ancB, ok := idx.ancestors[b.Hashcode()]
+	if !ok {
+		return nil, fmt.Errorf("vertex %q not found", VertexName(b))
+	}
+
+	return idx.g.lowest(ancA.Intersection(ancB)), nil
+}
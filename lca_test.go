@@ -0,0 +1,62 @@
+package dagg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAcyclicGraphLCA(t *testing.T) {
+	// 1 and 2 are both common ancestors of 5 and 6, and neither is an
+	// ancestor of the other.
+	var g AcyclicGraph[myint]
+	for i := 0; i <= 7; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(0), myint(1)))
+	g.Connect(BasicEdge(myint(0), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(5)))
+	g.Connect(BasicEdge(myint(1), myint(6)))
+	g.Connect(BasicEdge(myint(2), myint(5)))
+	g.Connect(BasicEdge(myint(2), myint(6)))
+	g.Connect(BasicEdge(myint(5), myint(3)))
+
+	idx, err := g.NewLCAIndex()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cases := []struct {
+		a, b     myint
+		expected []myint
+	}{
+		{5, 6, []myint{1, 2}},
+		{3, 6, []myint{1, 2}},
+		{3, 5, []myint{5}},
+		{1, 2, []myint{0}},
+		{4, 5, nil},
+	}
+	for _, tc := range cases {
+		actual, err := g.LCA(tc.a, tc.b)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Fatalf("bad LCA(%d, %d): %#v", tc.a, tc.b, actual)
+		}
+
+		actual, err = idx.LCA(tc.a, tc.b)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Fatalf("bad index LCA(%d, %d): %#v", tc.a, tc.b, actual)
+		}
+	}
+
+	if _, err := g.LCA(myint(1), myint(8)); err == nil {
+		t.Fatal("should error on missing vertex")
+	}
+	if _, err := idx.LCA(myint(8), myint(1)); err == nil {
+		t.Fatal("should error on missing vertex")
+	}
+}
@@ -0,0 +1,201 @@
+package dagg
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// The OpenLineage run event types.
+const (
+	LineageStart    = "START"
+	LineageComplete = "COMPLETE"
+	LineageFail     = "FAIL"
+	LineageAbort    = "ABORT"
+)
+
+// LineageSchemaURL is the OpenLineage schema of the events written by
+// WriteLineage.
+const LineageSchemaURL = "https://openlineage.io/spec/2-0-2/OpenLineage.json#/definitions/RunEvent"
+
+// DatasetVertex is implemented by the vertices of a graph which are
+// datasets, such as tables or files. Every other vertex is a job.
+type DatasetVertex interface {
+	Dataset() (namespace, name string)
+}
+
+// LineageOpts are the options for exporting a graph as OpenLineage events.
+type LineageOpts struct {
+	// Namespace is the namespace of the jobs, "default" if it is empty.
+	Namespace string
+
+	// Producer is the URI identifying the producer of the events. It
+	// defaults to the URI of this package.
+	Producer string
+
+	// EventType is the type of every event, LineageComplete if it is
+	// empty.
+	EventType string
+
+	// EventTime is the time of every event, the current time if it is zero.
+	EventTime time.Time
+
+	// RunID returns the run ID of a job, which must be a UUID. A random
+	// UUID is used for each job if it is nil.
+	RunID func(job interface{}) string
+}
+
+// LineageEvent is an OpenLineage RunEvent for a single job.
+type LineageEvent struct {
+	EventType string           `json:"eventType"`
+	EventTime string           `json:"eventTime"`
+	Run       LineageRun       `json:"run"`
+	Job       LineageJob       `json:"job"`
+	Inputs    []LineageDataset `json:"inputs"`
+	Outputs   []LineageDataset `json:"outputs"`
+	Producer  string           `json:"producer"`
+	SchemaURL string           `json:"schemaURL"`
+}
+
+// LineageRun identifies the run of a LineageEvent.
+type LineageRun struct {
+	RunID string `json:"runId"`
+}
+
+// LineageJob identifies the job of a LineageEvent.
+type LineageJob struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// LineageDataset identifies an input or output dataset of a LineageEvent.
+type LineageDataset struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// LineageEvents returns an OpenLineage event for each job in the graph,
+// ordered so that every job comes after the jobs it depends on.
+//
+// As in Walk, the source of an edge depends on its target, so the targets
+// of a job's edges which are DatasetVertex vertices are its inputs, and the
+// datasets with an edge to the job are its outputs. Edges between jobs or
+// between datasets aren't part of the events.
+func (g *AcyclicGraph[T]) LineageEvents(opts *LineageOpts) ([]LineageEvent, error) {
+	if opts == nil {
+		opts = &LineageOpts{}
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	producer := opts.Producer
+	if producer == "" {
+		producer = "https://github.com/streemtech/dagg"
+	}
+	eventType := opts.EventType
+	if eventType == "" {
+		eventType = LineageComplete
+	}
+	eventTime := opts.EventTime
+	if eventTime.IsZero() {
+		eventTime = time.Now()
+	}
+
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []LineageEvent
+	for i := len(order) - 1; i >= 0; i-- {
+		v := order[i]
+		if _, ok := lineageDataset(v); ok {
+			continue
+		}
+
+		runID, err := lineageRunID(opts, v)
+		if err != nil {
+			return nil, err
+		}
+
+		e := LineageEvent{
+			EventType: eventType,
+			EventTime: eventTime.UTC().Format(time.RFC3339Nano),
+			Run:       LineageRun{RunID: runID},
+			Job:       LineageJob{Namespace: namespace, Name: VertexName(v)},
+			Inputs:    lineageDatasets(g.downEdgesNoCopy(v)),
+			Outputs:   lineageDatasets(g.upEdgesNoCopy(v)),
+			Producer:  producer,
+			SchemaURL: LineageSchemaURL,
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// WriteLineage writes the LineageEvents of the graph to w as newline
+// delimited JSON, as accepted by the OpenLineage file transport.
+func (g *AcyclicGraph[T]) WriteLineage(w io.Writer, opts *LineageOpts) error {
+	events, err := g.LineageEvents(opts)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineageDataset returns the dataset of v, if it is a DatasetVertex.
+func lineageDataset[T Hashable](v T) (LineageDataset, bool) {
+	var raw interface{}
+	raw = v
+	d, ok := raw.(DatasetVertex)
+	if !ok {
+		return LineageDataset{}, false
+	}
+	namespace, name := d.Dataset()
+	return LineageDataset{Namespace: namespace, Name: name}, true
+}
+
+// lineageDatasets returns the sorted datasets in s.
+func lineageDatasets[T Hashable](s idSet[T]) []LineageDataset {
+	result := []LineageDataset{}
+	for _, v := range s {
+		if d, ok := lineageDataset(v); ok {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// lineageRunID returns the run ID of the job v.
+func lineageRunID(opts *LineageOpts, v interface{}) (string, error) {
+	if opts.RunID != nil {
+		return opts.RunID(v), nil
+	}
+
+	// a random version 4 UUID
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,92 @@
+package dagg
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testLineageVertex struct {
+	name string
+}
+
+func (v *testLineageVertex) Hashcode() string { return v.name }
+func (v *testLineageVertex) Name() string     { return v.name }
+
+type testDatasetVertex struct {
+	testLineageVertex
+}
+
+func (v *testDatasetVertex) Dataset() (string, string) {
+	return "postgres://db", v.name
+}
+
+func TestAcyclicGraphWriteLineage(t *testing.T) {
+	var g AcyclicGraph[Hashable]
+	raw := &testDatasetVertex{testLineageVertex{name: "raw"}}
+	clean := &testDatasetVertex{testLineageVertex{name: "clean"}}
+	report := &testDatasetVertex{testLineageVertex{name: "report"}}
+	extract := &testLineageVertex{name: "extract"}
+	summarize := &testLineageVertex{name: "summarize"}
+	for _, v := range []Hashable{raw, clean, report, extract, summarize} {
+		g.Add(v)
+	}
+
+	// extract reads raw and writes clean, summarize reads clean and writes
+	// report
+	g.Connect(BasicEdge[Hashable](extract, raw))
+	g.Connect(BasicEdge[Hashable](clean, extract))
+	g.Connect(BasicEdge[Hashable](summarize, clean))
+	g.Connect(BasicEdge[Hashable](report, summarize))
+
+	var buf bytes.Buffer
+	err := g.WriteLineage(&buf, &LineageOpts{
+		Namespace: "pipeline",
+		Producer:  "https://example.com/test",
+		EventTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		RunID: func(job interface{}) string {
+			return "run-" + VertexName(job.(Hashable))
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(buf.String())
+	expected := strings.TrimSpace(testLineageStr)
+	if actual != expected {
+		t.Fatalf("bad:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestAcyclicGraphLineageEvents_defaults(t *testing.T) {
+	var g AcyclicGraph[Hashable]
+	g.Add(&testLineageVertex{name: "job"})
+
+	events, err := g.LineageEvents(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("bad: %#v", events)
+	}
+
+	e := events[0]
+	if e.EventType != LineageComplete || e.Job.Namespace != "default" || e.Producer == "" {
+		t.Fatalf("bad: %#v", e)
+	}
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuid.MatchString(e.Run.RunID) {
+		t.Fatalf("bad run ID: %s", e.Run.RunID)
+	}
+	if e.Inputs == nil || e.Outputs == nil {
+		t.Fatal("inputs and outputs should be empty, not null")
+	}
+}
+
+const testLineageStr = `
+{"eventType":"COMPLETE","eventTime":"2020-01-02T03:04:05Z","run":{"runId":"run-extract"},"job":{"namespace":"pipeline","name":"extract"},"inputs":[{"namespace":"postgres://db","name":"raw"}],"outputs":[{"namespace":"postgres://db","name":"clean"}],"producer":"https://example.com/test","schemaURL":"https://openlineage.io/spec/2-0-2/OpenLineage.json#/definitions/RunEvent"}
+{"eventType":"COMPLETE","eventTime":"2020-01-02T03:04:05Z","run":{"runId":"run-summarize"},"job":{"namespace":"pipeline","name":"summarize"},"inputs":[{"namespace":"postgres://db","name":"clean"}],"outputs":[{"namespace":"postgres://db","name":"report"}],"producer":"https://example.com/test","schemaURL":"https://openlineage.io/spec/2-0-2/OpenLineage.json#/definitions/RunEvent"}
+`
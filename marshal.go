@@ -34,6 +34,28 @@ type marshalGraph struct {
 
 	// Any lists of vertices that are included in cycles.
 	Cycles [][]*marshalVertex `json:",omitempty"`
+
+	// less orders vertex names, as set by Graph.WithVertexLess.
+	less func(a, b string) bool
+}
+
+// nameLess compares two names with less, or lexicographically if it isn't
+// set.
+func (g *marshalGraph) nameLess(a, b string) bool {
+	if g.less != nil {
+		return g.less(a, b)
+	}
+	return a < b
+}
+
+// edgeLess orders two edges, given by the IDs of their ends, by the names of
+// their sources and then their targets, using less.
+func (g *marshalGraph) edgeLess(src1, tgt1, src2, tgt2 string) bool {
+	s1, s2 := g.vertexByID(src1).Name, g.vertexByID(src2).Name
+	if s1 != s2 {
+		return g.less(s1, s2)
+	}
+	return g.less(g.vertexByID(tgt1).Name, g.vertexByID(tgt2).Name)
 }
 
 func (g *marshalGraph) vertexByID(id string) *marshalVertex {
@@ -57,6 +79,15 @@ type marshalVertex struct {
 	// This is to help transition from the old Dot interfaces. We record if the
 	// node was a GraphNodeDotter here, so we can call it to get attributes.
 	graphNodeDotter GraphNodeDotter
+
+	// The kind and state of the vertex, used to apply a Theme.
+	kind, state string
+
+	// The annotations of the vertex, from Graph.SetVertexData.
+	data map[string]string
+
+	// The original vertex, for DotOpts.VertexAttrs.
+	raw interface{}
 }
 
 func newMarshalVertex[T Hashable](raw T) *marshalVertex {
@@ -72,12 +103,21 @@ func newMarshalVertex[T Hashable](raw T) *marshalVertex {
 	name := strconv.Quote(VertexName(raw))
 	name = name[1 : len(name)-1]
 
-	return &marshalVertex{
+	mv := &marshalVertex{
 		ID:              marshalVertexID(raw),
 		Name:            name,
 		Attrs:           make(map[string]string),
 		graphNodeDotter: dn,
+		raw:             v,
 	}
+	if k, ok := v.(KindedVertex); ok {
+		mv.kind = k.Kind()
+	}
+	if s, ok := v.(StatefulVertex); ok {
+		mv.state = s.State()
+	}
+
+	return mv
 }
 
 // vertices is a sort.Interface implementation for sorting vertices by ID
@@ -96,15 +136,36 @@ type marshalEdge struct {
 	Target string
 
 	Attrs map[string]string `json:",omitempty"`
+
+	// The original edge, for DotOpts.EdgeAttrs.
+	raw interface{}
 }
 
 func newMarshalEdge[T Hashable](e Edge[T]) *marshalEdge {
-	return &marshalEdge{
+	me := &marshalEdge{
 		Name:   fmt.Sprintf("%s|%s", VertexName(e.Source()), VertexName(e.Target())),
 		Source: marshalVertexID(e.Source()),
 		Target: marshalVertexID(e.Target()),
 		Attrs:  make(map[string]string),
+		raw:    e,
+	}
+
+	// edges may also be Named, in which case the name is used as the label.
+	// Otherwise any edge data is used.
+	var raw interface{}
+	raw = e
+	if n, ok := raw.(Named); ok {
+		me.Attrs["label"] = n.Name()
+	} else if de, ok := raw.(DataEdge); ok {
+		me.Attrs["label"] = fmt.Sprint(de.EdgeData())
 	}
+
+	// undirected edges are drawn without an arrowhead
+	if !IsDirected(e) {
+		me.Attrs["dir"] = "none"
+	}
+
+	return me
 }
 
 // edges is a sort.Interface implementation for sorting edges by Source ID
@@ -120,6 +181,7 @@ func newMarshalGraph[T Hashable](name string, g *Graph[T]) *marshalGraph {
 		Type:  "Graph",
 		Name:  name,
 		Attrs: make(map[string]string),
+		less:  g.less,
 	}
 
 	for _, v := range g.Vertices() {
@@ -131,16 +193,33 @@ func newMarshalGraph[T Hashable](name string, g *Graph[T]) *marshalGraph {
 		}
 
 		mv := newMarshalVertex(v)
+		mv.data = g.vertexDataStrings(v)
 		mg.Vertices = append(mg.Vertices, mv)
 	}
 
-	sort.Sort(vertices(mg.Vertices))
+	sort.Slice(mg.Subgraphs, func(i, j int) bool {
+		return mg.nameLess(mg.Subgraphs[i].Name, mg.Subgraphs[j].Name)
+	})
+
+	if mg.less == nil {
+		sort.Sort(vertices(mg.Vertices))
+	} else {
+		sort.Slice(mg.Vertices, func(i, j int) bool {
+			return mg.less(mg.Vertices[i].Name, mg.Vertices[j].Name)
+		})
+	}
 
 	for _, e := range g.Edges() {
 		mg.Edges = append(mg.Edges, newMarshalEdge(e))
 	}
 
-	sort.Sort(edges(mg.Edges))
+	if mg.less == nil {
+		sort.Sort(edges(mg.Edges))
+	} else {
+		sort.Slice(mg.Edges, func(i, j int) bool {
+			return mg.edgeLess(mg.Edges[i].Source, mg.Edges[i].Target, mg.Edges[j].Source, mg.Edges[j].Target)
+		})
+	}
 
 	for _, c := range (&AcyclicGraph[T]{*g}).Cycles() {
 		var cycle []*marshalVertex
@@ -169,11 +248,17 @@ func marshalSubgrapher[T Hashable](raw T) (*Graph[T], bool) {
 		return nil, false
 	}
 
-	switch g := sg.Subgraph().DirectedGraph().(type) {
+	grapher := sg.Subgraph()
+	if grapher == nil {
+		return nil, false
+	}
+	switch g := grapher.DirectedGraph().(type) {
 	case *Graph[T]:
-		return g, true
+		return g, g != nil
 	case *AcyclicGraph[T]:
-		return &g.Graph, true
+		if g != nil {
+			return &g.Graph, true
+		}
 	}
 
 	return nil, false
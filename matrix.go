@@ -0,0 +1,96 @@
+package dagg
+
+import (
+	"fmt"
+)
+
+// DenseAdjacency returns the adjacency matrix of the graph in row-major
+// order, along with the vertex for each row and column. The vertices are
+// sorted by Hashcode. The entry for row i and column j is 1 if there is an
+// edge from vertices[i] to vertices[j], and 0 otherwise. Undirected edges
+// are recorded in both directions.
+//
+// The result can be passed directly to gonum's mat.NewDense(n, n, data).
+func (g *Graph[T]) DenseAdjacency() ([]T, []float64) {
+	vertices := g.SortedVertices()
+	n := len(vertices)
+	data := make([]float64, n*n)
+	for _, ij := range g.adjacencyEntries(vertices) {
+		data[ij[0]*n+ij[1]] = 1
+	}
+	return vertices, data
+}
+
+// SparseAdjacency returns the adjacency matrix of the graph as a list of
+// (row, column) entries, along with the vertex for each row and column. See
+// DenseAdjacency for details.
+func (g *Graph[T]) SparseAdjacency() ([]T, [][2]int) {
+	vertices := g.SortedVertices()
+	return vertices, g.adjacencyEntries(vertices)
+}
+
+func (g *Graph[T]) adjacencyEntries(vertices []T) [][2]int {
+	index := make(map[string]int, len(vertices))
+	for i, v := range vertices {
+		index[v.Hashcode()] = i
+	}
+
+	entries := make([][2]int, 0, len(g.edges))
+	for _, e := range g.SortedEdges() {
+		i, ok := index[e.Source().Hashcode()]
+		if !ok {
+			continue
+		}
+		j, ok := index[e.Target().Hashcode()]
+		if !ok {
+			continue
+		}
+
+		entries = append(entries, [2]int{i, j})
+		if !IsDirected(e) && i != j {
+			entries = append(entries, [2]int{j, i})
+		}
+	}
+	return entries
+}
+
+// FromDenseAdjacency builds a graph from a row-major adjacency matrix, as
+// returned by DenseAdjacency. Every non-zero entry becomes a directed edge.
+func FromDenseAdjacency[T Hashable](vertices []T, data []float64) (*Graph[T], error) {
+	n := len(vertices)
+	if len(data) != n*n {
+		return nil, fmt.Errorf("adjacency matrix has %d entries, expected %d", len(data), n*n)
+	}
+
+	g := &Graph[T]{}
+	for _, v := range vertices {
+		g.Add(v)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if data[i*n+j] != 0 {
+				g.Connect(BasicEdge(vertices[i], vertices[j]))
+			}
+		}
+	}
+	return g, nil
+}
+
+// FromSparseAdjacency builds a graph from a list of (row, column) adjacency
+// entries, as returned by SparseAdjacency. Every entry becomes a directed
+// edge.
+func FromSparseAdjacency[T Hashable](vertices []T, entries [][2]int) (*Graph[T], error) {
+	g := &Graph[T]{}
+	for _, v := range vertices {
+		g.Add(v)
+	}
+	for _, ij := range entries {
+		for _, idx := range ij {
+			if idx < 0 || idx >= len(vertices) {
+				return nil, fmt.Errorf("adjacency entry %v out of range", ij)
+			}
+		}
+		g.Connect(BasicEdge(vertices[ij[0]], vertices[ij[1]]))
+	}
+	return g, nil
+}
@@ -0,0 +1,84 @@
+package dagg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGraphDenseAdjacency(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(UndirectedEdge(myint(2), myint(3)))
+
+	vertices, data := g.DenseAdjacency()
+	if !reflect.DeepEqual(vertices, []myint{1, 2, 3}) {
+		t.Fatalf("bad vertices: %#v", vertices)
+	}
+
+	expected := []float64{
+		0, 1, 1,
+		0, 0, 1,
+		0, 1, 0,
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("bad matrix: %#v", data)
+	}
+
+	if _, err := FromDenseAdjacency(vertices, data[1:]); err == nil {
+		t.Fatal("should error on wrong size")
+	}
+
+	imported, err := FromDenseAdjacency(vertices, data)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	actual := strings.TrimSpace(imported.String())
+	if actual != strings.TrimSpace(testGraphAdjacencyStr) {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestGraphSparseAdjacency(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(2)))
+
+	vertices, entries := g.SparseAdjacency()
+	expected := [][2]int{{0, 1}, {0, 2}, {1, 2}, {2, 1}}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("bad entries: %#v", entries)
+	}
+
+	if _, err := FromSparseAdjacency(vertices, [][2]int{{0, 3}}); err == nil {
+		t.Fatal("should error on out of range entry")
+	}
+
+	imported, err := FromSparseAdjacency(vertices, entries)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	actual := strings.TrimSpace(imported.String())
+	if actual != strings.TrimSpace(testGraphAdjacencyStr) {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+const testGraphAdjacencyStr = `
+1
+  2
+  3
+2
+  3
+3
+  2
+`
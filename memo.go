@@ -0,0 +1,111 @@
+package dagg
+
+import (
+	"sync"
+)
+
+// ContentHasher can be implemented by a vertex to identify the work it
+// does, for a ResultCache. Vertices with the same ContentHash are assumed
+// to do the same work, even if they are in different graphs or have
+// different hashcodes. Vertices which don't implement ContentHasher are
+// identified by their Hashcode.
+type ContentHasher interface {
+	ContentHash() string
+}
+
+// ResultCache shares the results of vertices between walks, so that the
+// same work isn't done twice by walks of the same or overlapping graphs. If
+// a vertex is already running in another walk, the walk waits for it and
+// shares its result. Only successful results are kept once a vertex
+// completes, so failed vertices are run again by later walks.
+//
+// A ResultCache is safe for concurrent use, and the zero value is an empty
+// cache.
+type ResultCache struct {
+	lock    sync.Mutex
+	results map[string]*cachedResult
+}
+
+type cachedResult struct {
+	done chan struct{}
+	err  error
+}
+
+// contentKey returns the key identifying the work done by v.
+func contentKey[T Hashable](v T) string {
+	var raw interface{}
+	raw = v
+	if ch, ok := raw.(ContentHasher); ok {
+		return ch.ContentHash()
+	}
+	return v.Hashcode()
+}
+
+// do calls fn unless a result for key has been cached or is being computed,
+// in which case that result is returned instead.
+func (c *ResultCache) do(key string, fn func() error) error {
+	c.lock.Lock()
+	if c.results == nil {
+		c.results = make(map[string]*cachedResult)
+	}
+	if r, ok := c.results[key]; ok {
+		c.lock.Unlock()
+		<-r.done
+		return r.err
+	}
+
+	r := &cachedResult{done: make(chan struct{})}
+	c.results[key] = r
+	c.lock.Unlock()
+
+	r.err = fn()
+
+	if r.err != nil {
+		c.lock.Lock()
+		delete(c.results, key)
+		c.lock.Unlock()
+	}
+	close(r.done)
+	return r.err
+}
+
+// store caches a successful result for key, for work which was done before
+// the cache was created. A result which is already cached is kept.
+func (c *ResultCache) store(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.results == nil {
+		c.results = make(map[string]*cachedResult)
+	}
+	if _, ok := c.results[key]; ok {
+		return
+	}
+	r := &cachedResult{done: make(chan struct{})}
+	close(r.done)
+	c.results[key] = r
+}
+
+// Has returns true if a successful result for the key is cached.
+func (c *ResultCache) Has(key string) bool {
+	c.lock.Lock()
+	r, ok := c.results[key]
+	c.lock.Unlock()
+	if !ok {
+		return false
+	}
+
+	select {
+	case <-r.done:
+		return r.err == nil
+	default:
+		return false
+	}
+}
+
+// Forget removes the result for the key, so the work is done again by the
+// next walk.
+func (c *ResultCache) Forget(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.results, key)
+}
@@ -0,0 +1,56 @@
+package dagg
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWalker_resultCache(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+
+	var calls [4]int32
+	cb := func(v myint) error {
+		atomic.AddInt32(&calls[v], 1)
+		if v == 3 {
+			return fmt.Errorf("error")
+		}
+		return nil
+	}
+
+	cache := &ResultCache{}
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := &Walker[myint]{Callback: cb, Reverse: true, Cache: cache}
+			w.Update(&g)
+			w.Wait()
+		}()
+	}
+	wg.Wait()
+
+	if calls[1] != 1 || calls[2] != 1 {
+		t.Fatalf("bad calls: %v", calls)
+	}
+	if calls[3] < 1 {
+		t.Fatalf("bad calls: %v", calls)
+	}
+	if !cache.Has("1") || cache.Has("3") {
+		t.Fatal("only successful results should be cached")
+	}
+
+	cache.Forget("1")
+	w := &Walker[myint]{Callback: cb, Reverse: true, Cache: cache}
+	w.Update(&g)
+	w.Wait()
+	if calls[1] != 2 || calls[2] != 1 {
+		t.Fatalf("bad calls: %v", calls)
+	}
+}
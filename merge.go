@@ -0,0 +1,58 @@
+package dagg
+
+import (
+	"github.com/hashicorp/go-multierror"
+)
+
+// Merge adds every vertex and edge of other to g. Vertices and edges which
+// are already in g, compared by Hashcode, are kept as they are.
+//
+// The edges are connected according to g's VertexPolicy, so with
+// StrictVertices the dangling edges of other are rejected. The other edges
+// are still added, and the errors are returned together.
+func (g *Graph[T]) Merge(other *Graph[T]) error {
+	for _, v := range other.vertices {
+		if !g.HasVertex(v) {
+			g.Add(v)
+		}
+	}
+
+	var err error
+	for _, e := range other.edges {
+		if !g.HasEdge(e) {
+			if cErr := g.Connect(e); cErr != nil {
+				err = multierror.Append(err, cErr)
+			}
+		}
+	}
+	return err
+}
+
+// Union returns a new graph containing every vertex and edge in either a or
+// b. Where both graphs contain the same vertex or edge, the one from a is
+// used. The result has the VertexPolicy of a, and any error from merging b
+// into it is returned as by Merge.
+func Union[T Hashable](a, b *Graph[T]) (*Graph[T], error) {
+	result := a.Copy()
+	if err := result.Merge(b); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Intersect returns a new graph containing only the vertices and edges which
+// are in both a and b. The vertices and edges are taken from a.
+func Intersect[T Hashable](a, b *Graph[T]) *Graph[T] {
+	result := &Graph[T]{}
+	for _, v := range a.vertices {
+		if b.HasVertex(v) {
+			result.Add(v)
+		}
+	}
+	for _, e := range a.edges {
+		if b.HasEdge(e) {
+			result.Connect(e)
+		}
+	}
+	return result
+}
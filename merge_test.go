@@ -0,0 +1,123 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphMerge(t *testing.T) {
+	var a Graph[myint]
+	a.Add(myint(1))
+	a.Add(myint(2))
+	a.Connect(BasicEdge(myint(1), myint(2)))
+
+	var b Graph[myint]
+	b.Add(myint(2))
+	b.Add(myint(3))
+	b.Connect(BasicEdge(myint(1), myint(2)))
+	b.Connect(BasicEdge(myint(2), myint(3)))
+
+	if err := a.Merge(&b); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(a.String())
+	expected := strings.TrimSpace(testGraphUnionStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+	if len(a.Edges()) != 2 {
+		t.Fatalf("bad edges: %#v", a.Edges())
+	}
+}
+
+func TestGraphUnion(t *testing.T) {
+	var a Graph[myint]
+	a.Add(myint(1))
+	a.Add(myint(2))
+	a.Connect(BasicEdge(myint(1), myint(2)))
+
+	var b Graph[myint]
+	b.Add(myint(2))
+	b.Add(myint(3))
+	b.Connect(BasicEdge(myint(2), myint(3)))
+
+	u, err := Union(&a, &b)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(u.String())
+	expected := strings.TrimSpace(testGraphUnionStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+	if a.HasVertex(myint(3)) {
+		t.Fatal("union should not modify its inputs")
+	}
+}
+
+func TestGraphMerge_strictVertices(t *testing.T) {
+	var a Graph[myint]
+	a.SetVertexPolicy(StrictVertices)
+	a.Add(myint(1))
+
+	var b Graph[myint]
+	b.SetVertexPolicy(AllowDanglingEdges)
+	b.Add(myint(1))
+	b.Add(myint(2))
+	b.Connect(BasicEdge(myint(1), myint(2)))
+	b.Connect(BasicEdge(myint(2), myint(3)))
+
+	if _, err := Union(&a, &b); err == nil {
+		t.Fatal("should error")
+	}
+
+	// the edges which can be connected still are
+	if err := a.Merge(&b); err == nil {
+		t.Fatal("should error")
+	}
+	if !a.HasEdge(BasicEdge(myint(1), myint(2))) {
+		t.Fatal("should have 1-2")
+	}
+	if a.HasVertex(myint(3)) || a.HasEdge(BasicEdge(myint(2), myint(3))) {
+		t.Fatal("should not have 3")
+	}
+}
+
+func TestGraphIntersect(t *testing.T) {
+	var a Graph[myint]
+	a.Add(myint(1))
+	a.Add(myint(2))
+	a.Add(myint(3))
+	a.Connect(BasicEdge(myint(1), myint(2)))
+	a.Connect(BasicEdge(myint(2), myint(3)))
+
+	var b Graph[myint]
+	b.Add(myint(1))
+	b.Add(myint(2))
+	b.Add(myint(3))
+	b.Connect(BasicEdge(myint(1), myint(2)))
+	b.Connect(BasicEdge(myint(3), myint(2)))
+
+	actual := strings.TrimSpace(Intersect(&a, &b).String())
+	expected := strings.TrimSpace(testGraphIntersectStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+const testGraphUnionStr = `
+1
+  2
+2
+  3
+3
+`
+
+const testGraphIntersectStr = `
+1
+  2
+2
+3
+`
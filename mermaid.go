@@ -0,0 +1,172 @@
+package dagg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MermaidOpts are the options for generating a mermaid formatted Graph.
+type MermaidOpts struct {
+	// Highlight Cycles
+	DrawCycles bool
+
+	// How many levels to expand subgraphs as we draw. As with DotOpts, zero
+	// or less expands every level.
+	MaxDepth int
+
+	// Write the "label" attribute of an edge as the edge text.
+	EdgeLabels bool
+
+	// Theme used to style the vertices.
+	Theme *Theme
+}
+
+// Mermaid returns a mermaid-formatted representation of the Graph.
+func (g *Graph[T]) Mermaid(opts *MermaidOpts) []byte {
+	return newMarshalGraph("", g).Mermaid(opts)
+}
+
+// mermaidWriter tracks the state needed while writing a single mermaid graph.
+// Mermaid node IDs are global to the whole diagram and have a restricted
+// character set, so every vertex is assigned a generated ID.
+type mermaidWriter struct {
+	indentWriter
+	opts *MermaidOpts
+
+	ids       map[string]string
+	subgraphs int
+
+	// mermaid styles links by their index in the output, so we need to
+	// count every link we write.
+	links      int
+	cycleLinks []int
+}
+
+// Returns the mermaid representation of this Graph.
+func (g *marshalGraph) Mermaid(opts *MermaidOpts) []byte {
+	if opts == nil {
+		opts = &MermaidOpts{
+			DrawCycles: true,
+			MaxDepth:   -1,
+			EdgeLabels: true,
+		}
+	}
+
+	w := &mermaidWriter{
+		opts: opts,
+		ids:  make(map[string]string),
+	}
+	w.WriteString("graph TD\n")
+	w.Indent()
+
+	maxDepth := opts.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = -1
+	}
+
+	g.writeMermaidBody(w)
+	for _, sg := range g.Subgraphs {
+		g.writeMermaidSubgraph(sg, maxDepth, w)
+	}
+
+	if len(w.cycleLinks) > 0 {
+		idx := make([]string, len(w.cycleLinks))
+		for i, l := range w.cycleLinks {
+			idx[i] = strconv.Itoa(l)
+		}
+		w.WriteString(fmt.Sprintf("linkStyle %s stroke:red,stroke-width:2px\n", strings.Join(idx, ",")))
+	}
+
+	w.Unindent()
+	return w.Bytes()
+}
+
+// Write the subgraph body. This is recursive, and the depth argument is used
+// to record the current depth of iteration.
+func (g *marshalGraph) writeMermaidSubgraph(sg *marshalGraph, depth int, w *mermaidWriter) {
+	if depth == 0 {
+		return
+	}
+	depth--
+
+	id := fmt.Sprintf("s%d", w.subgraphs)
+	w.subgraphs++
+
+	w.WriteString(fmt.Sprintf("subgraph %s [%s]\n", id, mermaidLabel(sg.Name)))
+	w.Indent()
+	sg.writeMermaidBody(w)
+	for _, s := range sg.Subgraphs {
+		sg.writeMermaidSubgraph(s, depth, w)
+	}
+	w.Unindent()
+	w.WriteString("end\n")
+}
+
+func (g *marshalGraph) writeMermaidBody(w *mermaidWriter) {
+	var styles []string
+	for _, v := range g.Vertices {
+		id := w.id(g, v.ID)
+		style := w.opts.Theme.style(v.kind, v.state)
+		w.WriteString(style.mermaidNode(id, mermaidLabel(v.Name)) + "\n")
+
+		if props := style.mermaidStyle(); props != "" {
+			styles = append(styles, fmt.Sprintf("style %s %s\n", id, props))
+		}
+	}
+	for _, s := range styles {
+		w.WriteString(s)
+	}
+
+	// record which edges are part of a cycle so they can be highlighted
+	cycleEdges := make(map[string]bool)
+	if w.opts.DrawCycles {
+		for _, c := range g.Cycles {
+			if len(c) < 2 {
+				continue
+			}
+			for i := range c {
+				j := (i + 1) % len(c)
+				cycleEdges[c[i].ID+"\x00"+c[j].ID] = true
+			}
+		}
+	}
+
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Attrs["dir"] == "none" {
+			arrow = "---"
+		}
+		if label, ok := e.Attrs["label"]; ok && w.opts.EdgeLabels {
+			arrow = fmt.Sprintf("%s|%s|", arrow, mermaidLabel(label))
+		}
+		w.WriteString(fmt.Sprintf("%s %s %s\n", w.id(g, e.Source), arrow, w.id(g, e.Target)))
+
+		if cycleEdges[e.Source+"\x00"+e.Target] {
+			w.cycleLinks = append(w.cycleLinks, w.links)
+		}
+		w.links++
+	}
+}
+
+// id returns the generated mermaid ID for the vertex with the given ID within
+// graph g.
+func (w *mermaidWriter) id(g *marshalGraph, vertexID string) string {
+	key := g.Name + "\x00" + vertexID
+	id, ok := w.ids[key]
+	if !ok {
+		id = fmt.Sprintf("n%d", len(w.ids))
+		w.ids[key] = id
+	}
+	return id
+}
+
+// mermaidLabel quotes a name for use as a mermaid label. Names from the
+// marshalGraph are already escaped for dot, so they are unescaped first.
+func mermaidLabel(name string) string {
+	if s, err := strconv.Unquote(`"` + name + `"`); err == nil {
+		name = s
+	}
+	name = strings.ReplaceAll(name, `"`, "#quot;")
+	return `"` + name + `"`
+}
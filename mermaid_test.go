@@ -0,0 +1,180 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphMermaid_empty(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+
+	actual := strings.TrimSpace(string(g.Mermaid(nil)))
+	expected := strings.TrimSpace(testGraphMermaidEmptyStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestGraphMermaid_basic(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+
+	actual := strings.TrimSpace(string(g.Mermaid(nil)))
+	expected := strings.TrimSpace(testGraphMermaidBasicStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestGraphMermaid_quoted(t *testing.T) {
+	var g Graph[mystr]
+	quoted := mystr(`name["with-quotes"]`)
+	other := mystr(`other`)
+	g.Add(quoted)
+	g.Add(other)
+	g.Connect(BasicEdge(quoted, other))
+
+	actual := strings.TrimSpace(string(g.Mermaid(nil)))
+	expected := strings.TrimSpace(testGraphMermaidQuotedStr)
+	if actual != expected {
+		t.Fatalf("\ngot:   %q\nwanted %q\n", actual, expected)
+	}
+}
+
+func TestGraphMermaid_edgeLabels(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(&testNamedEdge{Edge: BasicEdge(myint(1), myint(2)), name: "uses"})
+
+	actual := strings.TrimSpace(string(g.Mermaid(nil)))
+	expected := strings.TrimSpace(testGraphMermaidLabelStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	actual = strings.TrimSpace(string(g.Mermaid(&MermaidOpts{})))
+	expected = strings.TrimSpace(testGraphMermaidNoLabelStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestGraphMermaid_cycle(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+
+	actual := strings.TrimSpace(string(g.Mermaid(nil)))
+	expected := strings.TrimSpace(testGraphMermaidCycleStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+type testNamedEdge struct {
+	Edge[myint]
+	name string
+}
+
+func (e *testNamedEdge) Name() string { return e.name }
+
+func TestGraphMermaid_subgraphs(t *testing.T) {
+	deepest := &Graph[*testSubgraphVertex]{}
+	deepest.Add(&testSubgraphVertex{name: "z"})
+
+	inner := &Graph[*testSubgraphVertex]{}
+	x := inner.Add(&testSubgraphVertex{name: "x", sub: deepest})
+	y := inner.Add(&testSubgraphVertex{name: "y"})
+	inner.Connect(BasicEdge(x, y))
+
+	var g Graph[*testSubgraphVertex]
+	a := g.Add(&testSubgraphVertex{name: "a", sub: inner})
+	b := g.Add(&testSubgraphVertex{name: "b"})
+	g.Connect(BasicEdge(a, b))
+
+	// zero expands every level, as with Dot
+	for _, depth := range []int{0, -1} {
+		actual := strings.TrimSpace(string(g.Mermaid(&MermaidOpts{MaxDepth: depth})))
+		expected := strings.TrimSpace(testGraphMermaidSubgraphsStr)
+		if actual != expected {
+			t.Fatalf("bad: %d: %s", depth, actual)
+		}
+	}
+
+	actual := strings.TrimSpace(string(g.Mermaid(&MermaidOpts{MaxDepth: 1})))
+	expected := strings.TrimSpace(testGraphMermaidSubgraphsDepthStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+const testGraphMermaidEmptyStr = `graph TD
+	n0["1"]
+	n1["2"]
+	n2["3"]`
+
+const testGraphMermaidBasicStr = `graph TD
+	n0["1"]
+	n1["2"]
+	n2["3"]
+	n0 --> n2`
+
+const testGraphMermaidQuotedStr = `graph TD
+	n0["name[#quot;with-quotes#quot;]"]
+	n1["other"]
+	n0 --> n1`
+
+const testGraphMermaidLabelStr = `graph TD
+	n0["1"]
+	n1["2"]
+	n0 -->|"uses"| n1`
+
+const testGraphMermaidNoLabelStr = `graph TD
+	n0["1"]
+	n1["2"]
+	n0 --> n1`
+
+const testGraphMermaidCycleStr = `graph TD
+	n0["1"]
+	n1["2"]
+	n2["3"]
+	n0 --> n1
+	n1 --> n0
+	n1 --> n2
+	linkStyle 0,1 stroke:red,stroke-width:2px`
+
+const testGraphMermaidSubgraphsStr = `graph TD
+	n0["a"]
+	n1["b"]
+	n0 --> n1
+	subgraph s0 ["a"]
+		n2["x"]
+		n3["y"]
+		n2 --> n3
+		subgraph s1 ["x"]
+			n4["z"]
+		end
+	end
+`
+
+const testGraphMermaidSubgraphsDepthStr = `graph TD
+	n0["a"]
+	n1["b"]
+	n0 --> n1
+	subgraph s0 ["a"]
+		n2["x"]
+		n3["y"]
+		n2 --> n3
+	end
+`
@@ -0,0 +1,131 @@
+package dagg
+
+// The operations of a Mutation.
+const (
+	MutationAdd        = "add"
+	MutationRemove     = "remove"
+	MutationConnect    = "connect"
+	MutationRemoveEdge = "remove_edge"
+
+	// MutationExpireEdge is the removal of an ExpiringEdge by Sweep.
+	MutationExpireEdge = "expire_edge"
+)
+
+// Mutation is a single change to a graph: a vertex added or removed, or an
+// edge connected or removed. Replace and Remove are made up of several
+// mutations, so removing a vertex first removes each of its edges.
+type Mutation[T Hashable] struct {
+	Op string
+
+	// Vertex is set for MutationAdd and MutationRemove.
+	Vertex T
+
+	// Edge is set for MutationConnect, MutationRemoveEdge and
+	// MutationExpireEdge.
+	Edge Edge[T]
+}
+
+// mutationHook is a function called with each mutation of a graph.
+type mutationHook[T Hashable] struct {
+	fn func(Mutation[T])
+}
+
+// addHook registers fn to be called with each mutation of the graph,
+// returning the hook so it can be removed.
+func (g *Graph[T]) addHook(fn func(Mutation[T])) *mutationHook[T] {
+	h := &mutationHook[T]{fn: fn}
+	g.hooks = append(g.hooks, h)
+	return h
+}
+
+// removeHook removes a hook returned by addHook.
+func (g *Graph[T]) removeHook(h *mutationHook[T]) {
+	for i, hook := range g.hooks {
+		if hook == h {
+			g.hooks = append(g.hooks[:i:i], g.hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+// notify calls the hooks with a mutation. Every mutation passes through
+// notify, so it also advances the version of the graph.
+func (g *Graph[T]) notify(m Mutation[T]) {
+	g.version++
+	g.callHooks(m)
+}
+
+// callHooks calls the hooks with a mutation without advancing the version,
+// for mutations which have already been counted.
+func (g *Graph[T]) callHooks(m Mutation[T]) {
+	for _, h := range g.hooks {
+		h.fn(m)
+	}
+}
+
+// Observer is notified of each mutation of a graph it is registered with by
+// Observe, for example to maintain a derived index or mirror the graph to a
+// UI. The methods are called after the graph has been changed, on the
+// goroutine that changed it, so they must not modify the graph.
+//
+// Removing a vertex first removes each of its edges, calling OnRemoveEdge
+// for each. An edge removed by Sweep is also passed to OnRemoveEdge.
+type Observer[T Hashable] interface {
+	OnAddVertex(v T)
+	OnRemoveVertex(v T)
+	OnConnect(e Edge[T])
+	OnRemoveEdge(e Edge[T])
+}
+
+// ObserverFuncs is an Observer made of optional functions. A nil function
+// ignores its mutations.
+type ObserverFuncs[T Hashable] struct {
+	AddVertex    func(v T)
+	RemoveVertex func(v T)
+	Connect      func(e Edge[T])
+	RemoveEdge   func(e Edge[T])
+}
+
+func (o *ObserverFuncs[T]) OnAddVertex(v T) {
+	if o.AddVertex != nil {
+		o.AddVertex(v)
+	}
+}
+
+func (o *ObserverFuncs[T]) OnRemoveVertex(v T) {
+	if o.RemoveVertex != nil {
+		o.RemoveVertex(v)
+	}
+}
+
+func (o *ObserverFuncs[T]) OnConnect(e Edge[T]) {
+	if o.Connect != nil {
+		o.Connect(e)
+	}
+}
+
+func (o *ObserverFuncs[T]) OnRemoveEdge(e Edge[T]) {
+	if o.RemoveEdge != nil {
+		o.RemoveEdge(e)
+	}
+}
+
+// Observe registers o to be notified of each later mutation of the graph,
+// returning a function which unregisters it. Observers belong to the graph
+// they are registered with, and aren't given to copies or snapshots. The
+// mutations of a Tx are only observed once it is applied.
+func (g *Graph[T]) Observe(o Observer[T]) func() {
+	h := g.addHook(func(m Mutation[T]) {
+		switch m.Op {
+		case MutationAdd:
+			o.OnAddVertex(m.Vertex)
+		case MutationRemove:
+			o.OnRemoveVertex(m.Vertex)
+		case MutationConnect:
+			o.OnConnect(m.Edge)
+		case MutationRemoveEdge, MutationExpireEdge:
+			o.OnRemoveEdge(m.Edge)
+		}
+	})
+	return func() { g.removeHook(h) }
+}
@@ -0,0 +1,77 @@
+package dagg
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testObserver records the mutations it observes.
+type testObserver struct {
+	events []string
+}
+
+func (o *testObserver) OnAddVertex(v myint)    { o.record("add %s", VertexName(v)) }
+func (o *testObserver) OnRemoveVertex(v myint) { o.record("remove %s", VertexName(v)) }
+func (o *testObserver) OnConnect(e Edge[myint]) {
+	o.record("connect %s", e.Hashcode())
+}
+func (o *testObserver) OnRemoveEdge(e Edge[myint]) {
+	o.record("remove edge %s", e.Hashcode())
+}
+
+func (o *testObserver) record(format string, v string) {
+	o.events = append(o.events, fmt.Sprintf(format, v))
+}
+
+func TestGraphObserve(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+
+	o := &testObserver{}
+	cancel := g.Observe(o)
+
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(TemporaryEdge(myint(3), myint(1), time.Unix(0, 0)))
+	g.SweepAt(time.Unix(0, 0))
+	g.Remove(myint(1))
+	g.Remove(myint(1))
+
+	cancel()
+	g.Add(myint(4))
+
+	expected := []string{
+		"add 2",
+		"add 3",
+		"connect 2-1",
+		"connect 3-1",
+		"remove edge 3-1",
+		"remove edge 2-1",
+		"remove 1",
+	}
+	if !reflect.DeepEqual(o.events, expected) {
+		t.Fatalf("bad: %#v", o.events)
+	}
+}
+
+func TestGraphObserve_funcs(t *testing.T) {
+	var g Graph[myint]
+
+	var added []myint
+	g.Observe(&ObserverFuncs[myint]{
+		AddVertex: func(v myint) { added = append(added, v) },
+	})
+
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Remove(myint(2))
+
+	if !reflect.DeepEqual(added, []myint{1, 2}) {
+		t.Fatalf("bad: %#v", added)
+	}
+}
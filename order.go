@@ -0,0 +1,81 @@
+package dagg
+
+import "sort"
+
+// WithVertexLess sets the comparator used wherever the graph orders
+// vertices by name, such as String, Dot, Mermaid, TopologicalGenerations
+// and the depth-first walks, in place of lexicographic order. less is given
+// the VertexName of each vertex. A nil less restores lexicographic order.
+//
+// Copies and snapshots of the graph keep the comparator.
+func (g *Graph[T]) WithVertexLess(less func(a, b string) bool) {
+	g.less = less
+}
+
+// nameLess compares two vertex names with the comparator of the graph.
+func (g *Graph[T]) nameLess(a, b string) bool {
+	if g.less != nil {
+		return g.less(a, b)
+	}
+	return a < b
+}
+
+// sortByName sorts vertices by their VertexName, using the comparator of the
+// graph.
+func (g *Graph[T]) sortByName(vs []T) {
+	if g.less == nil {
+		sort.Sort(byVertexName[T](vs))
+		return
+	}
+	sort.Slice(vs, func(i, j int) bool {
+		return g.less(VertexName(vs[i]), VertexName(vs[j]))
+	})
+}
+
+// NaturalLess orders strings so that runs of digits compare by their
+// numeric value, putting "node2" before "node10". It can be given to
+// WithVertexLess.
+func NaturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if !isDigit(a[i]) || !isDigit(b[j]) {
+			if a[i] != b[j] {
+				return a[i] < b[j]
+			}
+			i++
+			j++
+			continue
+		}
+
+		// compare the runs of digits, ignoring leading zeros
+		si, sj := i, j
+		for i < len(a) && isDigit(a[i]) {
+			i++
+		}
+		for j < len(b) && isDigit(b[j]) {
+			j++
+		}
+		x, y := trimZeros(a[si:i]), trimZeros(b[sj:j])
+		if len(x) != len(y) {
+			return len(x) < len(y)
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	if len(a)-i != len(b)-j {
+		return len(a)-i < len(b)-j
+	}
+
+	// equal but for leading zeros
+	return a < b
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func trimZeros(s string) string {
+	for len(s) > 1 && s[0] == '0' {
+		s = s[1:]
+	}
+	return s
+}
@@ -0,0 +1,118 @@
+package dagg
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	cases := []struct {
+		a, b string
+		less bool
+	}{
+		{"node2", "node10", true},
+		{"node10", "node2", false},
+		{"node2", "node2", false},
+		{"a", "b", true},
+		{"node", "node1", true},
+		{"node1", "node1a", true},
+		{"node1b", "node01a", false},
+		{"node01", "node1", true},
+		{"node1", "node01", false},
+		{"v2.10", "v2.9", false},
+		{"1", "a", true},
+	}
+
+	for _, tc := range cases {
+		if actual := NaturalLess(tc.a, tc.b); actual != tc.less {
+			t.Fatalf("bad: NaturalLess(%q, %q) = %t", tc.a, tc.b, actual)
+		}
+	}
+}
+
+func testNaturalGraph() *AcyclicGraph[mystr] {
+	var g AcyclicGraph[mystr]
+	for _, n := range []string{"node1", "node2", "node10"} {
+		g.Add(mystr(n))
+	}
+	g.Connect(BasicEdge(mystr("node1"), mystr("node10")))
+	g.Connect(BasicEdge(mystr("node1"), mystr("node2")))
+	g.WithVertexLess(NaturalLess)
+	return &g
+}
+
+func TestGraphWithVertexLess_string(t *testing.T) {
+	g := testNaturalGraph()
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testGraphWithVertexLessStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// copies keep the comparator
+	if actual := strings.TrimSpace(g.Copy().String()); actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	g.WithVertexLess(nil)
+	if actual := strings.TrimSpace(g.String()); actual == expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestGraphWithVertexLess_dot(t *testing.T) {
+	g := testNaturalGraph()
+
+	actual := strings.TrimSpace(string(g.Dot(nil)))
+	expected := strings.TrimSpace(testGraphWithVertexLessDotStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestGraphWithVertexLess_walk(t *testing.T) {
+	g := testNaturalGraph()
+
+	generations, err := g.TopologicalGenerations()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := fmt.Sprint(generations); actual != "[[node2 node10] [node1]]" {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	var visited []mystr
+	err = g.SortedDepthFirstWalk([]mystr{"node1"}, func(v mystr, _ int) error {
+		visited = append(visited, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	// the targets are pushed in order, so the last is visited first
+	if !reflect.DeepEqual(visited, []mystr{"node1", "node10", "node2"}) {
+		t.Fatalf("bad: %v", visited)
+	}
+}
+
+const testGraphWithVertexLessStr = `
+node1
+  node2
+  node10
+node2
+node10
+`
+
+const testGraphWithVertexLessDotStr = `
+digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] node1" -> "[root] node2"
+		"[root] node1" -> "[root] node10"
+	}
+}
+`
@@ -0,0 +1,103 @@
+package dagg
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// AllPaths returns every simple path from from to to, following the edges of
+// the graph. Each path starts with from and ends with to. Paths are
+// returned in a consistent order, by comparing the names of the vertices
+// along each path.
+func (g *AcyclicGraph[T]) AllPaths(from, to T) ([][]T, error) {
+	return g.AllPathsLimit(from, to, 0)
+}
+
+// AllPathsLimit is like AllPaths, but stops once limit paths have been found.
+// A limit of zero or less returns all paths.
+func (g *AcyclicGraph[T]) AllPathsLimit(from, to T, limit int) ([][]T, error) {
+	for _, v := range []T{from, to} {
+		if !g.HasVertex(v) {
+			return nil, fmt.Errorf("vertex %q not found", VertexName(v))
+		}
+	}
+
+	var paths [][]T
+	path := []T{from}
+	onPath := map[string]struct{}{from.Hashcode(): {}}
+	target := to.Hashcode()
+
+	var visit func(v T) bool
+	visit = func(v T) bool {
+		if v.Hashcode() == target {
+			paths = append(paths, append([]T(nil), path...))
+			return limit > 0 && len(paths) >= limit
+		}
+
+		next := g.downEdgesNoCopy(v).List()
+		g.sortByName(next)
+		for _, n := range next {
+			if _, ok := onPath[n.Hashcode()]; ok {
+				continue
+			}
+
+			onPath[n.Hashcode()] = struct{}{}
+			path = append(path, n)
+			done := visit(n)
+			path = path[:len(path)-1]
+			delete(onPath, n.Hashcode())
+
+			if done {
+				return true
+			}
+		}
+		return false
+	}
+	visit(from)
+
+	return paths, nil
+}
+
+// CountPaths returns the number of distinct paths from from to to, following
+// the edges of the graph. The count is computed over a topological ordering
+// of the graph, so the paths don't need to be enumerated, and uses a
+// big.Int since the number of paths can grow exponentially with the size of
+// the graph. An error is returned if the graph contains a cycle.
+//
+// Complexity: O(V+E) big.Int additions
+func (g *AcyclicGraph[T]) CountPaths(from, to T) (*big.Int, error) {
+	for _, v := range []T{from, to} {
+		if !g.HasVertex(v) {
+			return nil, fmt.Errorf("vertex %q not found", VertexName(v))
+		}
+	}
+
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]*big.Int{
+		from.Hashcode(): big.NewInt(1),
+	}
+	for _, u := range order {
+		count, ok := counts[u.Hashcode()]
+		if !ok {
+			continue
+		}
+
+		for _, v := range g.downEdgesNoCopy(u) {
+			c, ok := counts[v.Hashcode()]
+			if !ok {
+				c = new(big.Int)
+				counts[v.Hashcode()] = c
+			}
+			c.Add(c, count)
+		}
+	}
+
+	if count, ok := counts[to.Hashcode()]; ok {
+		return count, nil
+	}
+	return new(big.Int), nil
+}
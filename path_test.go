@@ -0,0 +1,114 @@
+package dagg
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestAcyclicGraphAllPaths(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Add(myint(5))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(4)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+	g.Connect(BasicEdge(myint(1), myint(4)))
+	g.Connect(BasicEdge(myint(4), myint(5)))
+
+	actual, err := g.AllPaths(myint(1), myint(4))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := [][]myint{
+		{1, 2, 4},
+		{1, 3, 4},
+		{1, 4},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	actual, err = g.AllPathsLimit(myint(1), myint(5), 2)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected = [][]myint{
+		{1, 2, 4, 5},
+		{1, 3, 4, 5},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	actual, err = g.AllPaths(myint(5), myint(1))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(actual) != 0 {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if _, err := g.AllPaths(myint(1), myint(9)); err == nil {
+		t.Fatal("should error on missing vertex")
+	}
+}
+
+func TestAcyclicGraphCountPaths(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(4)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+	g.Connect(BasicEdge(myint(1), myint(4)))
+
+	count, err := g.CountPaths(myint(1), myint(4))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if count.Int64() != 3 {
+		t.Fatalf("bad: %s", count)
+	}
+
+	count, err = g.CountPaths(myint(4), myint(1))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if count.Sign() != 0 {
+		t.Fatalf("bad: %s", count)
+	}
+}
+
+func TestAcyclicGraphCountPaths_large(t *testing.T) {
+	// a chain of 100 diamonds has 2^100 paths from end to end
+	var g AcyclicGraph[mystr]
+	prev := g.Add(mystr("0"))
+	for i := 1; i <= 100; i++ {
+		next := g.Add(mystr(fmt.Sprint(i)))
+		for _, side := range []string{"a", "b"} {
+			mid := g.Add(mystr(fmt.Sprintf("%d%s", i, side)))
+			g.Connect(BasicEdge(prev, mid))
+			g.Connect(BasicEdge(mid, next))
+		}
+		prev = next
+	}
+
+	count, err := g.CountPaths(mystr("0"), mystr("100"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := new(big.Int).Lsh(big.NewInt(1), 100)
+	if count.Cmp(expected) != 0 {
+		t.Fatalf("bad: %s", count)
+	}
+}
@@ -0,0 +1,225 @@
+package dagg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// CheckpointVersion is the schema version of the checkpoints returned by
+// Walker.Checkpoint.
+const CheckpointVersion = 1
+
+// walkCheckpoint is the serialized form of a checkpoint.
+type walkCheckpoint struct {
+	Version int `json:"v"`
+
+	// Completed lists the hashcodes of the vertices which succeeded.
+	Completed []string `json:"completed"`
+
+	// Failed and Skipped hold the errors of the vertices which failed or
+	// were skipped, by hashcode, and Hash the ContentHash of the walked
+	// graph. They're only recorded by SaveWalk.
+	Failed  map[string]string `json:"failed,omitempty"`
+	Skipped map[string]string `json:"skipped,omitempty"`
+	Hash    string            `json:"hash,omitempty"`
+}
+
+// restoredVertex is the outcome of a vertex in a restored checkpoint.
+type restoredVertex struct {
+	err     error
+	skipped bool
+}
+
+// Pause stops the walk from starting any more vertices until Resume is
+// called. Vertices which are already running carry on to completion. The
+// walks of SubWalk are paused along with their parent.
+func (w *Walker[T]) Pause() {
+	w.pauseLock.Lock()
+	defer w.pauseLock.Unlock()
+	if w.resumeCh == nil {
+		w.resumeCh = make(chan struct{})
+	}
+}
+
+// Resume lets a paused walk start vertices again.
+func (w *Walker[T]) Resume() {
+	w.pauseLock.Lock()
+	defer w.pauseLock.Unlock()
+	if w.resumeCh != nil {
+		close(w.resumeCh)
+		w.resumeCh = nil
+	}
+}
+
+// Paused returns true if the walk is paused.
+func (w *Walker[T]) Paused() bool {
+	w.pauseLock.Lock()
+	defer w.pauseLock.Unlock()
+	return w.resumeCh != nil
+}
+
+// waitPaused waits while the walk, or the walk it is a SubWalk of, is
+// paused, or until cancelCh is closed.
+func (w *Walker[T]) waitPaused(cancelCh <-chan struct{}) {
+	for p := w; p != nil; p = p.parent {
+		p.pauseLock.Lock()
+		resumeCh := p.resumeCh
+		p.pauseLock.Unlock()
+		if resumeCh == nil {
+			continue
+		}
+
+		select {
+		case <-resumeCh:
+			// the walk may have been paused again, so check from the start
+			w.waitPaused(cancelCh)
+			return
+		case <-cancelCh:
+			return
+		}
+	}
+}
+
+// Checkpoint returns the progress of the walk, so it can be resumed by a
+// new Walker after the process restarts. Only the vertices which have
+// succeeded are recorded, so vertices which are running, failed or were
+// skipped are run again. The walk would usually be paused, and its running
+// vertices left to finish, before taking a checkpoint. SaveWalk records
+// the whole state of the walk instead.
+func (w *Walker[T]) Checkpoint() ([]byte, error) {
+	return json.Marshal(w.checkpoint(false))
+}
+
+// checkpoint returns the progress of the walk. The vertices which failed or
+// were skipped are only recorded if all is true.
+func (w *Walker[T]) checkpoint(all bool) walkCheckpoint {
+	w.errLock.Lock()
+	c := walkCheckpoint{Version: CheckpointVersion, Completed: []string{}}
+	for k, r := range w.results {
+		switch {
+		case r.Status == WalkStatusSuccess:
+			c.Completed = append(c.Completed, k)
+		case !all:
+		case r.Status == WalkStatusFailed:
+			if c.Failed == nil {
+				c.Failed = make(map[string]string)
+			}
+			c.Failed[k] = r.Err.Error()
+		case r.Status == WalkStatusSkipped:
+			if c.Skipped == nil {
+				c.Skipped = make(map[string]string)
+			}
+			c.Skipped[k] = r.Err.Error()
+		}
+	}
+	w.errLock.Unlock()
+
+	sort.Strings(c.Completed)
+	return c
+}
+
+// RestoreCheckpoint restores the progress of a walk from a checkpoint
+// returned by Checkpoint or SaveWalk. It must be called before the first
+// Update. The vertices recorded as completed aren't run again, and succeed
+// as soon as their dependencies have. Those recorded as failed or skipped
+// aren't run again either, and fail or are skipped with the same error.
+func (w *Walker[T]) RestoreCheckpoint(data []byte) error {
+	c, err := readCheckpoint(data)
+	if err != nil {
+		return err
+	}
+
+	w.restored = make(map[string]restoredVertex, len(c.Completed)+len(c.Failed)+len(c.Skipped))
+	for _, k := range c.Completed {
+		w.restored[k] = restoredVertex{}
+	}
+	for k, msg := range c.Failed {
+		w.restored[k] = restoredVertex{err: restoredError(msg)}
+	}
+	for k, msg := range c.Skipped {
+		w.restored[k] = restoredVertex{err: restoredError(msg), skipped: true}
+	}
+	return nil
+}
+
+// readCheckpoint decodes a checkpoint, checking its version.
+func readCheckpoint(data []byte) (*walkCheckpoint, error) {
+	var c walkCheckpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.Version != CheckpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d", c.Version)
+	}
+	return &c, nil
+}
+
+// restoredError returns the error of a restored vertex from its message,
+// which is one of the walk's own errors if it has the same message.
+func restoredError(msg string) error {
+	for _, err := range []error{ErrVetoed, errWalkUpstream, errWalkThreshold, errWalkDomain} {
+		if err.Error() == msg {
+			return err
+		}
+	}
+	return errors.New(msg)
+}
+
+// restoredResult returns the outcome of v if it finished in a restored
+// checkpoint.
+func (w *Walker[T]) restoredResult(v T) (restoredVertex, bool) {
+	r, ok := w.restored[v.Hashcode()]
+	return r, ok
+}
+
+// SaveWalk returns the state of the walk of w, so it can be resumed with
+// ResumeWalk after the process restarts. Unlike Checkpoint, the vertices
+// which failed or were skipped are recorded as well as those which
+// succeeded, so the resumed walk has the same result, along with the
+// ContentHash of the walked graph so the walk isn't resumed against
+// another. Undirected edges aren't walked, so they're left out of the hash.
+// Vertices which are running are run again, so the walk would usually be
+// paused, and its running vertices left to finish, before it is saved.
+func SaveWalk[T Hashable](w *Walker[T]) ([]byte, error) {
+	c := w.checkpoint(true)
+	w.changeLock.Lock()
+	c.Hash = contentHash(w.vertices, w.edges)
+	w.changeLock.Unlock()
+	return json.Marshal(c)
+}
+
+// ResumeWalk resumes the walk of g saved by SaveWalk with w, which should be
+// a new Walker configured as the one which was saved, and must not have
+// been updated yet. The vertices which didn't finish are run with the
+// options of w, and those which finished before the walk was saved keep
+// their results. If w has a Cache, the vertices which succeeded are stored
+// in it, so other walks sharing the cache don't run them again. Wait
+// should be called on w for the result of the whole walk. An error is
+// returned if g isn't the graph which was walked.
+func ResumeWalk[T Hashable](data []byte, g *AcyclicGraph[T], w *Walker[T]) error {
+	c, err := readCheckpoint(data)
+	if err != nil {
+		return err
+	}
+	if c.Hash == "" {
+		return fmt.Errorf("checkpoint has no graph hash: use RestoreCheckpoint")
+	}
+	if hash := contentHash(g.vertices, g.edges.Filter(IsDirected[T])); hash != c.Hash {
+		return fmt.Errorf("checkpoint is of a different graph: hash %s, expected %s", hash, c.Hash)
+	}
+
+	if err := w.RestoreCheckpoint(data); err != nil {
+		return err
+	}
+	if w.Cache != nil {
+		for _, k := range c.Completed {
+			if v, ok := g.vertices[k]; ok {
+				w.Cache.store(contentKey(v))
+			}
+		}
+	}
+	w.Update(g)
+	return nil
+}
@@ -0,0 +1,204 @@
+package dagg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWalker_pauseCheckpoint(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 4; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(BasicEdge(myint(3), myint(2)))
+	g.Connect(BasicEdge(myint(4), myint(3)))
+
+	var lock sync.Mutex
+	var ran []myint
+	var w *Walker[myint]
+	finished := make(chan struct{})
+	cb := func(v myint) error {
+		lock.Lock()
+		ran = append(ran, v)
+		lock.Unlock()
+		if v == 2 {
+			// pause while 2 is running, so 3 doesn't start
+			w.Pause()
+			close(finished)
+		}
+		return nil
+	}
+
+	w = &Walker[myint]{Callback: cb, Reverse: true}
+	w.Update(&g)
+	<-finished
+	time.Sleep(10 * time.Millisecond)
+	if !w.Paused() {
+		t.Fatal("should be paused")
+	}
+
+	data, err := w.Checkpoint()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(data) != `{"v":1,"completed":["1","2"]}` {
+		t.Fatalf("bad: %s", data)
+	}
+	lock.Lock()
+	if !reflect.DeepEqual(ran, []myint{1, 2}) {
+		t.Fatalf("bad: %#v", ran)
+	}
+	lock.Unlock()
+
+	w.Resume()
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// a new walker restored from the checkpoint only runs the rest
+	ran = nil
+	w2 := &Walker[myint]{Callback: walkCbRecord(&ran), Reverse: true}
+	if err := w2.RestoreCheckpoint(data); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	w2.Update(&g)
+	if err := w2.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(ran, []myint{3, 4}) {
+		t.Fatalf("bad: %#v", ran)
+	}
+
+	succeeded := w2.Result().WithStatus(WalkStatusSuccess)
+	sort.Sort(byVertexName[myint](succeeded))
+	if !reflect.DeepEqual(succeeded, []myint{1, 2, 3, 4}) {
+		t.Fatalf("bad: %#v", succeeded)
+	}
+
+	if err := w2.RestoreCheckpoint([]byte(`{"v":2}`)); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestSaveWalk(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 4; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(BasicEdge(myint(4), myint(3)))
+
+	var w *Walker[myint]
+	failed := make(chan struct{})
+	paused := make(chan struct{})
+	cb := func(v myint) error {
+		switch v {
+		case 1:
+			close(failed)
+			return fmt.Errorf("1 failed")
+		case 3:
+			// pause once 2 has been skipped, so 4 doesn't start
+			<-failed
+			time.Sleep(10 * time.Millisecond)
+			w.Pause()
+			close(paused)
+		}
+		return nil
+	}
+
+	w = &Walker[myint]{Callback: cb, Reverse: true}
+	w.Update(&g)
+	<-paused
+	time.Sleep(10 * time.Millisecond)
+
+	data, err := SaveWalk(w)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	var c walkCheckpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if c.Hash != g.ContentHash() {
+		t.Fatalf("bad hash: %s", c.Hash)
+	}
+	c.Hash = ""
+	expected := walkCheckpoint{
+		Version:   CheckpointVersion,
+		Completed: []string{"3"},
+		Failed:    map[string]string{"1": "1 failed"},
+		Skipped:   map[string]string{"2": errWalkUpstream.Error()},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Fatalf("bad: %#v", c)
+	}
+
+	w.Resume()
+	if err := w.Wait(); err == nil {
+		t.Fatal("expect error")
+	}
+
+	// the resumed walk only runs 4, and has the same result as the first
+	var ran []myint
+	var buf bytes.Buffer
+	cache := &ResultCache{}
+	w2 := &Walker[myint]{
+		Reverse:  true,
+		Callback: walkCbRecord(&ran),
+		Cache:    cache,
+		EventLog: &buf,
+	}
+	if err := ResumeWalk(data, &g, w2); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	err = w2.Wait()
+	if err == nil || err.Error() != w.Wait().Error() {
+		t.Fatalf("bad: %v", err)
+	}
+	if !reflect.DeepEqual(ran, []myint{4}) {
+		t.Fatalf("bad: %#v", ran)
+	}
+	r := w2.Result()
+	for v, status := range map[myint]string{
+		1: WalkStatusFailed,
+		2: WalkStatusSkipped,
+		3: WalkStatusSuccess,
+		4: WalkStatusSuccess,
+	} {
+		if r.Status(v) != status {
+			t.Fatalf("%d: bad: %s", v, r.Status(v))
+		}
+	}
+
+	// the options of the walker are kept, and 3 is cached without running
+	if !cache.Has("3") || !cache.Has("4") || cache.Has("1") {
+		t.Fatal("bad cache")
+	}
+	l, err := ReadWalkLog(&buf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := l.Vertices["4"]; !ok {
+		t.Fatalf("bad: %#v", l.Vertices)
+	}
+
+	// a different graph can't be resumed
+	g.Connect(BasicEdge(myint(3), myint(2)))
+	if err := ResumeWalk(data, &g, &Walker[myint]{Callback: walkCbRecord(&ran)}); err == nil {
+		t.Fatal("expect error")
+	}
+
+	// nor can a checkpoint without a hash
+	data, _ = w.Checkpoint()
+	g.RemoveEdge(BasicEdge(myint(3), myint(2)))
+	if err := ResumeWalk(data, &g, &Walker[myint]{Callback: walkCbRecord(&ran)}); err == nil {
+		t.Fatal("expect error")
+	}
+}
@@ -0,0 +1,359 @@
+package dagg
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// Delta is a serialized Mutation, as given to a Persister.
+type Delta struct {
+	Op string `json:"op"`
+
+	// Vertex is the encoded vertex, for MutationAdd and MutationRemove.
+	Vertex string `json:"vertex,omitempty"`
+
+	// Source and Target are the encoded vertices of the edge, for
+	// MutationConnect, MutationRemoveEdge and MutationExpireEdge.
+	Source     string `json:"source,omitempty"`
+	Target     string `json:"target,omitempty"`
+	Undirected bool   `json:"undirected,omitempty"`
+
+	// Data is the encoded data of a DataEdge.
+	Data *string `json:"data,omitempty"`
+
+	// Expires is the expiry time of an ExpiringEdge.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// Persister stores the deltas of a graph, for example by appending them to
+// a write-ahead log, so the graph can be rebuilt by replaying them.
+type Persister interface {
+	Persist(d Delta) error
+}
+
+// PersistOpts are the options for persisting the mutations of a graph.
+type PersistOpts[T Hashable] struct {
+	// Encode serializes a vertex. The vertex's Hashcode is used if it is
+	// nil.
+	Encode func(T) (string, error)
+
+	// EncodeData serializes the data of a DataEdge. The data is formatted
+	// with fmt.Sprint if it is nil.
+	EncodeData func(interface{}) (string, error)
+
+	// Async persists the deltas from a separate goroutine, so mutations
+	// don't wait for the Persister. Buffer is the number of deltas which
+	// may be waiting before mutations block.
+	Async  bool
+	Buffer int
+}
+
+// Persistence is a Persister attached to a graph by Graph.Persist.
+type Persistence[T Hashable] struct {
+	g    *Graph[T]
+	hook *mutationHook[T]
+	p    Persister
+	opts PersistOpts[T]
+
+	deltas chan Delta
+	done   chan struct{}
+
+	lock sync.Mutex
+	err  error
+}
+
+// Persist calls p with the delta of every later mutation of the graph, until
+// Close is called. Unless opts.Async is set, p is called before the
+// mutation returns.
+//
+// A delta is persisted once its mutation has been made, and the mutation
+// isn't undone if the delta can't be encoded or persisted: the error is
+// logged instead, and the first such error is returned by Err and Close.
+// A nil opts is the same as the zero PersistOpts.
+func (g *Graph[T]) Persist(p Persister, opts *PersistOpts[T]) *Persistence[T] {
+	if opts == nil {
+		opts = &PersistOpts[T]{}
+	}
+
+	ps := &Persistence[T]{g: g, p: p, opts: *opts}
+	if opts.Async {
+		ps.deltas = make(chan Delta, opts.Buffer)
+		ps.done = make(chan struct{})
+		go ps.run()
+	}
+	ps.hook = g.addHook(ps.mutated)
+	return ps
+}
+
+// mutated encodes and persists a mutation.
+func (ps *Persistence[T]) mutated(m Mutation[T]) {
+	d, err := ps.opts.Delta(m)
+	if err != nil {
+		ps.fail(err)
+		return
+	}
+
+	if ps.deltas != nil {
+		ps.deltas <- d
+		return
+	}
+	if err := ps.p.Persist(d); err != nil {
+		ps.fail(err)
+	}
+}
+
+// run persists the deltas of an async Persistence.
+func (ps *Persistence[T]) run() {
+	defer close(ps.done)
+	for d := range ps.deltas {
+		if err := ps.p.Persist(d); err != nil {
+			ps.fail(err)
+		}
+	}
+}
+
+// Delta encodes a mutation as a Delta, using the encoders of the options.
+func (opts *PersistOpts[T]) Delta(m Mutation[T]) (Delta, error) {
+	d := Delta{Op: m.Op}
+	switch m.Op {
+	case MutationAdd, MutationRemove:
+		v, err := opts.encode(m.Vertex)
+		if err != nil {
+			return d, fmt.Errorf("encoding %q: %w", VertexName(m.Vertex), err)
+		}
+		d.Vertex = v
+
+	default:
+		var err error
+		if d.Source, err = opts.encode(m.Edge.Source()); err != nil {
+			return d, fmt.Errorf("encoding %q: %w", VertexName(m.Edge.Source()), err)
+		}
+		if d.Target, err = opts.encode(m.Edge.Target()); err != nil {
+			return d, fmt.Errorf("encoding %q: %w", VertexName(m.Edge.Target()), err)
+		}
+		d.Undirected = !IsDirected(m.Edge)
+
+		var raw interface{}
+		raw = m.Edge
+		if de, ok := raw.(DataEdge); ok && m.Op == MutationConnect {
+			data := fmt.Sprint(de.EdgeData())
+			if opts.EncodeData != nil {
+				if data, err = opts.EncodeData(de.EdgeData()); err != nil {
+					return d, fmt.Errorf("encoding the data of %q: %w", m.Edge.Hashcode(), err)
+				}
+			}
+			d.Data = &data
+		}
+		if ee, ok := raw.(ExpiringEdge); ok && m.Op == MutationConnect {
+			expires := ee.Expires()
+			d.Expires = &expires
+		}
+	}
+	return d, nil
+}
+
+// encode serializes a vertex with Encode, or as its Hashcode.
+func (opts *PersistOpts[T]) encode(v T) (string, error) {
+	if opts.Encode == nil {
+		return v.Hashcode(), nil
+	}
+	return opts.Encode(v)
+}
+
+func (ps *Persistence[T]) fail(err error) {
+	log.Printf("[WARN] dagg/persist: %s", err)
+
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	if ps.err == nil {
+		ps.err = err
+	}
+}
+
+// Err returns the first error encoding or persisting a delta.
+func (ps *Persistence[T]) Err() error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	return ps.err
+}
+
+// Close stops persisting the mutations of the graph, waiting for any
+// pending deltas of an async Persistence, and returns the first error.
+func (ps *Persistence[T]) Close() error {
+	if ps.hook != nil {
+		ps.g.removeHook(ps.hook)
+		ps.hook = nil
+		if ps.deltas != nil {
+			close(ps.deltas)
+			<-ps.done
+		}
+	}
+	return ps.Err()
+}
+
+// LogPersister is a Persister which writes each delta to W as a line of
+// JSON, for a write-ahead log which can be replayed by ReplayLog.
+type LogPersister struct {
+	W io.Writer
+
+	// Sync calls the Sync method of W after every delta, if it has one,
+	// such as an *os.File.
+	Sync bool
+}
+
+func (p *LogPersister) Persist(d Delta) error {
+	line, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	if _, err := p.W.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	if s, ok := p.W.(interface{ Sync() error }); ok && p.Sync {
+		return s.Sync()
+	}
+	return nil
+}
+
+// ApplyDelta applies a delta to the graph, calling decode to turn the
+// encoded vertices back into vertices. Edge data is given to the edge as a
+// string, as by LabeledEdge, and an edge with an expiry time is connected
+// as a TemporaryEdge, or as a TemporaryLabeledEdge if it has data too.
+// Undirected edges keep their data and expiry time in the same way.
+//
+// The delta records a mutation which was already made to another graph, so
+// an edge is connected whatever the VertexPolicy of g: the vertices the
+// other graph added for it have deltas of their own, and a dangling edge
+// stays dangling.
+func (g *Graph[T]) ApplyDelta(d Delta, decode func(string) (T, error)) error {
+	switch d.Op {
+	case MutationAdd, MutationRemove:
+		v, err := decode(d.Vertex)
+		if err != nil {
+			return err
+		}
+		if d.Op == MutationAdd {
+			g.Add(v)
+		} else {
+			g.Remove(v)
+		}
+
+	case MutationConnect, MutationRemoveEdge, MutationExpireEdge:
+		source, err := decode(d.Source)
+		if err != nil {
+			return err
+		}
+		target, err := decode(d.Target)
+		if err != nil {
+			return err
+		}
+
+		var e Edge[T]
+		switch {
+		case d.Undirected:
+			e = undirectedDeltaEdge(source, target, d)
+		case d.Expires != nil && d.Data != nil:
+			e = TemporaryLabeledEdge(source, target, *d.Data, *d.Expires)
+		case d.Expires != nil:
+			e = TemporaryEdge(source, target, *d.Expires)
+		case d.Data != nil:
+			e = LabeledEdge(source, target, *d.Data)
+		default:
+			e = BasicEdge(source, target)
+		}
+
+		if d.Op == MutationConnect {
+			g.connect(e)
+		} else {
+			g.RemoveEdge(e)
+		}
+
+	default:
+		return fmt.Errorf("unknown operation %q", d.Op)
+	}
+	return nil
+}
+
+// undirectedDeltaEdge returns the undirected edge of a delta, carrying its
+// data and expiry time.
+func undirectedDeltaEdge[T Hashable](a, b T, d Delta) Edge[T] {
+	e := undirectedEdge[T]{basicEdge[T]{Src: a, Trgt: b}}
+	switch {
+	case d.Expires != nil && d.Data != nil:
+		return &undirectedTemporaryLabeledEdge[T]{
+			undirectedLabeledEdge: undirectedLabeledEdge[T]{undirectedEdge: e, Data: *d.Data},
+			At:                    *d.Expires,
+		}
+	case d.Expires != nil:
+		return &undirectedTemporaryEdge[T]{undirectedEdge: e, At: *d.Expires}
+	case d.Data != nil:
+		return &undirectedLabeledEdge[T]{undirectedEdge: e, Data: *d.Data}
+	}
+	return &e
+}
+
+// undirectedLabeledEdge is an undirectedEdge with the data of a delta.
+type undirectedLabeledEdge[T Hashable] struct {
+	undirectedEdge[T]
+	Data string
+}
+
+func (e *undirectedLabeledEdge[T]) EdgeData() interface{} {
+	return e.Data
+}
+
+// undirectedTemporaryEdge is an undirectedEdge with an expiry time.
+type undirectedTemporaryEdge[T Hashable] struct {
+	undirectedEdge[T]
+	At time.Time
+}
+
+func (e *undirectedTemporaryEdge[T]) Expires() time.Time {
+	return e.At
+}
+
+// undirectedTemporaryLabeledEdge is an undirectedLabeledEdge with an expiry
+// time.
+type undirectedTemporaryLabeledEdge[T Hashable] struct {
+	undirectedLabeledEdge[T]
+	At time.Time
+}
+
+func (e *undirectedTemporaryLabeledEdge[T]) Expires() time.Time {
+	return e.At
+}
+
+// ReplayLog applies each delta of a log written by a LogPersister to g, in
+// order, with ApplyDelta, so the log is replayed whatever the VertexPolicy
+// of g. A final line which isn't complete, as left by a crash while
+// writing, is ignored.
+func ReplayLog[T Hashable](r io.Reader, g *Graph[T], decode func(string) (T, error)) error {
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		text, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			// a line without its newline wasn't finished
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(text) == 1 {
+			continue
+		}
+
+		var d Delta
+		if err := json.Unmarshal(text, &d); err != nil {
+			return fmt.Errorf("line %d: %s", line, err)
+		}
+		if err := g.ApplyDelta(d, decode); err != nil {
+			return fmt.Errorf("line %d: %s", line, err)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package dagg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func encodeMystr(v mystr) (string, error) { return string(v), nil }
+func decodeMystr(s string) (mystr, error) { return mystr(s), nil }
+
+func TestGraphPersist(t *testing.T) {
+	var g AcyclicGraph[mystr]
+	g.Add("before")
+
+	var buf bytes.Buffer
+	ps := g.Persist(&LogPersister{W: &buf}, &PersistOpts[mystr]{Encode: encodeMystr})
+
+	g.Add("a")
+	g.Add("b")
+	g.Add("c")
+	g.Connect(BasicEdge[mystr]("a", "b"))
+	g.Connect(LabeledEdge[mystr]("b", "c", 42))
+	g.Connect(UndirectedEdge[mystr]("a", "c"))
+	g.Add("d")
+	g.Connect(BasicEdge[mystr]("d", "a"))
+	g.Remove("d")
+
+	// committed transactions are persisted too
+	err := g.Apply(func(tx *GraphTx[mystr]) error {
+		tx.Add("e")
+		tx.Connect(BasicEdge[mystr]("e", "a"))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	err = g.Apply(func(tx *GraphTx[mystr]) error {
+		tx.Add("f")
+		return errors.New("rolled back")
+	})
+	if err == nil {
+		t.Fatal("should error")
+	}
+
+	if err := ps.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	g.Add("after")
+
+	actual := strings.TrimSpace(buf.String())
+	expected := strings.TrimSpace(testPersistLogStr)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+
+	var replayed Graph[mystr]
+	replayed.Add("before")
+	if err := ReplayLog(&buf, &replayed, decodeMystr); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	g.Remove("after")
+	if replayed.String() != g.String() {
+		t.Fatalf("bad:\n%s\nexpected:\n%s", replayed.String(), g.String())
+	}
+}
+
+type slowPersister struct {
+	lock   sync.Mutex
+	deltas []Delta
+	wait   chan struct{}
+}
+
+func (p *slowPersister) Persist(d Delta) error {
+	<-p.wait
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.deltas = append(p.deltas, d)
+	return nil
+}
+
+func TestGraphPersist_async(t *testing.T) {
+	var g Graph[mystr]
+	p := &slowPersister{wait: make(chan struct{})}
+	ps := g.Persist(p, &PersistOpts[mystr]{Encode: encodeMystr, Async: true, Buffer: 10})
+
+	// mutations don't wait for the persister
+	g.Add("a")
+	g.Add("b")
+	g.Connect(BasicEdge[mystr]("a", "b"))
+
+	close(p.wait)
+	if err := ps.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(p.deltas) != 3 || p.deltas[2].Op != MutationConnect {
+		t.Fatalf("bad: %#v", p.deltas)
+	}
+}
+
+func TestGraphPersist_encodeError(t *testing.T) {
+	var g Graph[mystr]
+	var buf bytes.Buffer
+	ps := g.Persist(&LogPersister{W: &buf}, &PersistOpts[mystr]{
+		Encode: func(v mystr) (string, error) {
+			if v == "bad" {
+				return "", errors.New("can't encode")
+			}
+			return string(v), nil
+		},
+	})
+
+	g.Add("a")
+	g.Add("bad")
+	g.Add("b")
+
+	if err := ps.Close(); err == nil || !strings.Contains(err.Error(), "can't encode") {
+		t.Fatalf("bad: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Fatalf("bad: %s", buf.String())
+	}
+}
+
+func TestReplayLog_truncated(t *testing.T) {
+	src := `{"op":"add","vertex":"a"}` + "\n" + `{"op":"add","vert`
+
+	var g Graph[mystr]
+	if err := ReplayLog(strings.NewReader(src), &g, decodeMystr); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := strings.TrimSpace(g.String()); actual != "a" {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	src = `{"op":"rename","vertex":"a"}` + "\n"
+	if err := ReplayLog(strings.NewReader(src), &g, decodeMystr); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestReplayLog_vertexPolicy(t *testing.T) {
+	// written from a graph which allows dangling edges
+	src := `{"op":"add","vertex":"a"}` + "\n" +
+		`{"op":"connect","source":"a","target":"b"}` + "\n"
+
+	for _, policy := range []VertexPolicy{AutoAddVertices, StrictVertices, AllowDanglingEdges} {
+		var g Graph[mystr]
+		g.SetVertexPolicy(policy)
+		if err := ReplayLog(strings.NewReader(src), &g, decodeMystr); err != nil {
+			t.Fatalf("%d: err: %s", policy, err)
+		}
+		if g.HasVertex("b") || len(g.DanglingEdges()) != 1 {
+			t.Fatalf("%d: bad: %#v", policy, g.Edges())
+		}
+	}
+}
+
+func TestReplayLog_undirectedData(t *testing.T) {
+	var g Graph[mystr]
+	g.Add("a")
+	g.Add("b")
+
+	var buf bytes.Buffer
+	ps := g.Persist(&LogPersister{W: &buf}, &PersistOpts[mystr]{Encode: encodeMystr})
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	g.Connect(&testUndirectedDataEdge{
+		undirectedEdge: undirectedEdge[mystr]{basicEdge[mystr]{Src: "a", Trgt: "b"}},
+		at:             expires,
+	})
+	if err := ps.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var replayed Graph[mystr]
+	if err := ReplayLog(&buf, &replayed, decodeMystr); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	edges := replayed.Edges()
+	if len(edges) != 1 || IsDirected(edges[0]) {
+		t.Fatalf("bad: %#v", edges)
+	}
+	var raw interface{}
+	raw = edges[0]
+	if de, ok := raw.(DataEdge); !ok || de.EdgeData() != "lease" {
+		t.Fatalf("bad: %#v", raw)
+	}
+	if ee, ok := raw.(ExpiringEdge); !ok || !ee.Expires().Equal(expires) {
+		t.Fatalf("bad: %#v", raw)
+	}
+}
+
+// testUndirectedDataEdge is an undirected edge with data and an expiry
+// time.
+type testUndirectedDataEdge struct {
+	undirectedEdge[mystr]
+	at time.Time
+}
+
+func (e *testUndirectedDataEdge) EdgeData() interface{} { return "lease" }
+func (e *testUndirectedDataEdge) Expires() time.Time    { return e.at }
+
+const testPersistLogStr = `
+{"op":"add","vertex":"a"}
+{"op":"add","vertex":"b"}
+{"op":"add","vertex":"c"}
+{"op":"connect","source":"a","target":"b"}
+{"op":"connect","source":"b","target":"c","data":"42"}
+{"op":"connect","source":"a","target":"c","undirected":true}
+{"op":"add","vertex":"d"}
+{"op":"connect","source":"d","target":"a"}
+{"op":"remove_edge","source":"d","target":"a"}
+{"op":"remove","vertex":"d"}
+{"op":"add","vertex":"e"}
+{"op":"connect","source":"e","target":"a"}
+`
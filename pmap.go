@@ -0,0 +1,249 @@
+package dagg
+
+import (
+	"hash/fnv"
+	"math/bits"
+)
+
+// pmap is a persistent hash map from strings to values, implemented as a hash
+// array mapped trie. Every modification returns a new map which shares all
+// unchanged nodes with the original, so modifications are O(log n) in both
+// time and memory. The zero value is an empty map.
+type pmap[V any] struct {
+	root *pnode[V]
+	size int
+}
+
+const (
+	pmapBits = 5
+	pmapMask = 1<<pmapBits - 1
+
+	// pmapMaxShift is the shift beyond which the hash is exhausted, and
+	// colliding keys are stored in a list.
+	pmapMaxShift = 64
+)
+
+// pnode is a node of the trie. Branch nodes have a bitmap of which of the 32
+// slots are in use, with slots holding either an entry or a child node.
+// Collision nodes, below pmapMaxShift, only hold entries.
+type pnode[V any] struct {
+	bitmap uint32
+	slots  []pslot[V]
+}
+
+type pslot[V any] struct {
+	child *pnode[V]
+	hash  uint64
+	key   string
+	val   V
+}
+
+func pmapHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// Len returns the number of entries in the map.
+func (m pmap[V]) Len() int {
+	return m.size
+}
+
+// Get returns the value for key.
+func (m pmap[V]) Get(key string) (V, bool) {
+	return m.root.get(key, pmapHash(key), 0)
+}
+
+// Set returns a map with key set to val.
+func (m pmap[V]) Set(key string, val V) pmap[V] {
+	root, added := m.root.set(pslot[V]{hash: pmapHash(key), key: key, val: val}, 0)
+	if added {
+		m.size++
+	}
+	return pmap[V]{root: root, size: m.size}
+}
+
+// Delete returns a map without key.
+func (m pmap[V]) Delete(key string) pmap[V] {
+	root, deleted := m.root.delete(key, pmapHash(key), 0)
+	if !deleted {
+		return m
+	}
+	return pmap[V]{root: root, size: m.size - 1}
+}
+
+// Each calls cb for every entry in the map, in no particular order, until
+// cb returns false.
+func (m pmap[V]) Each(cb func(string, V) bool) {
+	m.root.each(cb)
+}
+
+func (n *pnode[V]) get(key string, hash uint64, shift uint) (V, bool) {
+	var zero V
+	for n != nil {
+		if shift >= pmapMaxShift {
+			for _, s := range n.slots {
+				if s.key == key {
+					return s.val, true
+				}
+			}
+			return zero, false
+		}
+
+		bit := uint32(1) << ((hash >> shift) & pmapMask)
+		if n.bitmap&bit == 0 {
+			return zero, false
+		}
+		s := n.slots[bits.OnesCount32(n.bitmap&(bit-1))]
+		if s.child == nil {
+			if s.key == key {
+				return s.val, true
+			}
+			return zero, false
+		}
+		n = s.child
+		shift += pmapBits
+	}
+	return zero, false
+}
+
+// set returns a copy of the node with the entry set, and whether the key is
+// new.
+func (n *pnode[V]) set(entry pslot[V], shift uint) (*pnode[V], bool) {
+	if n == nil {
+		n = &pnode[V]{}
+	}
+
+	if shift >= pmapMaxShift {
+		result := &pnode[V]{slots: make([]pslot[V], len(n.slots), len(n.slots)+1)}
+		copy(result.slots, n.slots)
+		for i, s := range result.slots {
+			if s.key == entry.key {
+				result.slots[i] = entry
+				return result, false
+			}
+		}
+		result.slots = append(result.slots, entry)
+		return result, true
+	}
+
+	bit := uint32(1) << ((entry.hash >> shift) & pmapMask)
+	pos := bits.OnesCount32(n.bitmap & (bit - 1))
+
+	if n.bitmap&bit == 0 {
+		result := &pnode[V]{
+			bitmap: n.bitmap | bit,
+			slots:  make([]pslot[V], len(n.slots)+1),
+		}
+		copy(result.slots, n.slots[:pos])
+		result.slots[pos] = entry
+		copy(result.slots[pos+1:], n.slots[pos:])
+		return result, true
+	}
+
+	result := &pnode[V]{
+		bitmap: n.bitmap,
+		slots:  make([]pslot[V], len(n.slots)),
+	}
+	copy(result.slots, n.slots)
+
+	s := n.slots[pos]
+	switch {
+	case s.child != nil:
+		child, added := s.child.set(entry, shift+pmapBits)
+		result.slots[pos] = pslot[V]{child: child}
+		return result, added
+
+	case s.key == entry.key:
+		result.slots[pos] = entry
+		return result, false
+
+	default:
+		// two keys share this slot, so push them both down a level
+		child, _ := (*pnode[V])(nil).set(s, shift+pmapBits)
+		child, _ = child.set(entry, shift+pmapBits)
+		result.slots[pos] = pslot[V]{child: child}
+		return result, true
+	}
+}
+
+// delete returns a copy of the node without the key, and whether the key
+// was found. A nil node is returned if the node becomes empty.
+func (n *pnode[V]) delete(key string, hash uint64, shift uint) (*pnode[V], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	if shift >= pmapMaxShift {
+		for i, s := range n.slots {
+			if s.key == key {
+				return n.without(i, 0), true
+			}
+		}
+		return n, false
+	}
+
+	bit := uint32(1) << ((hash >> shift) & pmapMask)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	pos := bits.OnesCount32(n.bitmap & (bit - 1))
+
+	s := n.slots[pos]
+	if s.child == nil {
+		if s.key != key {
+			return n, false
+		}
+		return n.without(pos, bit), true
+	}
+
+	child, deleted := s.child.delete(key, hash, shift+pmapBits)
+	if !deleted {
+		return n, false
+	}
+	if child == nil {
+		return n.without(pos, bit), true
+	}
+
+	result := &pnode[V]{
+		bitmap: n.bitmap,
+		slots:  make([]pslot[V], len(n.slots)),
+	}
+	copy(result.slots, n.slots)
+	result.slots[pos] = pslot[V]{child: child}
+	return result, true
+}
+
+// without returns a copy of the node without the slot at pos, clearing bit
+// from the bitmap.
+func (n *pnode[V]) without(pos int, bit uint32) *pnode[V] {
+	if len(n.slots) == 1 {
+		return nil
+	}
+
+	result := &pnode[V]{
+		bitmap: n.bitmap &^ bit,
+		slots:  make([]pslot[V], 0, len(n.slots)-1),
+	}
+	result.slots = append(result.slots, n.slots[:pos]...)
+	result.slots = append(result.slots, n.slots[pos+1:]...)
+	return result
+}
+
+func (n *pnode[V]) each(cb func(string, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	for _, s := range n.slots {
+		if s.child != nil {
+			if !s.child.each(cb) {
+				return false
+			}
+			continue
+		}
+		if !cb(s.key, s.val) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package dagg
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestPmap(t *testing.T) {
+	var m pmap[int]
+	versions := make([]pmap[int], 0, 1000)
+	for i := 0; i < 1000; i++ {
+		versions = append(versions, m)
+		m = m.Set(strconv.Itoa(i), i)
+	}
+
+	if m.Len() != 1000 {
+		t.Fatalf("bad len: %d", m.Len())
+	}
+	for i, old := range versions {
+		if old.Len() != i {
+			t.Fatalf("bad len: %d", old.Len())
+		}
+		if _, ok := old.Get(strconv.Itoa(i)); ok {
+			t.Fatalf("old version should not have %d", i)
+		}
+		if v, ok := m.Get(strconv.Itoa(i)); !ok || v != i {
+			t.Fatalf("bad get %d: %d", i, v)
+		}
+	}
+
+	for i := 0; i < 1000; i += 2 {
+		m = m.Delete(strconv.Itoa(i))
+	}
+	if m.Len() != 500 {
+		t.Fatalf("bad len: %d", m.Len())
+	}
+	count := 0
+	m.Each(func(k string, v int) bool {
+		if v%2 == 0 {
+			t.Fatalf("bad entry: %s", k)
+		}
+		count++
+		return true
+	})
+	if count != 500 {
+		t.Fatalf("bad count: %d", count)
+	}
+}
+
+func TestPmap_collision(t *testing.T) {
+	// force every key to the same hash
+	var root *pnode[int]
+	for i := 0; i < 3; i++ {
+		root, _ = root.set(pslot[int]{hash: 42, key: strconv.Itoa(i), val: i}, 0)
+	}
+
+	for i := 0; i < 3; i++ {
+		if v, ok := root.get(strconv.Itoa(i), 42, 0); !ok || v != i {
+			t.Fatalf("bad get %d: %d", i, v)
+		}
+	}
+
+	root, deleted := root.delete("1", 42, 0)
+	if !deleted {
+		t.Fatal("should delete")
+	}
+	if _, ok := root.get("1", 42, 0); ok {
+		t.Fatal("should not get deleted key")
+	}
+	if v, ok := root.get("2", 42, 0); !ok || v != 2 {
+		t.Fatalf("bad get: %d", v)
+	}
+}
@@ -0,0 +1,51 @@
+package dagg
+
+import "fmt"
+
+// VertexPolicy is how Connect treats the vertices of an edge which haven't
+// been added to the graph, as set by SetVertexPolicy.
+type VertexPolicy int
+
+const (
+	// AutoAddVertices adds the missing vertices to the graph, so that
+	// every vertex of an edge is also returned by Vertices. This is the
+	// default.
+	AutoAddVertices VertexPolicy = iota
+
+	// StrictVertices rejects the edge, and Connect returns an error.
+	StrictVertices
+
+	// AllowDanglingEdges adds the edge without its missing vertices, which
+	// are then only found through the edge. See DanglingEdges.
+	AllowDanglingEdges
+)
+
+// SetVertexPolicy sets how Connect treats vertices which aren't in the
+// graph. Copies and snapshots of the graph keep the policy.
+func (g *Graph[T]) SetVertexPolicy(p VertexPolicy) {
+	g.policy = p
+}
+
+// VertexPolicy returns the graph's VertexPolicy.
+func (g *Graph[T]) VertexPolicy() VertexPolicy {
+	return g.policy
+}
+
+// checkVertices applies the VertexPolicy to the vertices of an edge before
+// it is connected.
+func (g *Graph[T]) checkVertices(edge Edge[T]) error {
+	if g.policy == AllowDanglingEdges {
+		return nil
+	}
+
+	for _, v := range []T{edge.Source(), edge.Target()} {
+		if g.HasVertex(v) {
+			continue
+		}
+		if g.policy == StrictVertices {
+			return fmt.Errorf("vertex %q not found", VertexName(v))
+		}
+		g.Add(v)
+	}
+	return nil
+}
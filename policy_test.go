@@ -0,0 +1,67 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphConnect_autoAddVertices(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	if err := g.Connect(BasicEdge(myint(1), myint(2))); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !g.HasVertex(myint(2)) {
+		t.Fatal("should add the target")
+	}
+	if len(g.DanglingEdges()) != 0 {
+		t.Fatalf("bad: %v", g.DanglingEdges())
+	}
+}
+
+func TestGraphConnect_strictVertices(t *testing.T) {
+	var g Graph[myint]
+	g.SetVertexPolicy(StrictVertices)
+	g.Add(myint(1))
+
+	err := g.Connect(BasicEdge(myint(1), myint(2)))
+	if err == nil || err.Error() != `vertex "2" not found` {
+		t.Fatalf("bad: %v", err)
+	}
+	if g.HasVertex(myint(2)) || len(g.Edges()) != 0 {
+		t.Fatal("should not change the graph")
+	}
+
+	g.Add(myint(2))
+	if err := g.Connect(BasicEdge(myint(1), myint(2))); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// the policy applies inside a transaction too
+	var dag AcyclicGraph[myint]
+	dag.SetVertexPolicy(StrictVertices)
+	err = dag.Apply(func(tx *GraphTx[myint]) error {
+		return tx.Connect(BasicEdge(myint(1), myint(2)))
+	})
+	if err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestGraphConnect_allowDanglingEdges(t *testing.T) {
+	var g Graph[myint]
+	g.SetVertexPolicy(AllowDanglingEdges)
+	g.Add(myint(1))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+
+	if g.HasVertex(myint(2)) {
+		t.Fatal("should not add the target")
+	}
+	if actual := strings.TrimSpace(g.String()); actual != "1\n  2" {
+		t.Fatalf("bad: %s", actual)
+	}
+	if g.Copy().VertexPolicy() != AllowDanglingEdges {
+		t.Fatal("copies should keep the policy")
+	}
+}
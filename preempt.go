@@ -0,0 +1,32 @@
+package dagg
+
+// PreemptionPolicy decides when a running vertex is asked to yield to a
+// waiting vertex with a higher priority. Preemption is cooperative: the
+// running vertex is only asked, by cancelling its context, and it's up to
+// the vertex to give way. So only the vertices run with a context, by the
+// HeartbeatCallback of a Walker without Executors or as ExternalVertex
+// polls, can be preempted. Vertices run by the Callback or an Executor
+// always run to completion. See Walker.Preemption.
+type PreemptionPolicy struct {
+	// MinGap is how much higher the priority of the waiting vertex must be
+	// than that of the running vertex. It defaults to 1.
+	MinGap int
+
+	// MaxPreemptions, if greater than zero, is the number of times a vertex
+	// may be preempted, after which it runs to completion.
+	MaxPreemptions int
+}
+
+// allows returns true if a running vertex with the given priority, which has
+// already been preempted the given number of times, should yield to a
+// waiting vertex with the priority waiting.
+func (p *PreemptionPolicy) allows(running, waiting, preemptions int) bool {
+	if p.MaxPreemptions > 0 && preemptions >= p.MaxPreemptions {
+		return false
+	}
+	gap := p.MinGap
+	if gap < 1 {
+		gap = 1
+	}
+	return waiting >= running+gap
+}
@@ -0,0 +1,28 @@
+package dagg
+
+import (
+	"testing"
+)
+
+func TestPreemptionPolicy(t *testing.T) {
+	cases := []struct {
+		Policy                        PreemptionPolicy
+		Running, Waiting, Preemptions int
+		Expected                      bool
+	}{
+		{PreemptionPolicy{}, 0, 1, 0, true},
+		{PreemptionPolicy{}, 1, 1, 0, false},
+		{PreemptionPolicy{}, 2, 1, 0, false},
+		{PreemptionPolicy{MinGap: 5}, 0, 4, 0, false},
+		{PreemptionPolicy{MinGap: 5}, 0, 5, 0, true},
+		{PreemptionPolicy{MaxPreemptions: 2}, 0, 1, 1, true},
+		{PreemptionPolicy{MaxPreemptions: 2}, 0, 1, 2, false},
+	}
+
+	for i, tc := range cases {
+		actual := tc.Policy.allows(tc.Running, tc.Waiting, tc.Preemptions)
+		if actual != tc.Expected {
+			t.Fatalf("%d: bad: %v", i, actual)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package dagg
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAcyclicGraphExportRanks(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 6; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(6), myint(5)))
+
+	actual, err := g.ExportRanks()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []VertexRank{
+		{Hashcode: "3", TopoRank: 0, Level: 0, Component: 0},
+		{Hashcode: "4", TopoRank: 1, Level: 0, Component: 1},
+		{Hashcode: "5", TopoRank: 2, Level: 0, Component: 2},
+		{Hashcode: "2", TopoRank: 3, Level: 1, Component: 0},
+		{Hashcode: "6", TopoRank: 4, Level: 1, Component: 2},
+		{Hashcode: "1", TopoRank: 5, Level: 2, Component: 0},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	g.Connect(BasicEdge(myint(3), myint(1)))
+	if _, err := g.ExportRanks(); err == nil {
+		t.Fatal("should error on a cycle")
+	}
+}
+
+func TestAcyclicGraphWriteRanksCSV(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+
+	var buf bytes.Buffer
+	if err := g.WriteRanksCSV(&buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(buf.String())
+	expected := strings.TrimSpace(testAcyclicGraphWriteRanksCSVStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+const testAcyclicGraphWriteRanksCSVStr = `
+hashcode,topo_rank,level,component
+2,0,0,0
+1,1,1,0
+`
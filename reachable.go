@@ -0,0 +1,67 @@
+package dagg
+
+import (
+	"fmt"
+)
+
+// Reachable returns true if to can be reached from from by following the
+// edges of the graph. The search stops as soon as to is found.
+//
+// Complexity: O(V+E)
+func (g *AcyclicGraph[T]) Reachable(from, to T) (bool, error) {
+	for _, v := range []T{from, to} {
+		if !g.HasVertex(v) {
+			return false, fmt.Errorf("vertex %q not found", VertexName(v))
+		}
+	}
+
+	target := to.Hashcode()
+	seen := make(map[string]struct{})
+	frontier := g.downEdgesNoCopy(from).List()
+	for len(frontier) > 0 {
+		n := len(frontier)
+		current := frontier[n-1]
+		frontier = frontier[:n-1]
+
+		code := current.Hashcode()
+		if code == target {
+			return true, nil
+		}
+		if _, ok := seen[code]; ok {
+			continue
+		}
+		seen[code] = struct{}{}
+
+		for _, v := range g.downEdgesNoCopy(current) {
+			frontier = append(frontier, v)
+		}
+	}
+
+	return false, nil
+}
+
+// ReachableFrom returns every vertex that can be reached from v by following
+// the edges of the graph. v itself is only included if it is part of a
+// cycle.
+//
+// Complexity: O(V+E)
+func (g *AcyclicGraph[T]) ReachableFrom(v T) Set[T] {
+	s := make(Set[T])
+	frontier := g.downEdgesNoCopy(v).List()
+	for len(frontier) > 0 {
+		n := len(frontier)
+		current := frontier[n-1]
+		frontier = frontier[:n-1]
+
+		if s.Include(current) {
+			continue
+		}
+		s.Add(current)
+
+		for _, next := range g.downEdgesNoCopy(current) {
+			frontier = append(frontier, next)
+		}
+	}
+
+	return s
+}
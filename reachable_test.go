@@ -0,0 +1,61 @@
+package dagg
+
+import (
+	"testing"
+)
+
+func TestAcyclicGraphReachable(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(4), myint(3)))
+
+	cases := []struct {
+		From, To myint
+		Expected bool
+	}{
+		{1, 3, true},
+		{1, 2, true},
+		{3, 1, false},
+		{1, 4, false},
+		{1, 1, false},
+	}
+
+	for _, tc := range cases {
+		actual, err := g.Reachable(tc.From, tc.To)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if actual != tc.Expected {
+			t.Fatalf("%d -> %d: expected %t", tc.From, tc.To, tc.Expected)
+		}
+	}
+
+	if _, err := g.Reachable(myint(1), myint(9)); err == nil {
+		t.Fatal("should error on missing vertex")
+	}
+}
+
+func TestAcyclicGraphReachableFrom(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(4), myint(3)))
+
+	actual := g.ReachableFrom(myint(1))
+	if actual.Len() != 2 || !actual.Include(myint(2)) || !actual.Include(myint(3)) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if actual := g.ReachableFrom(myint(3)); actual.Len() != 0 {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
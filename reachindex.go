@@ -0,0 +1,143 @@
+package dagg
+
+import (
+	"fmt"
+	"math/bits"
+	"sync"
+)
+
+// ReachabilityIndex answers reachability queries on a graph from bitsets of
+// the ancestors and descendents of every vertex, for callers which query
+// the same graph many times between changes. The bitsets are built on the
+// first query, and built again on the first query after the graph is
+// mutated, which the index learns of through the graph's mutation hooks.
+//
+// Queries can be made concurrently with each other, but not with mutations
+// of the graph.
+type ReachabilityIndex[T Hashable] struct {
+	g    *AcyclicGraph[T]
+	hook *mutationHook[T]
+
+	lock     sync.Mutex
+	stale    bool
+	idx      *graphIndex[T]
+	down, up [][]uint64
+}
+
+// ReachabilityIndex returns a ReachabilityIndex of the graph. Close should
+// be called once the index is no longer needed, so the graph stops
+// notifying it.
+//
+// Complexity: O(V*(V+E)/64) to build, and O(V^2/32) space
+func (g *AcyclicGraph[T]) ReachabilityIndex() *ReachabilityIndex[T] {
+	ri := &ReachabilityIndex[T]{g: g, stale: true}
+	ri.hook = g.addHook(func(Mutation[T]) {
+		ri.lock.Lock()
+		defer ri.lock.Unlock()
+		ri.stale = true
+	})
+	return ri
+}
+
+// Close stops the graph notifying the index of mutations. The index must
+// not be used afterwards.
+func (ri *ReachabilityIndex[T]) Close() {
+	if ri.hook != nil {
+		ri.g.removeHook(ri.hook)
+		ri.hook = nil
+	}
+}
+
+// build builds the bitsets if the graph has changed since they were last
+// built. The lock must be held.
+func (ri *ReachabilityIndex[T]) build() error {
+	if !ri.stale {
+		return nil
+	}
+
+	idx := ri.g.index()
+	order, ok := idx.topological()
+	if !ok {
+		return fmt.Errorf("graph contains a cycle")
+	}
+
+	// the targets of a vertex come after it in the order, and its sources
+	// before it
+	reverse := make([]int, len(order))
+	for i, u := range order {
+		reverse[len(order)-1-i] = u
+	}
+	ri.idx = idx
+	ri.down = reachRows(reverse, idx.down)
+	ri.up = reachRows(order, idx.up)
+	ri.stale = false
+	return nil
+}
+
+// id returns the index of v. The lock must be held, and the bitsets built.
+func (ri *ReachabilityIndex[T]) id(v T) (int, error) {
+	i, ok := ri.idx.ids[v.Hashcode()]
+	if !ok {
+		return 0, fmt.Errorf("vertex %q not found", VertexName(v))
+	}
+	return i, nil
+}
+
+// Reaches returns true if b can be reached from a by following the edges
+// of the graph, as Reachable.
+//
+// Complexity: O(1) once built
+func (ri *ReachabilityIndex[T]) Reaches(a, b T) (bool, error) {
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+	if err := ri.build(); err != nil {
+		return false, err
+	}
+
+	i, err := ri.id(a)
+	if err != nil {
+		return false, err
+	}
+	j, err := ri.id(b)
+	if err != nil {
+		return false, err
+	}
+	return ri.down[i][j/64]&(1<<(uint(j)%64)) != 0, nil
+}
+
+// AncestorsFast returns the same vertices as Ancestors, from the index.
+//
+// Complexity: O(V/64 + k) once built, for k ancestors
+func (ri *ReachabilityIndex[T]) AncestorsFast(v T) (Set[T], error) {
+	return ri.members(v, func() [][]uint64 { return ri.up })
+}
+
+// DescendentsFast returns the same vertices as Descendents, from the index.
+//
+// Complexity: O(V/64 + k) once built, for k descendents
+func (ri *ReachabilityIndex[T]) DescendentsFast(v T) (Set[T], error) {
+	return ri.members(v, func() [][]uint64 { return ri.down })
+}
+
+// members returns the vertices in the row of v of the bitsets returned by
+// rows, which is called once they're built.
+func (ri *ReachabilityIndex[T]) members(v T, rows func() [][]uint64) (Set[T], error) {
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+	if err := ri.build(); err != nil {
+		return nil, err
+	}
+
+	i, err := ri.id(v)
+	if err != nil {
+		return nil, err
+	}
+	s := make(Set[T])
+	for w, word := range rows()[i] {
+		for word != 0 {
+			s.Add(ri.idx.vertices[w*64+bits.TrailingZeros64(word)])
+			word &= word - 1
+		}
+	}
+	return s, nil
+}
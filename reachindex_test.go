@@ -0,0 +1,75 @@
+package dagg
+
+import (
+	"testing"
+)
+
+func TestReachabilityIndex(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 6; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(1), myint(4)))
+	g.Connect(BasicEdge(myint(5), myint(3)))
+
+	ri := g.ReachabilityIndex()
+	defer ri.Close()
+
+	check := func() {
+		t.Helper()
+		for _, v := range g.Vertices() {
+			expected, _ := g.Ancestors(v)
+			actual, err := ri.AncestorsFast(v)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if actual.Len() != expected.Len() || expected.Difference(actual).Len() != 0 {
+				t.Fatalf("ancestors of %d: bad: %#v", v, actual)
+			}
+
+			expected, _ = g.Descendents(v)
+			actual, err = ri.DescendentsFast(v)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if actual.Len() != expected.Len() || expected.Difference(actual).Len() != 0 {
+				t.Fatalf("descendents of %d: bad: %#v", v, actual)
+			}
+
+			for _, u := range g.Vertices() {
+				expected, _ := g.Reachable(v, u)
+				actual, err := ri.Reaches(v, u)
+				if err != nil {
+					t.Fatalf("err: %s", err)
+				}
+				if actual != expected {
+					t.Fatalf("%d reaches %d: bad: %v", v, u, actual)
+				}
+			}
+		}
+	}
+	check()
+
+	if ok, _ := ri.Reaches(myint(1), myint(3)); !ok {
+		t.Fatal("1 should reach 3")
+	}
+
+	// mutations invalidate the index
+	g.Connect(BasicEdge(myint(3), myint(6)))
+	g.Remove(myint(2))
+	check()
+	if ok, _ := ri.Reaches(myint(1), myint(3)); ok {
+		t.Fatal("1 shouldn't reach 3")
+	}
+
+	if _, err := ri.AncestorsFast(myint(2)); err == nil {
+		t.Fatal("expect error")
+	}
+
+	ri.Close()
+	if len(g.hooks) != 0 {
+		t.Fatalf("bad: %d hooks", len(g.hooks))
+	}
+}
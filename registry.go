@@ -0,0 +1,215 @@
+package dagg
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Registry manages a set of named graphs. Each graph has its own lock, so
+// callers working on different graphs never block each other.
+//
+// The zero value is an empty Registry ready to use.
+type Registry[T Hashable] struct {
+	// TTL is how long a graph may go unused before Evict removes it. A TTL of
+	// zero disables eviction.
+	TTL time.Duration
+
+	lock    sync.Mutex
+	entries map[string]*registryEntry[T]
+	metrics RegistryMetrics
+
+	// now is used to get the current time, and can be replaced for tests.
+	now func() time.Time
+}
+
+// RegistryMetrics are the counters kept by a Registry.
+type RegistryMetrics struct {
+	// Graphs is the number of graphs currently in the registry.
+	Graphs int
+
+	Creates   uint64
+	Deletes   uint64
+	Evictions uint64
+
+	// Hits and Misses count lookups of graphs by name.
+	Hits   uint64
+	Misses uint64
+}
+
+type registryEntry[T Hashable] struct {
+	// lock guards the graph itself.
+	lock  sync.RWMutex
+	graph *AcyclicGraph[T]
+
+	// lastUsed and users, the number of Views and Updates of the graph in
+	// progress, are guarded by the Registry lock.
+	lastUsed time.Time
+	users    int
+}
+
+func (r *Registry[T]) init() {
+	if r.entries == nil {
+		r.entries = make(map[string]*registryEntry[T])
+	}
+	if r.now == nil {
+		r.now = time.Now
+	}
+}
+
+// Create adds a new empty graph with the given name, returning an error if
+// the name is already in use.
+func (r *Registry[T]) Create(name string) (*AcyclicGraph[T], error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.init()
+
+	if _, ok := r.entries[name]; ok {
+		return nil, fmt.Errorf("graph %q already exists", name)
+	}
+
+	e := &registryEntry[T]{
+		graph:    &AcyclicGraph[T]{},
+		lastUsed: r.now(),
+	}
+	r.entries[name] = e
+	r.metrics.Creates++
+
+	return e.graph, nil
+}
+
+// Get returns the named graph. The graph is not locked, so callers that may
+// race with other users of the graph should use View or Update instead.
+func (r *Registry[T]) Get(name string) (*AcyclicGraph[T], bool) {
+	e, ok := r.entry(name, false)
+	if !ok {
+		return nil, false
+	}
+	return e.graph, true
+}
+
+// Delete removes the named graph, returning false if it did not exist.
+func (r *Registry[T]) Delete(name string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.init()
+
+	if _, ok := r.entries[name]; !ok {
+		return false
+	}
+	delete(r.entries, name)
+	r.metrics.Deletes++
+
+	return true
+}
+
+// View calls fn with the named graph while holding its read lock.
+func (r *Registry[T]) View(name string, fn func(*AcyclicGraph[T]) error) error {
+	e, ok := r.entry(name, true)
+	if !ok {
+		return fmt.Errorf("graph %q not found", name)
+	}
+	defer r.release(e)
+
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return fn(e.graph)
+}
+
+// Update calls fn with the named graph while holding its write lock.
+func (r *Registry[T]) Update(name string, fn func(*AcyclicGraph[T]) error) error {
+	e, ok := r.entry(name, true)
+	if !ok {
+		return fmt.Errorf("graph %q not found", name)
+	}
+	defer r.release(e)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return fn(e.graph)
+}
+
+// Names returns the sorted names of all graphs in the registry.
+func (r *Registry[T]) Names() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Evict removes every graph that has not been used within the TTL, and
+// returns the names of the evicted graphs. A graph is in use until its
+// Views and Updates return, so a graph with one in progress is never
+// evicted. Evict is never called
+// automatically; callers are expected to run it periodically.
+func (r *Registry[T]) Evict() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.init()
+
+	if r.TTL <= 0 {
+		return nil
+	}
+
+	var evicted []string
+	deadline := r.now().Add(-r.TTL)
+	for name, e := range r.entries {
+		if e.users == 0 && e.lastUsed.Before(deadline) {
+			delete(r.entries, name)
+			evicted = append(evicted, name)
+		}
+	}
+	sort.Strings(evicted)
+	r.metrics.Evictions += uint64(len(evicted))
+
+	return evicted
+}
+
+// Metrics returns a snapshot of the registry counters.
+func (r *Registry[T]) Metrics() RegistryMetrics {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	m := r.metrics
+	m.Graphs = len(r.entries)
+	return m
+}
+
+// entry looks up the named entry, recording the lookup and refreshing the
+// entry's last use. If use is true, the entry is in use until release is
+// called.
+func (r *Registry[T]) entry(name string, use bool) (*registryEntry[T], bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.init()
+
+	e, ok := r.entries[name]
+	if !ok {
+		r.metrics.Misses++
+		return nil, false
+	}
+	r.metrics.Hits++
+	e.lastUsed = r.now()
+	if use {
+		e.users++
+	}
+
+	return e, true
+}
+
+// release records that a use of the entry has finished, refreshing its last
+// use.
+func (r *Registry[T]) release(e *registryEntry[T]) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	e.users--
+	e.lastUsed = r.now()
+}
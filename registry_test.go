@@ -0,0 +1,104 @@
+package dagg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	var r Registry[myint]
+
+	g, err := r.Create("a")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	g.Add(myint(1))
+
+	if _, err := r.Create("a"); err == nil {
+		t.Fatal("should error on duplicate name")
+	}
+
+	err = r.Update("a", func(g *AcyclicGraph[myint]) error {
+		g.Add(myint(2))
+		g.Connect(BasicEdge(myint(1), myint(2)))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	got, ok := r.Get("a")
+	if !ok {
+		t.Fatal("should have graph a")
+	}
+	if !got.HasEdge(BasicEdge(myint(1), myint(2))) {
+		t.Fatalf("bad: %s", got)
+	}
+
+	if _, ok := r.Get("b"); ok {
+		t.Fatal("should not have graph b")
+	}
+	if err := r.View("b", func(*AcyclicGraph[myint]) error { return nil }); err == nil {
+		t.Fatal("should error on unknown graph")
+	}
+
+	if !r.Delete("a") {
+		t.Fatal("should delete graph a")
+	}
+	if r.Delete("a") {
+		t.Fatal("should not delete graph a twice")
+	}
+
+	expected := RegistryMetrics{
+		Graphs:  0,
+		Creates: 1,
+		Deletes: 1,
+		Hits:    2,
+		Misses:  2,
+	}
+	if m := r.Metrics(); !reflect.DeepEqual(m, expected) {
+		t.Fatalf("bad metrics: %#v", m)
+	}
+}
+
+func TestRegistryEvict(t *testing.T) {
+	now := time.Unix(0, 0)
+	r := &Registry[myint]{
+		TTL: time.Minute,
+		now: func() time.Time { return now },
+	}
+
+	r.Create("a")
+	r.Create("b")
+
+	now = now.Add(45 * time.Second)
+	r.Get("b")
+
+	now = now.Add(30 * time.Second)
+	evicted := r.Evict()
+	if !reflect.DeepEqual(evicted, []string{"a"}) {
+		t.Fatalf("bad: %#v", evicted)
+	}
+	if names := r.Names(); !reflect.DeepEqual(names, []string{"b"}) {
+		t.Fatalf("bad: %#v", names)
+	}
+	if m := r.Metrics(); m.Evictions != 1 || m.Graphs != 1 {
+		t.Fatalf("bad metrics: %#v", m)
+	}
+
+	// a graph isn't evicted during a long Update, nor just after it
+	err := r.Update("b", func(*AcyclicGraph[myint]) error {
+		now = now.Add(2 * time.Minute)
+		if evicted := r.Evict(); len(evicted) != 0 {
+			t.Fatalf("bad: %#v", evicted)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if evicted := r.Evict(); len(evicted) != 0 {
+		t.Fatalf("bad: %#v", evicted)
+	}
+}
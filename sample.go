@@ -0,0 +1,55 @@
+package dagg
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// SampleStrategy selects how Sample picks the vertices of a sample.
+type SampleStrategy int
+
+const (
+	// SampleRandom picks n vertices at random.
+	SampleRandom SampleStrategy = iota
+
+	// SampleTopDegree picks the n vertices with the most edges, along with
+	// all of their neighbors.
+	SampleTopDegree
+)
+
+// Sample returns a small subgraph of g for inspection, containing the
+// vertices picked by the strategy and all edges between them.
+func (g *Graph[T]) Sample(n int, strategy SampleStrategy) *Graph[T] {
+	vertices := g.SortedVertices()
+	picked := make(Set[T])
+
+	switch strategy {
+	case SampleRandom:
+		rand.Shuffle(len(vertices), func(i, j int) {
+			vertices[i], vertices[j] = vertices[j], vertices[i]
+		})
+		for i := 0; i < n && i < len(vertices); i++ {
+			picked.Add(vertices[i])
+		}
+
+	case SampleTopDegree:
+		degree := make(map[string]int, len(vertices))
+		for _, v := range vertices {
+			degree[v.Hashcode()] = g.Neighbors(v).Len()
+		}
+		sort.SliceStable(vertices, func(i, j int) bool {
+			return degree[vertices[i].Hashcode()] > degree[vertices[j].Hashcode()]
+		})
+
+		for i := 0; i < n && i < len(vertices); i++ {
+			picked.Add(vertices[i])
+			for _, neighbor := range g.Neighbors(vertices[i]) {
+				picked.Add(neighbor)
+			}
+		}
+	}
+
+	return g.Filter(func(v T) bool {
+		return picked.Include(v)
+	})
+}
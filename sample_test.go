@@ -0,0 +1,57 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphSample_random(t *testing.T) {
+	var g Graph[myint]
+	for i := 0; i < 100; i++ {
+		g.Add(myint(i))
+		if i > 0 {
+			g.Connect(BasicEdge(myint(i-1), myint(i)))
+		}
+	}
+
+	s := g.Sample(10, SampleRandom)
+	if len(s.Vertices()) != 10 {
+		t.Fatalf("bad: %s", s)
+	}
+	for _, e := range s.Edges() {
+		if !s.HasVertex(e.Source()) || !s.HasVertex(e.Target()) {
+			t.Fatalf("edge not induced by sample: %s", e.Hashcode())
+		}
+	}
+
+	if s := g.Sample(1000, SampleRandom); len(s.Vertices()) != 100 {
+		t.Fatalf("bad: %d vertices", len(s.Vertices()))
+	}
+}
+
+func TestGraphSample_topDegree(t *testing.T) {
+	var g Graph[myint]
+	for i := 1; i <= 6; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(4), myint(1)))
+	g.Connect(BasicEdge(myint(5), myint(6)))
+
+	actual := strings.TrimSpace(g.Sample(1, SampleTopDegree).String())
+	expected := strings.TrimSpace(testGraphSampleTopDegreeStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+const testGraphSampleTopDegreeStr = `
+1
+  2
+  3
+2
+3
+4
+  1
+`
@@ -0,0 +1,232 @@
+package dagg
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Table is a vertex of a schema graph, named by the table.
+type Table string
+
+func (t Table) Hashcode() string {
+	return string(t)
+}
+
+// LoadForeignKeys builds a schema graph from the foreign keys listed in
+// information_schema, such as the tab separated output of
+//
+//	SELECT table_name, referenced_table_name
+//	FROM information_schema.key_column_usage
+//	WHERE referenced_table_name IS NOT NULL
+//
+// The input is tab or comma separated, and must start with a header row
+// naming the table_name column and the referenced_table_name column, or
+// foreign_table_name as it's called in PostgreSQL. Other columns are
+// ignored.
+//
+// Every table has an edge to each table it references, so the
+// TopologicalGenerations of the graph are in order of creation, and Walk
+// visits tables in the same order. A table referencing itself doesn't
+// affect the order, so no edge is added for it. The resulting graph is
+// validated before it is returned.
+func LoadForeignKeys(r io.Reader) (*AcyclicGraph[Table], error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// the separator is taken from the header row
+	text := string(src)
+	cr := csv.NewReader(strings.NewReader(text))
+	if header, _, _ := strings.Cut(text, "\n"); strings.Contains(header, "\t") {
+		cr.Comma = '\t'
+	}
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("no header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tableCol, refCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "table_name":
+			tableCol = i
+		case "referenced_table_name", "foreign_table_name":
+			refCol = i
+		}
+	}
+	if tableCol < 0 || refCol < 0 {
+		return nil, fmt.Errorf("header must name the table_name and referenced_table_name columns")
+	}
+
+	g := &AcyclicGraph[Table]{}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(row) <= tableCol || len(row) <= refCol {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("line %d: expected %d columns, found %d", line, len(header), len(row))
+		}
+
+		table := Table(strings.TrimSpace(row[tableCol]))
+		ref := strings.TrimSpace(row[refCol])
+		if table == "" {
+			continue
+		}
+		g.Add(table)
+		// unreferenced tables may be listed with a NULL reference
+		if ref == "" || strings.EqualFold(ref, "NULL") {
+			continue
+		}
+		g.Add(Table(ref))
+		if Table(ref) != table {
+			g.Connect(BasicEdge(table, Table(ref)))
+		}
+	}
+
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+var (
+	sqlCreateTable = regexp.MustCompile(`(?is)^\s*create\s+(?:(?:global\s+|local\s+)?(?:temporary|temp)\s+|unlogged\s+)?table\s+(?:if\s+not\s+exists\s+)?(` + sqlIdent + `)`)
+	sqlAlterTable  = regexp.MustCompile(`(?is)^\s*alter\s+table\s+(?:if\s+exists\s+)?(?:only\s+)?(` + sqlIdent + `)`)
+	sqlReferences  = regexp.MustCompile(`(?is)\breferences\s+(` + sqlIdent + `)`)
+)
+
+// sqlIdent matches a possibly qualified and quoted SQL identifier.
+const sqlIdent = "(?:(?:\"[^\"]+\"|`[^`]+`|\\[[^\\]]+\\]|[\\w$]+)\\.)*(?:\"[^\"]+\"|`[^`]+`|\\[[^\\]]+\\]|[\\w$]+)"
+
+// ParseSchema builds a schema graph from SQL migrations, using the
+// REFERENCES clauses of the CREATE TABLE and ALTER TABLE statements. Other
+// statements are ignored. Quoted identifiers are unquoted, and unquoted
+// identifiers are lower cased, as SQL compares them without regard to case.
+//
+// The graph is built and validated as by LoadForeignKeys.
+func ParseSchema(r io.Reader) (*AcyclicGraph[Table], error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &AcyclicGraph[Table]{}
+	for _, stmt := range splitSQL(string(src)) {
+		m := sqlCreateTable.FindStringSubmatch(stmt)
+		if m == nil {
+			m = sqlAlterTable.FindStringSubmatch(stmt)
+		}
+		if m == nil {
+			continue
+		}
+
+		table := sqlTableName(m[1])
+		g.Add(table)
+		for _, ref := range sqlReferences.FindAllStringSubmatch(stmt[len(m[0]):], -1) {
+			target := sqlTableName(ref[1])
+			g.Add(target)
+			if target != table {
+				g.Connect(BasicEdge(table, target))
+			}
+		}
+	}
+
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// splitSQL splits src into statements, removing comments and the contents
+// of string literals. Semicolons in string literals and quoted identifiers
+// don't end a statement.
+func splitSQL(src string) []string {
+	var stmts []string
+	var b strings.Builder
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case strings.HasPrefix(src[i:], "--"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			b.WriteByte(' ')
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				i = len(src)
+			} else {
+				i += end + 3
+			}
+			b.WriteByte(' ')
+
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				end = len(src) - i - 1
+			}
+			if c == '\'' {
+				// string literals never name a table, so their contents
+				// are dropped.
+				b.WriteString("''")
+			} else {
+				b.WriteString(src[i:min(i+end+2, len(src))])
+			}
+			i += end + 1
+
+		case c == ';':
+			stmts = append(stmts, b.String())
+			b.Reset()
+
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if strings.TrimSpace(b.String()) != "" {
+		stmts = append(stmts, b.String())
+	}
+	return stmts
+}
+
+// sqlTableName unquotes each part of a qualified identifier, lower casing
+// the unquoted parts.
+func sqlTableName(ident string) Table {
+	var parts []string
+	for len(ident) > 0 {
+		var part string
+		switch ident[0] {
+		case '"', '`', '[':
+			closing := ident[0]
+			if closing == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(ident[1:], closing) + 1
+			part, ident = ident[1:end], ident[end+1:]
+		default:
+			end := strings.IndexByte(ident, '.')
+			if end < 0 {
+				end = len(ident)
+			}
+			part, ident = strings.ToLower(ident[:end]), ident[end:]
+		}
+		parts = append(parts, part)
+		ident = strings.TrimPrefix(ident, ".")
+	}
+	return Table(strings.Join(parts, "."))
+}
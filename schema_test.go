@@ -0,0 +1,137 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadForeignKeys(t *testing.T) {
+	g, err := LoadForeignKeys(strings.NewReader(testForeignKeysStr))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testSchemaGraphStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestLoadForeignKeys_csv(t *testing.T) {
+	src := "constraint_name,table_name,foreign_table_name\n" +
+		"fk_1,orders,users\n" +
+		"fk_2,order_items,orders\n"
+	g, err := LoadForeignKeys(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	gens, err := g.TopologicalGenerations()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(gens) != 3 || gens[0][0] != "users" || gens[2][0] != "order_items" {
+		t.Fatalf("bad: %v", gens)
+	}
+}
+
+func TestLoadForeignKeys_invalid(t *testing.T) {
+	cases := map[string]string{
+		"empty":   "",
+		"header":  "a\tb\n",
+		"columns": "table_name\treferenced_table_name\norders\n",
+		"cycle":   "table_name\treferenced_table_name\na\tb\nb\ta\n",
+	}
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadForeignKeys(strings.NewReader(src)); err == nil {
+				t.Fatal("should error")
+			}
+		})
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	g, err := ParseSchema(strings.NewReader(testSchemaSQL))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testSchemaGraphStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestParseSchema_quoted(t *testing.T) {
+	src := "CREATE TABLE \"App\".\"Users\" (id int);\n" +
+		"CREATE TABLE `posts` (author int REFERENCES \"App\".\"Users\" (id), note text DEFAULT 'a; references x');\n" +
+		"CREATE TABLE [Tags] (post int REFERENCES Posts(id));"
+	g, err := ParseSchema(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := "App.Users\nTags\n  posts\nposts\n  App.Users"
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+const testForeignKeysStr = `TABLE_NAME	COLUMN_NAME	REFERENCED_TABLE_NAME
+orders	user_id	users
+orders	address_id	addresses
+order_items	order_id	orders
+order_items	product_id	products
+users	manager_id	users
+addresses	user_id	users
+products	NULL	NULL
+`
+
+const testSchemaSQL = `
+-- the users come first
+CREATE TABLE users (
+	id serial PRIMARY KEY,
+	manager_id int REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS addresses (
+	id serial PRIMARY KEY,
+	user_id int NOT NULL REFERENCES users(id)
+);
+
+CREATE TABLE products (id serial PRIMARY KEY);
+
+/* orders reference addresses; and users */
+CREATE TABLE orders (
+	id serial PRIMARY KEY,
+	user_id int NOT NULL,
+	address_id int,
+	FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE order_items (
+	order_id int REFERENCES orders (id),
+	product_id int
+);
+
+ALTER TABLE orders ADD CONSTRAINT fk_address FOREIGN KEY (address_id) REFERENCES addresses (id);
+ALTER TABLE ONLY order_items ADD FOREIGN KEY (product_id) REFERENCES Products (id);
+CREATE INDEX orders_user ON orders (user_id);
+`
+
+const testSchemaGraphStr = `
+addresses
+  users
+order_items
+  orders
+  products
+orders
+  addresses
+  users
+products
+users
+`
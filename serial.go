@@ -0,0 +1,93 @@
+package dagg
+
+import "sort"
+
+// SerialVertex is a vertex that belongs to a named serial group. The
+// members of a serial group run one at a time, in the order they would be
+// walked, even though there are no edges between them. This keeps ordering
+// constraints such as "these migrations run one at a time" out of the
+// dependency graph.
+//
+// The order is by the depth of each member in the direction of the walk,
+// and then by name. A member waits for the previous member to finish, but
+// not to succeed, so a failed member doesn't skip the rest of its group.
+type SerialVertex interface {
+	SerialGroup() string
+}
+
+// updateSerial orders the members of each serial group in g, recording the
+// member each must wait for. The changeLock must be held.
+func (w *Walker[T]) updateSerial(g *AcyclicGraph[T]) {
+	w.serialPrev = nil
+	if g == nil {
+		return
+	}
+
+	groups := make(map[string][]int)
+	idx := g.index()
+	for i, v := range idx.vertices {
+		var raw interface{}
+		raw = v
+		if sv, ok := raw.(SerialVertex); ok && sv.SerialGroup() != "" {
+			groups[sv.SerialGroup()] = append(groups[sv.SerialGroup()], i)
+		}
+	}
+	if len(groups) == 0 {
+		return
+	}
+
+	// the depth of each vertex in the direction of the walk, which without
+	// Reverse is the direction of the edges
+	depth := make([]int, len(idx.vertices))
+	order, _ := idx.topological()
+	next := idx.down
+	if w.Reverse {
+		next = idx.up
+		for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+			order[i], order[j] = order[j], order[i]
+		}
+	}
+	for _, u := range order {
+		for _, v := range next[u] {
+			if depth[u]+1 > depth[v] {
+				depth[v] = depth[u] + 1
+			}
+		}
+	}
+
+	w.serialPrev = make(map[string]string)
+	for _, members := range groups {
+		sort.Slice(members, func(i, j int) bool {
+			a, b := members[i], members[j]
+			if depth[a] != depth[b] {
+				return depth[a] < depth[b]
+			}
+			return g.nameLess(VertexName(idx.vertices[a]), VertexName(idx.vertices[b]))
+		})
+		for i := 1; i < len(members); i++ {
+			w.serialPrev[idx.vertices[members[i]].Hashcode()] = idx.vertices[members[i-1]].Hashcode()
+		}
+	}
+}
+
+// waitSerial waits until the member of v's serial group before it has
+// finished. The previous member is looked up again once it finishes, in
+// case the walk has been updated in the meantime.
+func (w *Walker[T]) waitSerial(v T) {
+	var waited string
+	for {
+		w.changeLock.Lock()
+		prev, ok := w.serialPrev[v.Hashcode()]
+		var done <-chan struct{}
+		if info, exists := w.vertexMap[prev]; ok && exists {
+			done = info.DoneCh
+		}
+		w.changeLock.Unlock()
+
+		if done == nil || prev == waited {
+			return
+		}
+		<-done
+		waited = prev
+	}
+}
@@ -0,0 +1,81 @@
+package dagg
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type serialVertex struct {
+	name  string
+	group string
+}
+
+func (v *serialVertex) Hashcode() string    { return v.name }
+func (v *serialVertex) SerialGroup() string { return v.group }
+
+func TestWalker_serialGroups(t *testing.T) {
+	var g AcyclicGraph[*serialVertex]
+	m1 := g.Add(&serialVertex{"m1", "migrations"})
+	m2 := g.Add(&serialVertex{"m2", "migrations"})
+	m3 := g.Add(&serialVertex{"m3", "migrations"})
+	setup := g.Add(&serialVertex{"setup", ""})
+	g.Add(&serialVertex{"other", ""})
+
+	// m1 runs after setup, so it comes after the other members
+	g.Connect(BasicEdge(m1, setup))
+
+	var lock sync.Mutex
+	var order []string
+	running, max := 0, 0
+	cb := func(v *serialVertex) error {
+		lock.Lock()
+		order = append(order, v.name)
+		if v.group != "" {
+			running++
+			if running > max {
+				max = running
+			}
+		}
+		lock.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		lock.Lock()
+		if v.group != "" {
+			running--
+		}
+		lock.Unlock()
+
+		// a failed member doesn't stop the rest of the group
+		if v == m2 {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	}
+
+	w := &Walker[*serialVertex]{Callback: cb, Reverse: true}
+	w.Update(&g)
+	if err := w.Wait(); err == nil {
+		t.Fatal("should error")
+	}
+
+	if max != 1 {
+		t.Fatalf("bad: %d members ran at once", max)
+	}
+
+	var members []string
+	for _, name := range order {
+		if name == m1.name || name == m2.name || name == m3.name {
+			members = append(members, name)
+		}
+	}
+	if !reflect.DeepEqual(members, []string{"m2", "m3", "m1"}) {
+		t.Fatalf("bad: %v", order)
+	}
+	if len(order) != 5 {
+		t.Fatalf("bad: %v", order)
+	}
+}
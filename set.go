@@ -1,5 +1,9 @@
 package dagg
 
+import (
+	"sort"
+)
+
 // Set is a set data structure.
 type Set[T Hashable] map[string]T
 
@@ -44,6 +48,31 @@ func (s Set[T]) Intersection(other Set[T]) Set[T] {
 	return result
 }
 
+// Union returns a set with the elements of both s and other.
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	result := make(Set[T], len(s)+len(other))
+	for k, v := range s {
+		result[k] = v
+	}
+	for k, v := range other {
+		result[k] = v
+	}
+	return result
+}
+
+// Equal returns true if s and other have the same elements.
+func (s Set[T]) Equal(other Set[T]) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for k := range s {
+		if _, ok := other[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // Difference returns a set with the elements that s has but
 // other doesn't.
 func (s Set[T]) Difference(other Set[T]) Set[T] {
@@ -94,6 +123,14 @@ func (s Set[T]) List() []T {
 	return r
 }
 
+// Slice returns the set elements sorted by Hashcode, unlike List which
+// returns them in no particular order.
+func (s Set[T]) Slice() []T {
+	r := s.List()
+	sort.Sort(byHashcode[T](r))
+	return r
+}
+
 // Copy returns a shallow copy of the set.
 func (s Set[T]) Copy() Set[T] {
 	c := make(Set[T], len(s))
@@ -129,6 +129,77 @@ func TestSetCopy(t *testing.T) {
 
 }
 
+func TestSetUnion(t *testing.T) {
+	a := make(Set[myint])
+	a.Add(1)
+	a.Add(2)
+	b := make(Set[myint])
+	b.Add(2)
+	b.Add(3)
+
+	u := a.Union(b)
+	if u.Len() != 3 || !u.Include(1) || !u.Include(3) {
+		t.Fatalf("bad: %#v", u.List())
+	}
+	if a.Len() != 2 || b.Len() != 2 {
+		t.Fatal("union should not modify its inputs")
+	}
+
+	var nilSet Set[myint]
+	if !nilSet.Union(a).Equal(a) {
+		t.Fatalf("bad: %#v", nilSet.Union(a).List())
+	}
+}
+
+func TestSetEqual(t *testing.T) {
+	cases := []struct {
+		A, B     []myint
+		Expected bool
+	}{
+		{[]myint{1, 2}, []myint{2, 1}, true},
+		{[]myint{1, 2}, []myint{1, 3}, false},
+		{[]myint{1, 2}, []myint{1}, false},
+		{nil, []myint{}, true},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var one, two Set[myint]
+			if tc.A != nil {
+				one = make(Set[myint])
+			}
+			two = make(Set[myint])
+			for _, v := range tc.A {
+				one.Add(v)
+			}
+			for _, v := range tc.B {
+				two.Add(v)
+			}
+
+			if one.Equal(two) != tc.Expected || two.Equal(one) != tc.Expected {
+				t.Fatalf("bad: %v, %v", one.List(), two.List())
+			}
+		})
+	}
+}
+
+func TestSetSlice(t *testing.T) {
+	s := make(Set[myint])
+	s.Add(3)
+	s.Add(1)
+	s.Add(2)
+
+	actual := fmt.Sprint(s.Slice())
+	if actual != "[1 2 3]" {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	var nilSet Set[myint]
+	if len(nilSet.Slice()) != 0 {
+		t.Fatal("should be empty")
+	}
+}
+
 func makeSet(n int) Set[myint] {
 	ret := make(Set[myint], n)
 	for i := 0; i < n; i++ {
@@ -0,0 +1,142 @@
+package dagg
+
+import (
+	"log"
+	"sort"
+	"time"
+)
+
+// SLOVertex is a vertex with an expected duration. A run of the vertex
+// breaches its SLO if it takes any longer.
+type SLOVertex interface {
+	SLO() time.Duration
+}
+
+// vertexSLO returns the SLO of v, if it has one.
+func vertexSLO[T Hashable](v T) (time.Duration, bool) {
+	var raw interface{}
+	raw = v
+	if sv, ok := raw.(SLOVertex); ok && sv.SLO() > 0 {
+		return sv.SLO(), true
+	}
+	return 0, false
+}
+
+// SLOResult is a single run of a vertex with an SLO.
+type SLOResult struct {
+	// Vertex is the hashcode of the vertex, and Name is its VertexName.
+	Vertex string
+	Name   string
+
+	SLO      time.Duration
+	Duration time.Duration
+}
+
+// Breached returns true if the run took longer than the SLO.
+func (r SLOResult) Breached() bool {
+	return r.Duration > r.SLO
+}
+
+// SLOResults returns the runs of the vertices with an SLO which have
+// finished, sorted by hashcode.
+func (w *Walker[T]) SLOResults() []SLOResult {
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+
+	var result []SLOResult
+	for h, t := range w.timings {
+		slo, ok := vertexSLO(t.v)
+		if !ok || t.end.IsZero() {
+			continue
+		}
+		result = append(result, SLOResult{
+			Vertex:   h,
+			Name:     VertexName(t.v),
+			SLO:      slo,
+			Duration: t.end.Sub(t.start),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Vertex < result[j].Vertex })
+	return result
+}
+
+// SLOResults returns the runs of the vertices with an SLO which have
+// finished, sorted by hashcode. The SLO of each vertex is recorded in the
+// event log when it starts.
+func (l *WalkLog) SLOResults() []SLOResult {
+	var result []SLOResult
+	for id, v := range l.Vertices {
+		if v.SLO <= 0 || v.Finished.IsZero() {
+			continue
+		}
+		result = append(result, SLOResult{
+			Vertex:   id,
+			Name:     v.Name,
+			SLO:      v.SLO,
+			Duration: v.Duration(),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Vertex < result[j].Vertex })
+	return result
+}
+
+// checkSLO logs a warning if v took longer than its SLO.
+func checkSLO[T Hashable](v T, d time.Duration) {
+	if slo, ok := vertexSLO(v); ok && d > slo {
+		log.Printf("[WARN] dagg/walk: %q took %s, breaching its SLO of %s", VertexName(v), d, slo)
+	}
+}
+
+// SLOStats are the SLO statistics of a vertex over many runs.
+type SLOStats struct {
+	DurationStats
+
+	// Breaches is the number of runs that breached the SLO.
+	Breaches int
+}
+
+// BreachRate returns the fraction of runs which breached the SLO.
+func (s *SLOStats) BreachRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Breaches) / float64(s.Runs)
+}
+
+// SLOHistory aggregates the SLO results of many walks, such as the walks
+// replayed from a history of event logs, to find the vertices which are
+// chronically slow. The zero value is ready to use.
+type SLOHistory struct {
+	runs runHistory[SLOStats, *SLOStats]
+}
+
+// Add adds the results of a single walk.
+func (h *SLOHistory) Add(results []SLOResult) {
+	for _, r := range results {
+		s := h.runs.add(r.Vertex, r.Name, r.Duration)
+		if r.Breached() {
+			s.Breaches++
+		}
+	}
+}
+
+// Stats returns the statistics of every vertex, sorted by hashcode.
+func (h *SLOHistory) Stats() []*SLOStats {
+	return h.runs.sorted()
+}
+
+// Chronic returns the statistics of the vertices which have run at least
+// minRuns times and breached their SLO in at least rate of those runs,
+// sorted from the highest breach rate.
+func (h *SLOHistory) Chronic(minRuns int, rate float64) []*SLOStats {
+	var result []*SLOStats
+	for _, s := range h.Stats() {
+		if s.Runs >= minRuns && s.Breaches > 0 && s.BreachRate() >= rate {
+			result = append(result, s)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].BreachRate() > result[j].BreachRate()
+	})
+	return result
+}
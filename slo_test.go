@@ -0,0 +1,134 @@
+package dagg
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type sloVertex struct {
+	name string
+	slo  time.Duration
+	took time.Duration
+}
+
+func (v *sloVertex) Hashcode() string   { return v.name }
+func (v *sloVertex) SLO() time.Duration { return v.slo }
+
+// walkSLOs walks the vertices one after another with a fake clock, where
+// each vertex takes as long as it says.
+func walkSLOs(t *testing.T, vs ...*sloVertex) []SLOResult {
+	var g AcyclicGraph[*sloVertex]
+	for _, v := range vs {
+		g.Add(v)
+	}
+
+	var lock sync.Mutex
+	now := time.Unix(0, 0)
+	w := &Walker[*sloVertex]{
+		Reverse: true,
+		Callback: func(v *sloVertex) error {
+			lock.Lock()
+			defer lock.Unlock()
+			now = now.Add(v.took)
+			return nil
+		},
+		now: func() time.Time {
+			lock.Lock()
+			defer lock.Unlock()
+			return now
+		},
+	}
+	for i := 1; i < len(vs); i++ {
+		g.Connect(BasicEdge(vs[i], vs[i-1]))
+	}
+	w.Update(&g)
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return w.SLOResults()
+}
+
+func TestWalkerSLOResults(t *testing.T) {
+	results := walkSLOs(t,
+		&sloVertex{name: "a", slo: 2 * time.Second, took: 3 * time.Second},
+		&sloVertex{name: "b", slo: 2 * time.Second, took: time.Second},
+	)
+
+	expected := []SLOResult{
+		{Vertex: "a", Name: "a", SLO: 2 * time.Second, Duration: 3 * time.Second},
+		{Vertex: "b", Name: "b", SLO: 2 * time.Second, Duration: time.Second},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("bad: %#v", results)
+	}
+	if !results[0].Breached() || results[1].Breached() {
+		t.Fatalf("bad: %#v", results)
+	}
+}
+
+func TestSLOHistory(t *testing.T) {
+	var h SLOHistory
+	for i := 0; i < 4; i++ {
+		// a always breaches, b breaches every other run, c never does
+		b := time.Second
+		if i%2 == 0 {
+			b = 3 * time.Second
+		}
+		h.Add(walkSLOs(t,
+			&sloVertex{name: "a", slo: time.Second, took: 2 * time.Second},
+			&sloVertex{name: "b", slo: 2 * time.Second, took: b},
+			&sloVertex{name: "c", slo: time.Second, took: time.Second},
+		))
+	}
+
+	stats := h.Stats()
+	if len(stats) != 3 {
+		t.Fatalf("bad: %#v", stats)
+	}
+	b := stats[1]
+	if b.Runs != 4 || b.Breaches != 2 || b.Worst != 3*time.Second || b.Mean() != 2*time.Second {
+		t.Fatalf("bad: %#v", b)
+	}
+
+	var names []string
+	for _, s := range h.Chronic(4, 0.5) {
+		names = append(names, s.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Fatalf("bad: %#v", names)
+	}
+	if chronic := h.Chronic(5, 0); len(chronic) != 0 {
+		t.Fatalf("bad: %#v", chronic)
+	}
+}
+
+func TestWalkLogSLOResults(t *testing.T) {
+	var g AcyclicGraph[*sloVertex]
+	g.Add(&sloVertex{name: "slow", slo: time.Nanosecond})
+	g.Add(&sloVertex{name: "none"})
+
+	var buf bytes.Buffer
+	w := &Walker[*sloVertex]{
+		Callback: func(v *sloVertex) error {
+			time.Sleep(time.Millisecond)
+			return nil
+		},
+		EventLog: &buf,
+	}
+	w.Update(&g)
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	l, err := ReadWalkLog(&buf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	results := l.SLOResults()
+	if len(results) != 1 || results[0].Vertex != "slow" || !results[0].Breached() {
+		t.Fatalf("bad: %#v", results)
+	}
+}
@@ -0,0 +1,76 @@
+package dagg
+
+// GraphStats are summary statistics of a graph, as returned by Stats.
+type GraphStats struct {
+	Vertices int `json:"vertices"`
+
+	// Edges counts every edge, directed or not.
+	Edges int `json:"edges"`
+
+	// MaxInDegree and MaxOutDegree are the most directed edges to and from
+	// any one vertex.
+	MaxInDegree  int `json:"max_in_degree"`
+	MaxOutDegree int `json:"max_out_degree"`
+
+	// Components is the number of connected components, ignoring the
+	// direction of the edges.
+	Components int `json:"components"`
+
+	// LongestPath is the number of edges in the longest directed path, or
+	// -1 if the graph contains a cycle.
+	LongestPath int `json:"longest_path"`
+
+	// Density is the number of directed edges divided by the number
+	// possible between distinct vertices, from 0 to 1. Self-loops make it
+	// possible to exceed 1.
+	Density float64 `json:"density"`
+}
+
+// Stats returns summary statistics of the graph. Edges to vertices which
+// are not in the graph are counted in Edges and the degrees, but otherwise
+// ignored.
+//
+// Complexity: O(V+E)
+func (g *Graph[T]) Stats() GraphStats {
+	stats := GraphStats{
+		Vertices:   len(g.vertices),
+		Edges:      len(g.edges),
+		Components: len(g.Components()),
+	}
+
+	directed := 0
+	for _, v := range g.vertices {
+		in, out := g.upEdgesNoCopy(v).Len(), g.downEdgesNoCopy(v).Len()
+		if in > stats.MaxInDegree {
+			stats.MaxInDegree = in
+		}
+		if out > stats.MaxOutDegree {
+			stats.MaxOutDegree = out
+		}
+		directed += out
+	}
+	if n := len(g.vertices); n > 1 {
+		stats.Density = float64(directed) / float64(n*(n-1))
+	}
+
+	// the longest path ending at each vertex, in topological order
+	idx := g.index()
+	order, ok := idx.topological()
+	if !ok {
+		stats.LongestPath = -1
+		return stats
+	}
+	length := make([]int, len(order))
+	for _, u := range order {
+		for _, v := range idx.down[u] {
+			if length[u]+1 > length[v] {
+				length[v] = length[u] + 1
+			}
+		}
+		if length[u] > stats.LongestPath {
+			stats.LongestPath = length[u]
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,44 @@
+package dagg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraphStats(t *testing.T) {
+	var g Graph[myint]
+	for i := 1; i <= 6; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(1), myint(4)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+	g.Connect(UndirectedEdge(myint(5), myint(6)))
+
+	expected := GraphStats{
+		Vertices:     6,
+		Edges:        6,
+		MaxInDegree:  2,
+		MaxOutDegree: 3,
+		Components:   2,
+		LongestPath:  3,
+		Density:      5.0 / 30,
+	}
+	if actual := g.Stats(); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	g.Connect(BasicEdge(myint(4), myint(1)))
+	if actual := g.Stats(); actual.LongestPath != -1 {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestGraphStats_empty(t *testing.T) {
+	var g Graph[myint]
+	if actual := g.Stats(); !reflect.DeepEqual(actual, GraphStats{}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
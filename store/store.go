@@ -0,0 +1,290 @@
+// Package store keeps dagg graphs on disk, as a directory holding a snapshot
+// of the vertices and edges and a log of the changes made since.
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/streemtech/dagg"
+)
+
+// The files of a snapshot directory. Each holds a line of JSON per
+// dagg.Delta, except policyFile, which holds the dagg.VertexPolicy of the
+// graph as a number.
+const (
+	verticesFile = "vertices.jsonl"
+	edgesFile    = "edges.jsonl"
+	logFile      = "log.jsonl"
+	policyFile   = "policy"
+
+	// currentFile names the snapshot directory in use, and is replaced
+	// atomically by Save.
+	currentFile = "CURRENT"
+)
+
+// Store saves and loads a graph in the directory Dir. The graph is kept as
+// a snapshot of its vertices and edges, along with a log of the mutations
+// appended since the snapshot was saved, so a graph can be kept up to date
+// on disk without rewriting it.
+type Store[T dagg.Hashable] struct {
+	Dir string
+
+	// Encode and Decode serialize the vertices, and must be given.
+	Encode func(T) (string, error)
+	Decode func(string) (T, error)
+
+	// EncodeData serializes the data of a dagg.DataEdge, as for
+	// dagg.PersistOpts. Data is read back as a string.
+	EncodeData func(interface{}) (string, error)
+}
+
+// Save writes a new snapshot of g, replacing the previous snapshot and its
+// log. The snapshot keeps the VertexPolicy of g, as it is when saved. Save can't be called while an Appender of the store is open, as the
+// Appender would keep writing to the log of the old snapshot.
+func (s *Store[T]) Save(g *dagg.Graph[T]) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	old, err := s.current()
+	if err != nil {
+		return err
+	}
+
+	name := "snapshot-" + strconv.Itoa(old.generation+1)
+	dir := filepath.Join(s.Dir, name)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return err
+	}
+
+	opts := s.opts()
+	var vertices, edges []dagg.Mutation[T]
+	for _, v := range g.Vertices() {
+		vertices = append(vertices, dagg.Mutation[T]{Op: dagg.MutationAdd, Vertex: v})
+	}
+	for _, e := range g.Edges() {
+		edges = append(edges, dagg.Mutation[T]{Op: dagg.MutationConnect, Edge: e})
+	}
+	if err := writeDeltas(filepath.Join(dir, verticesFile), opts, vertices); err != nil {
+		return err
+	}
+	if err := writeDeltas(filepath.Join(dir, edgesFile), opts, edges); err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(dir, logFile), nil); err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(dir, policyFile), func(w io.Writer) error {
+		_, err := io.WriteString(w, strconv.Itoa(int(g.VertexPolicy()))+"\n")
+		return err
+	}); err != nil {
+		return err
+	}
+
+	// the new snapshot is only used once CURRENT names it
+	tmp := filepath.Join(s.Dir, currentFile+".tmp")
+	if err := writeFile(tmp, func(w io.Writer) error {
+		_, err := io.WriteString(w, name+"\n")
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(s.Dir, currentFile)); err != nil {
+		return err
+	}
+
+	if old.name != "" {
+		return os.RemoveAll(filepath.Join(s.Dir, old.name))
+	}
+	return nil
+}
+
+// Load reads the graph from the store, replaying the log over the snapshot.
+// The graph has the VertexPolicy it was saved with, and its dangling edges.
+// An empty graph is returned if nothing has been saved.
+func (s *Store[T]) Load() (*dagg.Graph[T], error) {
+	g := &dagg.Graph[T]{}
+
+	cur, err := s.current()
+	if err != nil || cur.name == "" {
+		return g, err
+	}
+
+	dir := filepath.Join(s.Dir, cur.name)
+	policy, err := readPolicy(filepath.Join(dir, policyFile))
+	if err != nil {
+		return nil, err
+	}
+	g.SetVertexPolicy(policy)
+	for _, name := range []string{verticesFile, edgesFile, logFile} {
+		if err := s.replay(filepath.Join(dir, name), g); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func (s *Store[T]) replay(path string, g *dagg.Graph[T]) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := dagg.ReplayLog(f, g, s.Decode); err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	return nil
+}
+
+// readPolicy reads the VertexPolicy of a snapshot. Snapshots saved before
+// the policy was kept have the default policy.
+func readPolicy(path string) (dagg.VertexPolicy, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return dagg.AutoAddVertices, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	p, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("%s: bad vertex policy %q", path, data)
+	}
+	return dagg.VertexPolicy(p), nil
+}
+
+// Appender appends the mutations of a graph to the log of a store.
+type Appender[T dagg.Hashable] struct {
+	*dagg.Persistence[T]
+
+	f *os.File
+}
+
+// Close stops appending mutations to the log, returning the first error
+// encoding or writing one.
+func (a *Appender[T]) Close() error {
+	err := a.Persistence.Close()
+	if cErr := a.f.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+// Append appends every later mutation of g to the log of the store, until
+// the Appender is closed, so that Load returns the graph as it was when the
+// Appender was closed. g should be the graph last saved, or loaded, by the
+// store. If sync is true, the log is synced to disk after every mutation.
+func (s *Store[T]) Append(g *dagg.Graph[T], sync bool) (*Appender[T], error) {
+	cur, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	if cur.name == "" {
+		return nil, fmt.Errorf("no snapshot saved in %s", s.Dir)
+	}
+
+	path := filepath.Join(s.Dir, cur.name, logFile)
+	if err := truncateTorn(path); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &dagg.LogPersister{W: f, Sync: sync}
+	return &Appender[T]{Persistence: g.Persist(p, s.opts()), f: f}, nil
+}
+
+// truncateTorn drops a final line of the log which isn't complete, as left
+// by a crash while writing, so that appending to the log doesn't join it to
+// the next line.
+func truncateTorn(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return nil
+	}
+	return os.Truncate(path, int64(bytes.LastIndexByte(data, '\n')+1))
+}
+
+func (s *Store[T]) opts() *dagg.PersistOpts[T] {
+	return &dagg.PersistOpts[T]{Encode: s.Encode, EncodeData: s.EncodeData}
+}
+
+// snapshot identifies the snapshot directory named by CURRENT.
+type snapshot struct {
+	name       string
+	generation int
+}
+
+func (s *Store[T]) current() (snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, currentFile))
+	if os.IsNotExist(err) {
+		return snapshot{}, nil
+	}
+	if err != nil {
+		return snapshot{}, err
+	}
+
+	name := strings.TrimSpace(string(data))
+	gen, err := strconv.Atoi(strings.TrimPrefix(name, "snapshot-"))
+	if err != nil || !strings.HasPrefix(name, "snapshot-") {
+		return snapshot{}, fmt.Errorf("%s: bad snapshot name %q", currentFile, name)
+	}
+	return snapshot{name: name, generation: gen}, nil
+}
+
+// writeDeltas writes the deltas of the mutations to a new file at path.
+func writeDeltas[T dagg.Hashable](path string, opts *dagg.PersistOpts[T], ms []dagg.Mutation[T]) error {
+	return writeFile(path, func(w io.Writer) error {
+		p := &dagg.LogPersister{W: w}
+		for _, m := range ms {
+			d, err := opts.Delta(m)
+			if err != nil {
+				return err
+			}
+			if err := p.Persist(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeFile creates the file at path, writes it with fn if fn isn't nil,
+// and syncs it to disk.
+func writeFile(path string, fn func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if fn != nil {
+		w := bufio.NewWriter(f)
+		if err := fn(w); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/streemtech/dagg"
+)
+
+type name string
+
+func (n name) Hashcode() string { return string(n) }
+
+func testStore(t *testing.T) *Store[name] {
+	return &Store[name]{
+		Dir:    t.TempDir(),
+		Encode: func(n name) (string, error) { return string(n), nil },
+		Decode: func(s string) (name, error) { return name(s), nil },
+	}
+}
+
+func TestStore(t *testing.T) {
+	s := testStore(t)
+
+	g, err := s.Load()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(g.Vertices()) != 0 {
+		t.Fatalf("bad: %s", g)
+	}
+
+	g.Add(name("a"))
+	g.Add(name("b"))
+	g.Add(name("c"))
+	g.Connect(dagg.BasicEdge(name("a"), name("b")))
+	g.Connect(dagg.LabeledEdge(name("b"), name("c"), "uses"))
+	if err := s.Save(g); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	a, err := s.Append(g, true)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	g.Add(name("d"))
+	g.Connect(dagg.BasicEdge(name("c"), name("d")))
+	g.Remove(name("a"))
+	if err := a.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	loaded, err := s.Load()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	actual := strings.TrimSpace(loaded.String())
+	expected := strings.TrimSpace(testStoreStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// saving again starts a new snapshot and log
+	if err := s.Save(loaded); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if strings.Join(names, ",") != "CURRENT,snapshot-2" {
+		t.Fatalf("bad: %v", names)
+	}
+
+	reloaded, err := s.Load()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := strings.TrimSpace(reloaded.String()); actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestStore_dangling(t *testing.T) {
+	s := testStore(t)
+	g := &dagg.Graph[name]{}
+	g.SetVertexPolicy(dagg.AllowDanglingEdges)
+	g.Add(name("a"))
+	g.Connect(dagg.BasicEdge(name("a"), name("b")))
+	if err := s.Save(g); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	a, err := s.Append(g, false)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	g.Connect(dagg.BasicEdge(name("a"), name("c")))
+	if err := a.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	loaded, err := s.Load()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if loaded.VertexPolicy() != dagg.AllowDanglingEdges {
+		t.Fatalf("bad: %d", loaded.VertexPolicy())
+	}
+	if len(loaded.Vertices()) != 1 || len(loaded.DanglingEdges()) != 2 {
+		t.Fatalf("bad: %s", loaded)
+	}
+}
+
+func TestStoreAppend_unsaved(t *testing.T) {
+	s := testStore(t)
+	if _, err := s.Append(&dagg.Graph[name]{}, false); err == nil {
+		t.Fatal("should error without a snapshot")
+	}
+}
+
+func TestStoreLoad_torn(t *testing.T) {
+	s := testStore(t)
+	g := &dagg.Graph[name]{}
+	g.Add(name("a"))
+	if err := s.Save(g); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// a write interrupted by a crash leaves part of a line
+	path := filepath.Join(s.Dir, "snapshot-1", logFile)
+	if err := os.WriteFile(path, []byte(`{"op":"add","vert`), 0o644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	loaded, err := s.Load()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := strings.TrimSpace(loaded.String()); actual != "a" {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// appending drops the partial line
+	a, err := s.Append(loaded, false)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	loaded.Add(name("b"))
+	if err := a.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	loaded, err = s.Load()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := strings.TrimSpace(loaded.String()); actual != "a\nb" {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+const testStoreStr = `
+b
+  c (uses)
+c
+  d
+d
+`
@@ -0,0 +1,143 @@
+package dagg
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// StructVertex is a vertex of a graph built by FromStructs, holding one of
+// the structs.
+type StructVertex[S any] struct {
+	ID    string
+	Deps  []string
+	Value S
+}
+
+func (v *StructVertex[S]) Hashcode() string {
+	return v.ID
+}
+
+func (v *StructVertex[S]) Name() string {
+	return v.ID
+}
+
+// StructDeclarer can be implemented by the structs given to FromStructs in
+// place of the dag struct tags.
+type StructDeclarer interface {
+	DagID() string
+	DagDeps() []string
+}
+
+// FromStructs builds a graph from a slice of structs, or pointers to
+// structs, which declare their ID and dependencies with struct tags:
+//
+//	type Service struct {
+//		Name     string   `dag:"id"`
+//		Requires []string `dag:"deps"`
+//	}
+//
+// The id field may be a string or any other type, which is formatted with
+// fmt.Sprint. The deps field may be a string or a slice of strings. Structs
+// which implement StructDeclarer are used without looking at their tags.
+//
+// As with a WorkflowSpec, every struct depends on the structs listed in its
+// deps, so each vertex has an edge to each of its dependencies. The
+// resulting graph is validated before it is returned.
+func FromStructs[S any](items []S) (*AcyclicGraph[*StructVertex[S]], error) {
+	g := &AcyclicGraph[*StructVertex[S]]{}
+	vertices := make(map[string]*StructVertex[S], len(items))
+
+	// keep the order of the items so the errors are deterministic
+	order := make([]*StructVertex[S], 0, len(items))
+
+	for i, item := range items {
+		id, deps, err := structDecl(item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		if id == "" {
+			return nil, fmt.Errorf("item %d has no id", i)
+		}
+		if _, ok := vertices[id]; ok {
+			return nil, fmt.Errorf("duplicate id %q", id)
+		}
+
+		v := &StructVertex[S]{
+			ID:    id,
+			Deps:  deps,
+			Value: item,
+		}
+		vertices[id] = v
+		order = append(order, v)
+		g.Add(v)
+	}
+
+	for _, v := range order {
+		for _, id := range v.Deps {
+			dep, ok := vertices[id]
+			if !ok {
+				return nil, fmt.Errorf("%q depends on unknown id %q", v.ID, id)
+			}
+			g.Connect(BasicEdge(v, dep))
+		}
+	}
+
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// structDecl returns the id and dependencies declared by the struct item.
+func structDecl(item interface{}) (string, []string, error) {
+	if d, ok := item.(StructDeclarer); ok {
+		return d.DagID(), d.DagDeps(), nil
+	}
+
+	rv := reflect.ValueOf(item)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "", nil, fmt.Errorf("nil item")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return "", nil, fmt.Errorf("%s is not a struct", rv.Type())
+	}
+
+	var id string
+	var deps []string
+	var hasID bool
+	for _, f := range reflect.VisibleFields(rv.Type()) {
+		if !f.IsExported() {
+			continue
+		}
+
+		fv := rv.FieldByIndex(f.Index)
+		switch f.Tag.Get("dag") {
+		case "id":
+			id = fmt.Sprint(fv.Interface())
+			hasID = true
+
+		case "deps":
+			switch {
+			case fv.Kind() == reflect.String:
+				if s := fv.String(); s != "" {
+					deps = append(deps, s)
+				}
+			case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.String:
+				for i := 0; i < fv.Len(); i++ {
+					deps = append(deps, fv.Index(i).String())
+				}
+			default:
+				return "", nil, fmt.Errorf("field %s: deps must be a string or a slice of strings, not %s", f.Name, f.Type)
+			}
+		}
+	}
+
+	if !hasID {
+		return "", nil, fmt.Errorf("%s has no field tagged `dag:\"id\"`", rv.Type())
+	}
+	return id, deps, nil
+}
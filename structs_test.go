@@ -0,0 +1,141 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+type testService struct {
+	Name     string   `dag:"id"`
+	Requires []string `dag:"deps"`
+	Port     int
+}
+
+type testJob struct {
+	ID    int    `dag:"id"`
+	After string `dag:"deps"`
+}
+
+type testDeclared struct {
+	name string
+	deps []string
+}
+
+func (d testDeclared) DagID() string     { return d.name }
+func (d testDeclared) DagDeps() []string { return d.deps }
+
+func TestFromStructs(t *testing.T) {
+	g, err := FromStructs([]*testService{
+		{Name: "db", Port: 5432},
+		{Name: "api", Requires: []string{"db", "cache"}},
+		{Name: "cache"},
+		{Name: "web", Requires: []string{"api"}},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testFromStructsStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	for _, v := range g.Vertices() {
+		if v.ID == "db" && v.Value.Port != 5432 {
+			t.Fatalf("bad value: %#v", v.Value)
+		}
+	}
+}
+
+func TestFromStructs_fieldKinds(t *testing.T) {
+	g, err := FromStructs([]testJob{
+		{ID: 1},
+		{ID: 2, After: "1"},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := "1\n2\n  1"
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestFromStructs_declarer(t *testing.T) {
+	g, err := FromStructs([]testDeclared{
+		{name: "a", deps: []string{"b"}},
+		{name: "b"},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	actual := strings.TrimSpace(g.String())
+	expected := "a\n  b\nb"
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestFromStructs_invalid(t *testing.T) {
+	cases := map[string]func() error{
+		"unknown dep": func() error {
+			_, err := FromStructs([]testService{{Name: "a", Requires: []string{"b"}}})
+			return err
+		},
+		"duplicate": func() error {
+			_, err := FromStructs([]testService{{Name: "a"}, {Name: "a"}})
+			return err
+		},
+		"no id": func() error {
+			_, err := FromStructs([]testService{{Requires: []string{"a"}}})
+			return err
+		},
+		"cycle": func() error {
+			_, err := FromStructs([]testService{
+				{Name: "a", Requires: []string{"b"}},
+				{Name: "b", Requires: []string{"a"}},
+			})
+			return err
+		},
+		"no tag": func() error {
+			_, err := FromStructs([]struct{ Name string }{{Name: "a"}})
+			return err
+		},
+		"deps kind": func() error {
+			_, err := FromStructs([]struct {
+				Name string `dag:"id"`
+				Deps int    `dag:"deps"`
+			}{{Name: "a"}})
+			return err
+		},
+		"not a struct": func() error {
+			_, err := FromStructs([]string{"a"})
+			return err
+		},
+		"nil": func() error {
+			_, err := FromStructs([]*testService{nil})
+			return err
+		},
+	}
+
+	for name, f := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := f(); err == nil {
+				t.Fatal("should error")
+			}
+		})
+	}
+}
+
+const testFromStructsStr = `
+api
+  cache
+  db
+cache
+db
+web
+  api
+`
@@ -0,0 +1,131 @@
+package dagg
+
+import "fmt"
+
+// SubtreeLock is an exclusive lock on a vertex of a SyncGraph and all of
+// its descendents, as returned by SyncGraph.LockSubtree. While it is held,
+// only the holder can modify the locked vertices, and other controllers can
+// still modify the rest of the graph.
+type SubtreeLock[T Hashable] struct {
+	s    *SyncGraph[T]
+	root T
+}
+
+// LockSubtree locks v and its descendents for exclusive modification,
+// waiting until none of them are held by another SubtreeLock. An error is
+// returned if v isn't in the graph.
+//
+// A controller must not lock a subtree which overlaps one it already
+// holds, as it would wait for itself.
+func (s *SyncGraph[T]) LockSubtree(v T) (*SubtreeLock[T], error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	l := &SubtreeLock[T]{s: s, root: v}
+	for {
+		if !s.g.HasVertex(v) {
+			return nil, fmt.Errorf("vertex %q not found", VertexName(v))
+		}
+
+		subtree, _ := s.g.Descendents(v)
+		subtree.Add(v)
+		free := true
+		for k := range subtree {
+			if _, ok := s.claims[k]; ok {
+				free = false
+				break
+			}
+		}
+		if free {
+			for k := range subtree {
+				s.claims[k] = l
+			}
+			return l, nil
+		}
+		s.unlocked.Wait()
+	}
+}
+
+// Root returns the vertex the subtree was locked from.
+func (l *SubtreeLock[T]) Root() T {
+	return l.root
+}
+
+// Apply commits the modifications of fn to the graph, as SyncGraph.Apply.
+// fn may only modify the vertices of the subtree, and vertices it adds to
+// the graph, which join the subtree. An error is returned, and nothing is
+// committed, if fn modifies any other vertex.
+func (l *SubtreeLock[T]) Apply(fn func(tx *GraphTx[T]) error) error {
+	s := l.s
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// vertices new to the graph are claimed once the changes are committed
+	added := make(Set[T])
+	check := s.checkClaims(l)
+	err := s.g.apply(fn, func(m Mutation[T]) error {
+		if err := check(m); err != nil {
+			return err
+		}
+		for _, v := range mutationVertices(m) {
+			if _, ok := s.claims[v.Hashcode()]; ok {
+				continue
+			}
+			if s.g.HasVertex(v) {
+				return fmt.Errorf("vertex %q is outside the locked subtree of %q",
+					VertexName(v), VertexName(l.root))
+			}
+			added.Add(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for k, v := range added {
+		if s.g.HasVertex(v) {
+			s.claims[k] = l
+		}
+	}
+	return nil
+}
+
+// Unlock releases the subtree, so other controllers can modify it.
+func (l *SubtreeLock[T]) Unlock() {
+	s := l.s
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for k, owner := range s.claims {
+		if owner == l {
+			delete(s.claims, k)
+		}
+	}
+	s.unlocked.Broadcast()
+}
+
+// checkClaims returns a check for apply which rejects modifications of
+// vertices held by a SubtreeLock other than holder. The lock must be held
+// while it is used.
+func (s *SyncGraph[T]) checkClaims(holder *SubtreeLock[T]) func(m Mutation[T]) error {
+	return func(m Mutation[T]) error {
+		for _, v := range mutationVertices(m) {
+			if owner, ok := s.claims[v.Hashcode()]; ok && owner != holder {
+				return fmt.Errorf("vertex %q is locked by the subtree of %q",
+					VertexName(v), VertexName(owner.root))
+			}
+		}
+		return nil
+	}
+}
+
+// mutationVertices returns the vertices modified by m.
+func mutationVertices[T Hashable](m Mutation[T]) []T {
+	switch m.Op {
+	case MutationAdd, MutationRemove:
+		return []T{m.Vertex}
+	default:
+		return []T{m.Edge.Source(), m.Edge.Target()}
+	}
+}
@@ -0,0 +1,96 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncGraphLockSubtree(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 5; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(4), myint(5)))
+	s := NewSyncGraph(&g)
+
+	l, err := s.LockSubtree(myint(2))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// the holder can modify the subtree, and add vertices to it
+	err = l.Apply(func(tx *GraphTx[myint]) error {
+		tx.Add(myint(6))
+		return tx.Connect(BasicEdge(myint(3), myint(6)))
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// but not vertices outside of it
+	err = l.Apply(func(tx *GraphTx[myint]) error {
+		return tx.Connect(BasicEdge(myint(3), myint(5)))
+	})
+	if err == nil || !strings.Contains(err.Error(), "outside the locked subtree") {
+		t.Fatalf("bad: %v", err)
+	}
+
+	// others can modify the rest of the graph
+	err = s.Apply(func(tx *GraphTx[myint]) error {
+		tx.Remove(myint(5))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// but not the locked subtree, including the vertices added to it
+	err = s.Apply(func(tx *GraphTx[myint]) error {
+		tx.Remove(myint(6))
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "locked by the subtree") {
+		t.Fatalf("bad: %v", err)
+	}
+	snapshot, _ := s.Snapshot()
+	if !snapshot.HasVertex(myint(6)) || snapshot.HasVertex(myint(5)) {
+		t.Fatalf("bad: %s", snapshot.String())
+	}
+
+	// an overlapping lock waits for the subtree to be unlocked
+	locked := make(chan *SubtreeLock[myint])
+	go func() {
+		l, err := s.LockSubtree(myint(1))
+		if err != nil {
+			t.Errorf("err: %s", err)
+		}
+		locked <- l
+	}()
+	select {
+	case <-locked:
+		t.Fatal("lock should wait")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// a disjoint lock doesn't
+	other, err := s.LockSubtree(myint(4))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	other.Unlock()
+
+	l.Unlock()
+	select {
+	case l := <-locked:
+		l.Unlock()
+	case <-time.After(time.Second):
+		t.Fatal("lock should be released")
+	}
+
+	if _, err := s.LockSubtree(myint(7)); err == nil {
+		t.Fatal("expect error")
+	}
+}
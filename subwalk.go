@@ -0,0 +1,57 @@
+package dagg
+
+// SubWalk walks the graph g as a child scope of the vertex v, and returns
+// the result of the walk. It is intended to be called from the callback of
+// v, so that hierarchical workflows can walk nested graphs with the same
+// callbacks, executors and options as the parent walk. Events of the
+// sub-walk are written to the same EventLog, with a Scope identifying v.
+// Every option of the Walker applies to the sub-walk too, so a Deadline is
+// also the deadline of g, and BeforeStart and Cache are consulted for its
+// vertices.
+//
+// SubWalk waits for the nested walk to complete, so v doesn't complete
+// until every vertex of g has.
+func (w *Walker[T]) SubWalk(v T, g *AcyclicGraph[T]) error {
+	scope := v.Hashcode()
+	if w.scope != "" {
+		scope = w.scope + "/" + scope
+	}
+
+	child := &Walker[T]{
+		Callback:          w.Callback,
+		Executors:         w.Executors,
+		HeartbeatCallback: w.HeartbeatCallback,
+		HeartbeatTimeout:  w.HeartbeatTimeout,
+		CancelStalled:     w.CancelStalled,
+		ProfileLabels:     w.ProfileLabels,
+		Deadline:          w.Deadline,
+		Estimate:          w.Estimate,
+		DeadlineWarning:   w.DeadlineWarning,
+		Cache:             w.Cache,
+		EventLog:          w.EventLog,
+		BeforeStart:       w.BeforeStart,
+		MaxFailures:       w.MaxFailures,
+		MaxBranchFailures: w.MaxBranchFailures,
+		MaxDomainFailures: w.MaxDomainFailures,
+		MaxConcurrency:    w.MaxConcurrency,
+		Priority:          w.Priority,
+		Preemption:        w.Preemption,
+		Reverse:           w.Reverse,
+
+		parent:   w,
+		scope:    scope,
+		slots:    w.slots,
+		dispatch: w.dispatch,
+		now:      w.now,
+	}
+
+	// v gives up its places while it waits, so the vertices of g can run
+	// under the shared MaxConcurrency and in the concurrency groups of v. If
+	// SubWalk wasn't called from the callback of v, it holds no places.
+	if places := w.runningPlaces(v); places != nil && places.give() {
+		defer places.take()
+	}
+
+	child.Update(g)
+	return child.Wait()
+}
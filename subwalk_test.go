@@ -0,0 +1,213 @@
+package dagg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWalker_subWalk(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+
+	var nested AcyclicGraph[myint]
+	nested.Add(myint(10))
+	nested.Add(myint(11))
+	nested.Connect(BasicEdge(myint(11), myint(10)))
+
+	var lock sync.Mutex
+	var order []myint
+	var buf bytes.Buffer
+	var w *Walker[myint]
+	w = &Walker[myint]{
+		Reverse:  true,
+		EventLog: &buf,
+		Callback: func(v myint) error {
+			lock.Lock()
+			order = append(order, v)
+			lock.Unlock()
+
+			switch v {
+			case 1:
+				return w.SubWalk(v, &nested)
+			case 11:
+				return fmt.Errorf("nested error")
+			}
+			return nil
+		},
+	}
+	w.Update(&g)
+
+	if err := w.Wait(); err == nil {
+		t.Fatal("expect error")
+	}
+	if !reflect.DeepEqual(order, []myint{1, 10, 11}) {
+		t.Fatalf("bad: %#v", order)
+	}
+
+	l, err := ReadWalkLog(&buf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	var ids []string
+	for id := range l.Vertices {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if !reflect.DeepEqual(ids, []string{"1", "1/10", "1/11", "2"}) {
+		t.Fatalf("bad: %#v", ids)
+	}
+	if !reflect.DeepEqual(l.Failed(), []string{"1", "1/11"}) {
+		t.Fatalf("bad: %#v", l.Failed())
+	}
+}
+
+func TestWalker_subWalkOptions(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+
+	var nested AcyclicGraph[myint]
+	nested.Add(myint(10))
+	nested.Add(myint(11))
+
+	var lock sync.Mutex
+	var late []myint
+	var w *Walker[myint]
+	w = &Walker[myint]{
+		Reverse: true,
+		Callback: func(v myint) error {
+			if v == 1 {
+				return w.SubWalk(v, &nested)
+			}
+			return nil
+		},
+		BeforeStart: func(v myint) (time.Duration, bool) {
+			return 0, v != 11
+		},
+		Deadline: time.Now().Add(-time.Hour),
+		Estimate: func(myint) time.Duration { return time.Second },
+		DeadlineWarning: func(v myint, _ time.Duration) {
+			lock.Lock()
+			defer lock.Unlock()
+			late = append(late, v)
+		},
+	}
+	w.Update(&g)
+
+	err := w.Wait()
+	if err == nil || !errors.Is(err, ErrVetoed) {
+		t.Fatalf("expect vetoed error, got: %v", err)
+	}
+
+	sort.Slice(late, func(i, j int) bool { return late[i] < late[j] })
+	if !reflect.DeepEqual(late, []myint{1, 10}) {
+		t.Fatalf("bad: %#v", late)
+	}
+}
+
+func TestWalker_subWalkGroups(t *testing.T) {
+	SetGroupLimit("test-subwalk", 1)
+	defer SetGroupLimit("test-subwalk", 0)
+
+	outer := &groupVertex{name: "outer", groups: []string{"test-subwalk"}}
+	inner := &groupVertex{name: "inner", groups: []string{"test-subwalk"}}
+
+	var g AcyclicGraph[*groupVertex]
+	g.Add(outer)
+
+	var nested AcyclicGraph[*groupVertex]
+	nested.Add(inner)
+
+	var lock sync.Mutex
+	var order []string
+	var w *Walker[*groupVertex]
+	w = &Walker[*groupVertex]{
+		Reverse:        true,
+		MaxConcurrency: 1,
+		Callback: func(v *groupVertex) error {
+			lock.Lock()
+			order = append(order, v.name)
+			lock.Unlock()
+
+			if v == outer {
+				return w.SubWalk(v, &nested)
+			}
+			return nil
+		},
+	}
+
+	// inner only gets a place in the group while outer gives up its own
+	done := make(chan error, 1)
+	go func() {
+		w.Update(&g)
+		done <- w.Wait()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("walk deadlocked")
+	}
+
+	if !reflect.DeepEqual(order, []string{"outer", "inner"}) {
+		t.Fatalf("bad: %#v", order)
+	}
+	if w.slots.running != 0 {
+		t.Fatalf("bad: %d places taken", w.slots.running)
+	}
+}
+
+func TestWalker_subWalkOutsideCallback(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+
+	var nested AcyclicGraph[myint]
+	nested.Add(myint(10))
+	nested.Add(myint(11))
+
+	var lock sync.Mutex
+	var running, most int
+	w := &Walker[myint]{
+		Reverse:        true,
+		MaxConcurrency: 1,
+		Callback: func(v myint) error {
+			lock.Lock()
+			running++
+			if running > most {
+				most = running
+			}
+			lock.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			lock.Lock()
+			running--
+			lock.Unlock()
+			return nil
+		},
+	}
+	w.Update(&g)
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// 1 no longer holds a place, so it has none to give up
+	if err := w.SubWalk(myint(1), &nested); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if most != 1 {
+		t.Fatalf("bad: %d running at once", most)
+	}
+	if w.slots.running != 0 {
+		t.Fatalf("bad: %d places taken", w.slots.running)
+	}
+}
@@ -0,0 +1,192 @@
+package dagg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteTo writes the graph to w in the same format as String, without
+// building the whole string in memory. The graph can be read back with
+// ReadFrom, or with ReadFromWithOpts if it has dangling edges. Edges are
+// written under their source, so a dangling edge whose source isn't a
+// vertex is left out.
+func (g *Graph[T]) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	// Build the list of node names and a mapping so that we can more
+	// easily alphabetize the output to remain deterministic.
+	vertices := g.Vertices()
+	names := make([]string, 0, len(vertices))
+	mapping := make(map[string]T, len(vertices))
+	for _, v := range vertices {
+		name := VertexName(v)
+		names = append(names, name)
+		mapping[name] = v
+	}
+	sort.Slice(names, func(i, j int) bool { return g.nameLess(names[i], names[j]) })
+
+	// Write each node in order...
+	for _, name := range names {
+		v := mapping[name]
+		targets := g.downEdgesNoCopy(v)
+
+		if _, err := fmt.Fprintf(bw, "%s\n", name); err != nil {
+			return cw.n, err
+		}
+
+		// Alphabetize dependencies
+		deps := make([]string, 0, targets.Len())
+		for _, target := range targets {
+			dep := VertexName(target)
+			if data, ok := g.EdgeData(v, target); ok {
+				dep = fmt.Sprintf("%s (%v)", dep, data)
+			}
+			deps = append(deps, dep)
+		}
+		sort.Slice(deps, func(i, j int) bool { return g.nameLess(deps[i], deps[j]) })
+
+		// Write dependencies
+		for _, d := range deps {
+			if _, err := fmt.Fprintf(bw, "  %s\n", d); err != nil {
+				return cw.n, err
+			}
+		}
+	}
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// countWriter counts the bytes written to w.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ReadOpts are the options for ReadFromWithOpts.
+type ReadOpts struct {
+	// VertexPolicy is the VertexPolicy of the graph read. With
+	// AllowDanglingEdges, a dependency which isn't listed as a vertex is
+	// read as a dangling edge, so a graph with dangling edges can be
+	// written by WriteTo and read back. With any other policy it's an
+	// error, as WriteTo lists every dependency of such a graph as a vertex.
+	VertexPolicy VertexPolicy
+}
+
+// ReadFrom reads a graph in the format written by WriteTo and String,
+// calling decode to turn the name of each vertex into a vertex. decode is
+// called once for each vertex.
+//
+// Every vertex is on its own line, followed by its dependencies indented by
+// two spaces. A dependency written as "name (data)" is returned as a
+// LabeledEdge with the data as a string. Every dependency must also be
+// listed as a vertex, as String does.
+func ReadFrom[T Hashable](r io.Reader, decode func(name string) (T, error)) (*Graph[T], error) {
+	return ReadFromWithOpts(r, decode, nil)
+}
+
+// ReadFromWithOpts reads a graph like ReadFrom, with the given options. The
+// ends of dangling edges are decoded once each, like the vertices.
+func ReadFromWithOpts[T Hashable](r io.Reader, decode func(name string) (T, error), opts *ReadOpts) (*Graph[T], error) {
+	if opts == nil {
+		opts = &ReadOpts{}
+	}
+
+	type dep struct {
+		line   int
+		source T
+		text   string
+	}
+
+	g := &Graph[T]{}
+	g.SetVertexPolicy(opts.VertexPolicy)
+	vertices := make(map[string]T)
+	dangling := make(map[string]T)
+	var deps []dep
+
+	var source *T
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		text, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if text == "" && err == io.EOF {
+			break
+		}
+
+		text = strings.TrimRight(text, "\r\n")
+		switch {
+		case strings.TrimSpace(text) == "":
+
+		case strings.HasPrefix(text, "  "):
+			if source == nil {
+				return nil, fmt.Errorf("line %d: dependency before any vertex", line)
+			}
+			deps = append(deps, dep{line: line, source: *source, text: text[2:]})
+
+		default:
+			if _, ok := vertices[text]; ok {
+				return nil, fmt.Errorf("line %d: duplicate vertex %q", line, text)
+			}
+			v, err := decode(text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", line, err)
+			}
+			vertices[text] = v
+			g.Add(v)
+			source = &v
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	// dependencies are connected once every vertex is known, since they
+	// usually refer to vertices later in the output.
+	for _, d := range deps {
+		name, data, labeled := d.text, "", false
+		if _, ok := vertices[name]; !ok {
+			// "name (data)"
+			if i := strings.LastIndex(name, " ("); i >= 0 && strings.HasSuffix(name, ")") {
+				name, data, labeled = name[:i], name[i+2:len(name)-1], true
+			}
+		}
+
+		target, ok := vertices[name]
+		if !ok {
+			if g.policy != AllowDanglingEdges {
+				return nil, fmt.Errorf("line %d: unknown vertex %q", d.line, d.text)
+			}
+			if target, ok = dangling[name]; !ok {
+				v, err := decode(name)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %s", d.line, err)
+				}
+				dangling[name] = v
+				target = v
+			}
+		}
+
+		e := BasicEdge(d.source, target)
+		if labeled {
+			e = LabeledEdge(d.source, target, data)
+		}
+		if err := g.Connect(e); err != nil {
+			return nil, fmt.Errorf("line %d: %s", d.line, err)
+		}
+	}
+
+	return g, nil
+}
@@ -0,0 +1,133 @@
+package dagg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGraphWriteTo(t *testing.T) {
+	var g Graph[mystr]
+	g.Add("a")
+	g.Add("b")
+	g.Add("c")
+	g.Connect(BasicEdge[mystr]("a", "b"))
+	g.Connect(LabeledEdge[mystr]("a", "c", 42))
+	g.Connect(BasicEdge[mystr]("b", "c"))
+
+	var buf bytes.Buffer
+	n, err := g.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("bad: wrote %d, counted %d", buf.Len(), n)
+	}
+
+	actual := buf.String()
+	if actual != g.String() {
+		t.Fatalf("bad: %s", actual)
+	}
+	if strings.TrimSpace(actual) != strings.TrimSpace(testGraphWriteToStr) {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+	calls := 0
+	g, err := ReadFrom(strings.NewReader(testGraphWriteToStr), func(name string) (mystr, error) {
+		calls++
+		return mystr(name), nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if calls != 3 {
+		t.Fatalf("decode called %d times", calls)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testGraphWriteToStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+	if data, _ := g.EdgeData("a", "c"); data != "42" {
+		t.Fatalf("bad: %#v", data)
+	}
+}
+
+func TestReadFrom_invalid(t *testing.T) {
+	decode := func(name string) (mystr, error) {
+		if name == "bad" {
+			return "", errors.New("bad name")
+		}
+		return mystr(name), nil
+	}
+
+	cases := map[string]string{
+		"unknown":   "a\n  b\n",
+		"duplicate": "a\na\n",
+		"orphan":    "  a\n",
+		"decode":    "a\nbad\n",
+	}
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadFrom(strings.NewReader(src), decode); err == nil {
+				t.Fatal("should error")
+			}
+		})
+	}
+}
+
+func TestReadFromWithOpts_danglingEdges(t *testing.T) {
+	var g Graph[mystr]
+	g.SetVertexPolicy(AllowDanglingEdges)
+	g.Add("a")
+	g.Add("b")
+	g.Connect(BasicEdge[mystr]("a", "b"))
+	g.Connect(BasicEdge[mystr]("a", "c"))
+	g.Connect(LabeledEdge[mystr]("b", "d", 5))
+	g.Connect(BasicEdge[mystr]("b", "c"))
+
+	var buf bytes.Buffer
+	if _, err := g.WriteTo(&buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	calls := 0
+	decode := func(name string) (mystr, error) {
+		calls++
+		return mystr(name), nil
+	}
+	if _, err := ReadFrom(bytes.NewReader(buf.Bytes()), decode); err == nil {
+		t.Fatal("should error")
+	}
+
+	calls = 0
+	read, err := ReadFromWithOpts(bytes.NewReader(buf.Bytes()), decode, &ReadOpts{VertexPolicy: AllowDanglingEdges})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if calls != 4 {
+		t.Fatalf("decode called %d times", calls)
+	}
+	if read.String() != g.String() {
+		t.Fatalf("bad: %s", read.String())
+	}
+	if dangling := read.DanglingEdges(); len(dangling) != 3 {
+		t.Fatalf("bad: %#v", dangling)
+	}
+	if data, _ := read.EdgeData("b", "d"); data != "5" {
+		t.Fatalf("bad: %#v", data)
+	}
+}
+
+const testGraphWriteToStr = `
+a
+  b
+  c (42)
+b
+  c
+c
+`
@@ -0,0 +1,124 @@
+package dagg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KindedVertex can be implemented by a vertex to declare what kind of
+// vertex it is, which is used to look up its style in a Theme.
+type KindedVertex interface {
+	Kind() string
+}
+
+// StatefulVertex can be implemented by a vertex to declare its current
+// state, which is used to look up its style in a Theme.
+type StatefulVertex interface {
+	State() string
+}
+
+// Theme maps the kinds and states of vertices to styles, so that graphs can
+// be rendered consistently by Dot and Mermaid.
+//
+// The style of a vertex starts as Default, which is then overridden by the
+// style for the vertex kind, and finally by the style for the vertex state.
+// Only the non-empty fields of a Style override the previous value.
+type Theme struct {
+	Default Style
+	Kinds   map[string]Style
+	States  map[string]Style
+}
+
+// Style describes how to draw a vertex. Colors are any color understood by
+// the output format, and Shape is a dot shape name.
+type Style struct {
+	Color     string
+	FillColor string
+	Shape     string
+}
+
+// merge returns s with the non-empty fields of o applied over it.
+func (s Style) merge(o Style) Style {
+	if o.Color != "" {
+		s.Color = o.Color
+	}
+	if o.FillColor != "" {
+		s.FillColor = o.FillColor
+	}
+	if o.Shape != "" {
+		s.Shape = o.Shape
+	}
+	return s
+}
+
+func (s Style) empty() bool {
+	return s == Style{}
+}
+
+// style returns the style for a vertex with the given kind and state.
+func (t *Theme) style(kind, state string) Style {
+	if t == nil {
+		return Style{}
+	}
+
+	s := t.Default
+	if kind != "" {
+		s = s.merge(t.Kinds[kind])
+	}
+	if state != "" {
+		s = s.merge(t.States[state])
+	}
+	return s
+}
+
+// dotAttrs returns the dot attributes for the style.
+func (s Style) dotAttrs() map[string]string {
+	attrs := make(map[string]string)
+	if s.Color != "" {
+		attrs["color"] = s.Color
+	}
+	if s.FillColor != "" {
+		attrs["fillcolor"] = s.FillColor
+		attrs["style"] = "filled"
+	}
+	if s.Shape != "" {
+		attrs["shape"] = s.Shape
+	}
+	return attrs
+}
+
+// mermaidStyle returns the mermaid style statement properties for the
+// style, or an empty string if there are none.
+func (s Style) mermaidStyle() string {
+	var props []string
+	if s.FillColor != "" {
+		props = append(props, "fill:"+s.FillColor)
+	}
+	if s.Color != "" {
+		props = append(props, "stroke:"+s.Color)
+	}
+	sort.Strings(props)
+	return strings.Join(props, ",")
+}
+
+// mermaidShapes maps dot shape names to the mermaid node delimiters.
+var mermaidShapes = map[string][2]string{
+	"box":     {"[", "]"},
+	"rect":    {"[", "]"},
+	"ellipse": {"([", "])"},
+	"oval":    {"([", "])"},
+	"circle":  {"((", "))"},
+	"diamond": {"{", "}"},
+	"hexagon": {"{{", "}}"},
+}
+
+// mermaidNode returns the mermaid node declaration for the given ID and
+// label, using the style shape if mermaid supports it.
+func (s Style) mermaidNode(id, label string) string {
+	delims, ok := mermaidShapes[s.Shape]
+	if !ok {
+		delims = mermaidShapes["box"]
+	}
+	return fmt.Sprintf("%s%s%s%s", id, delims[0], label, delims[1])
+}
@@ -0,0 +1,96 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThemeStyle(t *testing.T) {
+	theme := &Theme{
+		Default: Style{Color: "black", Shape: "box"},
+		Kinds: map[string]Style{
+			"task": {Shape: "ellipse"},
+		},
+		States: map[string]Style{
+			"failed": {Color: "red", FillColor: "pink"},
+		},
+	}
+
+	expected := Style{Color: "red", FillColor: "pink", Shape: "ellipse"}
+	if s := theme.style("task", "failed"); s != expected {
+		t.Fatalf("bad: %#v", s)
+	}
+
+	expected = Style{Color: "black", Shape: "box"}
+	if s := theme.style("unknown", ""); s != expected {
+		t.Fatalf("bad: %#v", s)
+	}
+
+	var nilTheme *Theme
+	if s := nilTheme.style("task", "failed"); !s.empty() {
+		t.Fatalf("bad: %#v", s)
+	}
+}
+
+func TestGraphDot_theme(t *testing.T) {
+	var g Graph[*testThemedVertex]
+	a := &testThemedVertex{name: "a", kind: "task", state: "failed"}
+	b := &testThemedVertex{name: "b", kind: "task"}
+	g.Add(a)
+	g.Add(b)
+	g.Connect(BasicEdge(a, b))
+
+	actual := strings.TrimSpace(string(g.Dot(&DotOpts{Theme: testTheme})))
+	expected := strings.TrimSpace(testGraphDotThemeStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestGraphMermaid_theme(t *testing.T) {
+	var g Graph[*testThemedVertex]
+	a := &testThemedVertex{name: "a", kind: "task", state: "failed"}
+	b := &testThemedVertex{name: "b", kind: "task"}
+	g.Add(a)
+	g.Add(b)
+	g.Connect(BasicEdge(a, b))
+
+	actual := strings.TrimSpace(string(g.Mermaid(&MermaidOpts{Theme: testTheme})))
+	expected := strings.TrimSpace(testGraphMermaidThemeStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+var testTheme = &Theme{
+	Kinds: map[string]Style{
+		"task": {Shape: "ellipse"},
+	},
+	States: map[string]Style{
+		"failed": {Color: "red", FillColor: "pink"},
+	},
+}
+
+type testThemedVertex struct {
+	name, kind, state string
+}
+
+func (v *testThemedVertex) Hashcode() string { return v.name }
+func (v *testThemedVertex) Kind() string     { return v.kind }
+func (v *testThemedVertex) State() string    { return v.state }
+
+const testGraphDotThemeStr = `digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] a" [color = "red", fillcolor = "pink", shape = "ellipse", style = "filled"]
+		"[root] b" [shape = "ellipse"]
+		"[root] a" -> "[root] b"
+	}
+}`
+
+const testGraphMermaidThemeStr = `graph TD
+	n0(["a"])
+	n1(["b"])
+	style n0 fill:pink,stroke:red
+	n0 --> n1`
@@ -0,0 +1,91 @@
+package dagg
+
+// GraphTx collects modifications to an AcyclicGraph which are committed
+// together by Apply. Reads through the GraphTx see the pending
+// modifications.
+type GraphTx[T Hashable] struct {
+	g *AcyclicGraph[T]
+}
+
+// Add adds a vertex to the transaction.
+func (tx *GraphTx[T]) Add(v T) T {
+	return tx.g.Add(v)
+}
+
+// Remove removes a vertex and its edges in the transaction.
+func (tx *GraphTx[T]) Remove(v T) {
+	tx.g.Remove(v)
+}
+
+// Connect adds an edge in the transaction, returning an error if the
+// graph's VertexPolicy rejects it.
+func (tx *GraphTx[T]) Connect(e Edge[T]) error {
+	return tx.g.Connect(e)
+}
+
+// RemoveEdge removes an edge in the transaction.
+func (tx *GraphTx[T]) RemoveEdge(e Edge[T]) {
+	tx.g.RemoveEdge(e)
+}
+
+// HasVertex checks if the vertex is present, including pending
+// modifications.
+func (tx *GraphTx[T]) HasVertex(v T) bool {
+	return tx.g.HasVertex(v)
+}
+
+// HasEdge checks if the edge is present, including pending modifications.
+func (tx *GraphTx[T]) HasEdge(e Edge[T]) bool {
+	return tx.g.HasEdge(e)
+}
+
+// Apply calls fn with a transaction, and commits its modifications to the
+// graph if fn succeeds and the result contains no cycles. Otherwise the
+// graph is left unchanged and the error is returned. If the result contains
+// cycles, the error is a *ValidationError wrapping a *CycleError.
+//
+// The cycle check is done once for the whole transaction, rather than once
+// for each modification, and the graph structure is copied at most once.
+func (g *AcyclicGraph[T]) Apply(fn func(tx *GraphTx[T]) error) error {
+	return g.apply(fn, nil)
+}
+
+// apply is Apply, with every modification of the transaction given to check
+// before it is committed, if check is set. If check returns an error the
+// graph is left unchanged.
+func (g *AcyclicGraph[T]) apply(fn func(tx *GraphTx[T]) error, check func(m Mutation[T]) error) error {
+	work := g.ReadSnapshot()
+
+	// the mutations are only passed to the graph's hooks once committed
+	var mutations []Mutation[T]
+	if len(g.hooks) > 0 || check != nil {
+		work.addHook(func(m Mutation[T]) {
+			mutations = append(mutations, m)
+		})
+	}
+
+	if err := fn(&GraphTx[T]{g: work}); err != nil {
+		return err
+	}
+	if check != nil {
+		for _, m := range mutations {
+			if err := check(m); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := work.topologicalOrder(); err != nil {
+		// get the detailed cycle information
+		return work.Validate()
+	}
+
+	// the work graph has already counted the mutations in its version
+	hooks := g.hooks
+	g.Graph = work.Graph
+	g.hooks = hooks
+	for _, m := range mutations {
+		g.callHooks(m)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package dagg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAcyclicGraphApply(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+
+	err := g.Apply(func(tx *GraphTx[myint]) error {
+		tx.Add(myint(2))
+		tx.Add(myint(3))
+		tx.Connect(BasicEdge(myint(1), myint(2)))
+		tx.Connect(BasicEdge(myint(2), myint(3)))
+		if !tx.HasEdge(BasicEdge(myint(1), myint(2))) {
+			return fmt.Errorf("pending edge should be visible")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testGraphApplyStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestAcyclicGraphApply_rollback(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+
+	err := g.Apply(func(tx *GraphTx[myint]) error {
+		tx.Remove(myint(1))
+		tx.Connect(BasicEdge(myint(3), myint(2)))
+		return nil
+	})
+	var cr CycleReporter[myint]
+	if !errors.As(err, &cr) {
+		t.Fatalf("bad: %v", err)
+	}
+
+	err = g.Apply(func(tx *GraphTx[myint]) error {
+		tx.Remove(myint(1))
+		return fmt.Errorf("abort")
+	})
+	if err == nil || err.Error() != "abort" {
+		t.Fatalf("bad: %v", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testGraphApplyStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+const testGraphApplyStr = `
+1
+  2
+2
+  3
+3
+`
@@ -0,0 +1,96 @@
+package dagg
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Version returns the version of the graph, which is advanced by every
+// mutation. Copies and snapshots start at the version of the graph they
+// were taken from.
+func (g *Graph[T]) Version() uint64 {
+	return g.version
+}
+
+// VersionError is returned by ApplyIf when the graph has been modified
+// since the expected version.
+type VersionError struct {
+	Expected, Actual uint64
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("graph is at version %d, expected %d", e.Actual, e.Expected)
+}
+
+// ApplyIf is like Apply, but only if the graph is still at the given
+// version, as returned by Version. Otherwise fn isn't called, and a
+// *VersionError is returned. This lets a caller which read the graph detect
+// that another caller has modified it since, rather than overwriting its
+// changes.
+func (g *AcyclicGraph[T]) ApplyIf(version uint64, fn func(tx *GraphTx[T]) error) error {
+	if g.version != version {
+		return &VersionError{Expected: version, Actual: g.version}
+	}
+	return g.Apply(fn)
+}
+
+// SyncGraph guards an AcyclicGraph for use by concurrent controllers. Each
+// controller reads a snapshot of the graph along with its version, and
+// commits its changes with ApplyIf, so that updates made from a stale
+// snapshot are detected rather than lost.
+//
+// A controller which owns a part of the graph can hold a SubtreeLock on it,
+// so that no other controller can modify it, while the rest of the graph
+// stays writable.
+type SyncGraph[T Hashable] struct {
+	lock sync.Mutex
+	g    *AcyclicGraph[T]
+
+	// claims holds the SubtreeLock of every locked vertex, by hashcode.
+	// unlocked is signalled when a SubtreeLock is released.
+	claims   map[string]*SubtreeLock[T]
+	unlocked *sync.Cond
+}
+
+// NewSyncGraph returns a SyncGraph guarding g. g must not be used directly
+// afterwards.
+func NewSyncGraph[T Hashable](g *AcyclicGraph[T]) *SyncGraph[T] {
+	s := &SyncGraph[T]{g: g, claims: make(map[string]*SubtreeLock[T])}
+	s.unlocked = sync.NewCond(&s.lock)
+	return s
+}
+
+// Version returns the current version of the graph.
+func (s *SyncGraph[T]) Version() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.g.Version()
+}
+
+// Snapshot returns a read-only snapshot of the graph and its version. The
+// snapshot can be read while the graph is modified.
+func (s *SyncGraph[T]) Snapshot() (*AcyclicGraph[T], uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.g.ReadSnapshot(), s.g.Version()
+}
+
+// Apply commits the modifications of fn to the graph, regardless of the
+// version. See AcyclicGraph.Apply. An error is returned, and nothing is
+// committed, if fn modifies a vertex held by a SubtreeLock.
+func (s *SyncGraph[T]) Apply(fn func(tx *GraphTx[T]) error) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.g.apply(fn, s.checkClaims(nil))
+}
+
+// ApplyIf commits the modifications of fn to the graph if it is still at
+// the given version. See AcyclicGraph.ApplyIf.
+func (s *SyncGraph[T]) ApplyIf(version uint64, fn func(tx *GraphTx[T]) error) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.g.version != version {
+		return &VersionError{Expected: version, Actual: s.g.version}
+	}
+	return s.g.apply(fn, s.checkClaims(nil))
+}
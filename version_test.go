@@ -0,0 +1,106 @@
+package dagg
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestGraphVersion(t *testing.T) {
+	var g AcyclicGraph[myint]
+	if g.Version() != 0 {
+		t.Fatalf("bad: %d", g.Version())
+	}
+
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	if g.Version() != 3 {
+		t.Fatalf("bad: %d", g.Version())
+	}
+
+	// nothing is removed, so nothing changes
+	g.RemoveEdge(BasicEdge(myint(2), myint(1)))
+	if g.Version() != 3 {
+		t.Fatalf("bad: %d", g.Version())
+	}
+
+	// a transaction advances the version once per mutation
+	err := g.Apply(func(tx *GraphTx[myint]) error {
+		tx.Add(myint(3))
+		tx.Connect(BasicEdge(myint(2), myint(3)))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if g.Version() != 5 {
+		t.Fatalf("bad: %d", g.Version())
+	}
+	if c := g.Copy(); c.Version() != 5 {
+		t.Fatalf("bad: %d", c.Version())
+	}
+}
+
+func TestAcyclicGraphApplyIf(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	version := g.Version()
+
+	g.Add(myint(2))
+
+	called := false
+	err := g.ApplyIf(version, func(tx *GraphTx[myint]) error {
+		called = true
+		return nil
+	})
+	var verr *VersionError
+	if !errors.As(err, &verr) || verr.Expected != 1 || verr.Actual != 2 {
+		t.Fatalf("bad: %v", err)
+	}
+	if called {
+		t.Fatal("should not call fn")
+	}
+
+	err = g.ApplyIf(g.Version(), func(tx *GraphTx[myint]) error {
+		tx.Connect(BasicEdge(myint(1), myint(2)))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !g.HasEdge(BasicEdge(myint(1), myint(2))) {
+		t.Fatal("should have edge")
+	}
+}
+
+func TestSyncGraph(t *testing.T) {
+	s := NewSyncGraph(&AcyclicGraph[myint]{})
+
+	// every controller reads the same version, so only one can commit
+	_, version := s.Snapshot()
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	committed := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := s.ApplyIf(version, func(tx *GraphTx[myint]) error {
+				tx.Add(myint(i))
+				return nil
+			})
+			if err == nil {
+				lock.Lock()
+				committed++
+				lock.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	snapshot, version := s.Snapshot()
+	if committed != 1 || version != 1 || len(snapshot.Vertices()) != 1 {
+		t.Fatalf("bad: %d %d %d", committed, version, len(snapshot.Vertices()))
+	}
+}
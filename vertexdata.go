@@ -0,0 +1,72 @@
+package dagg
+
+import (
+	"fmt"
+)
+
+// SetVertexData annotates the vertex with a value under the key, such as
+// its status or owner. Annotations are kept by Replace, and removed along
+// with the vertex. The vertex need not be in the graph yet.
+func (g *Graph[T]) SetVertexData(v T, key string, value interface{}) {
+	g.unshare()
+	if g.data == nil {
+		g.data = make(map[string]map[string]interface{})
+	}
+
+	data, ok := g.data[v.Hashcode()]
+	if !ok {
+		data = make(map[string]interface{})
+		g.data[v.Hashcode()] = data
+	}
+	data[key] = value
+}
+
+// VertexData returns the annotation of the vertex under the key.
+func (g *Graph[T]) VertexData(v T, key string) (interface{}, bool) {
+	value, ok := g.data[v.Hashcode()][key]
+	return value, ok
+}
+
+// DeleteVertexData removes the annotation of the vertex under the key.
+func (g *Graph[T]) DeleteVertexData(v T, key string) {
+	if _, ok := g.data[v.Hashcode()][key]; !ok {
+		return
+	}
+
+	g.unshare()
+	delete(g.data[v.Hashcode()], key)
+	if len(g.data[v.Hashcode()]) == 0 {
+		delete(g.data, v.Hashcode())
+	}
+}
+
+// vertexDataStrings returns the annotations of the vertex formatted as
+// strings, for the marshal output.
+func (g *Graph[T]) vertexDataStrings(v T) map[string]string {
+	data, ok := g.data[v.Hashcode()]
+	if !ok {
+		return nil
+	}
+
+	result := make(map[string]string, len(data))
+	for k, value := range data {
+		result[k] = fmt.Sprint(value)
+	}
+	return result
+}
+
+func copyVertexData(m map[string]map[string]interface{}) map[string]map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	c := make(map[string]map[string]interface{}, len(m))
+	for k, data := range m {
+		d := make(map[string]interface{}, len(data))
+		for key, value := range data {
+			d[key] = value
+		}
+		c[k] = d
+	}
+	return c
+}
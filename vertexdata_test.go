@@ -0,0 +1,50 @@
+package dagg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphVertexData(t *testing.T) {
+	var g Graph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.SetVertexData(myint(1), "owner", "infra")
+
+	snapshot := g.ReadSnapshot()
+	g.SetVertexData(myint(1), "status", "failed")
+	if _, ok := snapshot.VertexData(myint(1), "status"); ok {
+		t.Fatal("snapshot should not be modified")
+	}
+
+	g.Replace(myint(1), myint(3))
+	if v, ok := g.VertexData(myint(3), "owner"); !ok || v != "infra" {
+		t.Fatalf("bad: %v", v)
+	}
+	if _, ok := g.VertexData(myint(1), "owner"); ok {
+		t.Fatal("replaced vertex should have no data")
+	}
+
+	g.DeleteVertexData(myint(3), "owner")
+	if _, ok := g.VertexData(myint(3), "owner"); ok {
+		t.Fatal("data should be deleted")
+	}
+
+	actual := strings.TrimSpace(string(g.Dot(&DotOpts{VertexData: true})))
+	expected := strings.TrimSpace(testGraphVertexDataDotStr)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+}
+
+const testGraphVertexDataDotStr = `
+digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] 3" [status = "failed"]
+		"[root] 3" -> "[root] 2"
+	}
+}
+`
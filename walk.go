@@ -0,0 +1,855 @@
+package dagg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// Walker is used to walk every vertex of a graph in parallel.
+//
+// A vertex will only be walked when the dependencies of that vertex have
+// been walked. If two vertices can be walked at the same time, they will be.
+//
+// Update can be called to update the graph. This can be called even during
+// a walk, changing vertices/edges mid-walk, such as after a Replace, Remove
+// or Connect on the graph. Pending vertices which are removed are never
+// run, and pending vertices which are replaced by a vertex with the same
+// hashcode run with the replacement. Running vertices finish under their
+// old identity, and if a vertex is removed but has already been executed,
+// the result of that execution (any error) is still returned by Wait.
+// Changing or re-adding a vertex that has already executed has no effect.
+// Changing edges of a vertex that has already executed has no effect.
+// Changes made after the first Update are recorded, and returned by Changes.
+//
+// Non-parallelism can be enforced by introducing a lock in your callback
+// function. However, the goroutine overhead of a walk will remain.
+// Walker will create V*2 goroutines (one for each vertex, and dependency
+// waiter for each vertex). In general this should be of no concern unless
+// there are a huge number of vertices.
+//
+// The walk is depth first by default. This can be changed with the Reverse
+// option.
+//
+// A single walker is only valid for one graph walk. After the walk is complete
+// you must construct a new walker to walk again. State for the walk is never
+// deleted in case vertices or edges are changed.
+type Walker[T Hashable] struct {
+	// Callback is what is called for each vertex
+	Callback WalkFunc[T]
+
+	// Executors, if set, are used instead of the Callback. Each vertex is
+	// dispatched to the first executor with the capabilities it requires.
+	Executors []*Executor[T]
+
+	// HeartbeatCallback, if set, is used instead of the Callback. It is given
+	// a Heartbeat for the vertex to report its progress with, so that
+	// vertices which haven't reported within the HeartbeatTimeout are
+	// returned by Stalled. If CancelStalled is true, the context of a
+	// stalled vertex is cancelled and the vertex fails.
+	HeartbeatCallback HeartbeatFunc[T]
+	HeartbeatTimeout  time.Duration
+	CancelStalled     bool
+
+	// ProfileLabels, if true, tags the goroutine running each vertex with
+	// the pprof labels "dagg.vertex", holding the hashcode of the vertex,
+	// and "dagg.kind" for a KindedVertex. CPU and heap profiles of a walk
+	// can then be attributed to the vertices.
+	ProfileLabels bool
+
+	// Deadline, if set along with Estimate, is the time the walk should
+	// finish by. The latest time each vertex can start is calculated from the
+	// Estimate of how long each vertex takes, and if a vertex starts after
+	// this, or is still running once its latest start plus its Estimate has
+	// passed, the walk is certain to miss the deadline. DeadlineWarning is
+	// then called with how late the vertex is, as soon as it's known.
+	Deadline        time.Time
+	Estimate        func(T) time.Duration
+	DeadlineWarning func(v T, late time.Duration)
+
+	// Cache, if set, shares the results of vertices with other walks using
+	// the same cache, so a vertex whose work has already been done by
+	// another walk isn't run again.
+	Cache *ResultCache
+
+	// EventLog, if set, has every decision of the walk written to it as a
+	// line of JSON. The log can be replayed with ReadWalkLog.
+	EventLog  io.Writer
+	eventLock sync.Mutex
+
+	// parent and scope are set for the walker of a SubWalk.
+	parent *Walker[T]
+	scope  string
+
+	// dispatch, if set, wraps every run of a vertex, whether it's run by
+	// the Callback, an Executor or the HeartbeatCallback, or polled. It is
+	// set by FaultInjector.Walk.
+	dispatch func(v T, run func() error) error
+
+	// BeforeStart, if set, is consulted when a vertex is ready to start,
+	// for admission control such as maintenance windows or circuit
+	// breakers. If it returns a delay greater than zero, the vertex waits
+	// that long and BeforeStart is consulted again. Otherwise the vertex
+	// starts if allow is true, or fails with ErrVetoed if it is false. A
+	// vertex removed while it is delayed stops waiting, and never starts.
+	BeforeStart func(v T) (delay time.Duration, allow bool)
+
+	// MaxFailures, if greater than zero, aborts the walk once this many
+	// vertices have failed. Vertices which haven't started yet are skipped.
+	MaxFailures int
+
+	// MaxBranchFailures, if greater than zero, skips the remaining vertices
+	// which depend on a vertex once this many of its dependents have failed.
+	// This avoids large fan-outs from each reporting the same failure.
+	MaxBranchFailures int
+
+	// MaxDomainFailures, if greater than zero, skips the remaining vertices
+	// of a failure domain once this many of its vertices have failed. See
+	// FailureDomainVertex.
+	MaxDomainFailures int
+
+	// MaxConcurrency, if greater than zero, is the number of vertices which
+	// may run at once. The limit is shared with the walks of SubWalk, and a
+	// vertex gives up its place while waiting for its SubWalk.
+	MaxConcurrency int
+
+	// Priority, if set, orders the vertices waiting for a place under
+	// MaxConcurrency, so that when several vertices are ready at once, those
+	// with a higher priority start first. Vertices with the same priority
+	// start in the order they became ready.
+	Priority func(v T) int
+
+	// Preemption, if set, lets a vertex waiting for a place under
+	// MaxConcurrency ask a running vertex with a lower Priority to yield
+	// it. The context of the running vertex is cancelled, and if it then
+	// returns an error, it gives up its place and runs again once it has
+	// one back. Only vertices which are given a context, those run by the
+	// HeartbeatCallback and ExternalVertex polls, can be preempted. The
+	// preemptions of each vertex are counted in its VertexResult.
+	Preemption *PreemptionPolicy
+
+	// slots holds the places of the running vertices, when MaxConcurrency
+	// is set.
+	slots *slotPool
+
+	// places holds the places of each running vertex, by hashcode, so a
+	// SubWalk of the vertex can give them up while it waits.
+	places     map[string]*heldPlaces
+	placesLock sync.Mutex
+
+	// resumeCh is set while the walk is paused, and closed by Resume.
+	resumeCh  chan struct{}
+	pauseLock sync.Mutex
+
+	// restored holds the outcome of the vertices which finished in the
+	// checkpoint given to RestoreCheckpoint, by hashcode.
+	restored map[string]restoredVertex
+
+	// Reverse, if true, causes the source of an edge to depend on a target.
+	// When false (default), the target depends on the source.
+	Reverse bool
+
+	// changeLock must be held to modify any of the fields below. Only Update
+	// should modify these fields. Modifying them outside of Update can cause
+	// serious problems.
+	changeLock sync.Mutex
+	vertices   Set[T]
+	edges      edgeSet[T]
+	vertexMap  map[string]*walkerVertex[T]
+	changes    []WalkChange
+
+	// serialPrev maps the hashcode of each member of a serial group to the
+	// member it must wait for.
+	serialPrev map[string]string
+
+	// latestStarts holds the latest start time of each vertex, to meet the
+	// Deadline.
+	latestStarts map[string]time.Time
+
+	// wait is done when all vertices have executed. It may become "undone"
+	// if new vertices are added.
+	wait sync.WaitGroup
+
+	// errMap contains the errors recorded so far for execution. Vertices
+	// which were skipped because their upstream failed are recorded in
+	// upstreamFailed, so their errors aren't reported by Wait.
+	errMap         map[string]error
+	upstreamFailed map[string]struct{}
+	errLock        sync.Mutex
+
+	// results holds the status of every vertex which finished or was
+	// cancelled, for Result. It is protected by errLock.
+	results map[string]*VertexResult[T]
+
+	// failures counts the failed vertices, in total, by each of their
+	// dependencies and by failure domain, so the failure thresholds can be
+	// applied. skipped counts the vertices which weren't run because a
+	// threshold was reached.
+	failures       int
+	branchFailures map[string]int
+	domainFailures map[string]int
+	skipped        int
+
+	// preemptions counts the times each vertex yielded its place, by
+	// hashcode. It is protected by errLock.
+	preemptions map[string]int
+
+	// timings records when each vertex ran, for Gantt.
+	timings map[string]*walkTiming[T]
+
+	// now is used to get the current time, and can be replaced for tests.
+	now func() time.Time
+
+	// heartbeats holds the vertices currently running a HeartbeatCallback.
+	heartbeats    map[string]*runningVertex[T]
+	heartbeatLock sync.Mutex
+}
+
+func (w *Walker[T]) init() {
+	if w.vertices == nil {
+		w.vertices = make(Set[T])
+	}
+	if w.edges == nil {
+		w.edges = make(edgeSet[T])
+	}
+	if w.slots == nil && w.MaxConcurrency > 0 {
+		w.slots = &slotPool{limit: w.MaxConcurrency, preemption: w.Preemption}
+	}
+}
+
+type walkerVertex[T Hashable] struct {
+	// These should only be set once on initialization and never written again.
+	// They are not protected by a lock since they don't need to be since
+	// they are write-once.
+
+	// DoneCh is closed when this vertex has completed execution, regardless
+	// of success.
+	//
+	// CancelCh is closed when the vertex should cancel execution. If execution
+	// is already complete (DoneCh is closed), this has no effect. Otherwise,
+	// execution is cancelled as quickly as possible.
+	DoneCh   chan struct{}
+	CancelCh chan struct{}
+
+	// Dependency information. Any changes to any of these fields requires
+	// holding DepsLock.
+	//
+	// DepsCh is sent a single value that denotes whether the upstream deps
+	// were successful (no errors). Any value sent means that the upstream
+	// dependencies are complete. No other values will ever be sent again.
+	//
+	// DepsUpdateCh is closed when there is a new DepsCh set.
+	DepsCh       chan bool
+	DepsUpdateCh chan struct{}
+	DepsLock     sync.Mutex
+
+	// Below is not safe to read/write in parallel. This behavior is
+	// enforced by changes only happening in Update. Nothing else should
+	// ever modify these.
+	deps         map[string]chan struct{}
+	depsCancelCh chan struct{}
+
+	// started is set once the vertex begins executing, and is protected by
+	// the walker's changeLock.
+	started bool
+}
+
+// errWalkUpstream is used in the errMap of a walk to note that an upstream
+// dependency failed so this vertex wasn't run. This is not shown in the final
+// user-returned error.
+var errWalkUpstream = errors.New("upstream dependency failed")
+
+// errWalkThreshold is the reason recorded for vertices skipped because a
+// failure threshold was reached. Like errWalkUpstream, it isn't returned by
+// Wait.
+var errWalkThreshold = errors.New("failure threshold reached")
+
+// errWalkDomain is the reason recorded for vertices skipped because their
+// failure domain reached MaxDomainFailures.
+var errWalkDomain = errors.New("failure domain threshold reached")
+
+// ErrVetoed is the error of a vertex which the Walker's BeforeStart hook
+// didn't allow to start. The vertices which depend on it are skipped.
+var ErrVetoed = errors.New("vetoed by BeforeStart")
+
+// admit consults BeforeStart, if it is set, waiting out any delays, and
+// returns whether v may start. If cancelCh is closed during a delay, as
+// when v is removed from the walk, admit stops waiting and returns with
+// cancelled set.
+func (w *Walker[T]) admit(v T, cancelCh <-chan struct{}) (allow, cancelled bool) {
+	if w.BeforeStart == nil {
+		return true, false
+	}
+	for {
+		delay, allow := w.BeforeStart(v)
+		if delay <= 0 {
+			return allow, false
+		}
+		log.Printf("[TRACE] dagg/walk: BeforeStart delayed %q by %s", VertexName(v), delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-cancelCh:
+			timer.Stop()
+			return false, true
+		}
+	}
+}
+
+// Wait waits for the completion of the walk and returns an error describing
+// any problems that arose. Update should be called to populate the walk with
+// vertices and edges prior to calling this.
+//
+// Wait will return as soon as all currently known vertices are complete.
+// If you plan on calling Update with more vertices in the future, you
+// should not call Wait until after this is done.
+func (w *Walker[T]) Wait() error {
+	// Wait for completion
+	w.wait.Wait()
+
+	var result error
+	w.errLock.Lock()
+	for k, err := range w.errMap {
+		if _, upstream := w.upstreamFailed[k]; upstream {
+			continue
+		}
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	if w.skipped > 0 {
+		result = multierror.Append(result, fmt.Errorf(
+			"%d vertices skipped after reaching the failure threshold", w.skipped))
+	}
+	w.errLock.Unlock()
+
+	return result
+}
+
+// Update updates the currently executing walk with the given graph.
+// This will perform a diff of the vertices and edges and update the walker.
+// Already completed vertices remain completed (including any errors during
+// their execution).
+//
+// This returns immediately once the walker is updated; it does not wait
+// for completion of the walk.
+//
+// Multiple Updates can be called in parallel. Update can be called at any
+// time during a walk.
+func (w *Walker[T]) Update(g *AcyclicGraph[T]) {
+	w.init()
+	v := make(Set[T])
+	e := make(edgeSet[T])
+	if g != nil {
+		// undirected edges are not dependencies, so they are not walked
+		v, e = g.vertices, g.edges.Filter(IsDirected[T])
+	}
+
+	// Grab the change lock so no more updates happen but also so that
+	// no new vertices are executed during this time since we may be
+	// removing them.
+	w.changeLock.Lock()
+	defer w.changeLock.Unlock()
+
+	// Initialize fields, noting if this is the first update so that we only
+	// record changes made during the walk.
+	midWalk := w.vertexMap != nil
+	if w.vertexMap == nil {
+		w.vertexMap = make(map[string]*walkerVertex[T])
+	}
+
+	// Calculate all our sets
+	newEdges := e.Difference(w.edges)
+	oldEdges := w.edges.Difference(e)
+	newVerts := v.Difference(w.vertices)
+	oldVerts := w.vertices.Difference(v)
+
+	// Add the new vertices
+	for _, raw := range newVerts {
+		// Add to the waitgroup so our walk is not done until everything finishes
+		w.wait.Add(1)
+
+		// Add to our own set so we know about it already
+		w.vertices.Add(raw)
+
+		// Initialize the vertex info
+		info := &walkerVertex[T]{
+			DoneCh:   make(chan struct{}),
+			CancelCh: make(chan struct{}),
+			deps:     make(map[string]chan struct{}),
+		}
+
+		// Add it to the map and kick off the walk
+		w.vertexMap[raw.Hashcode()] = info
+	}
+
+	// Pending vertices which have been replaced will run with their
+	// replacement.
+	for k, raw := range v {
+		if _, ok := w.vertices[k]; ok {
+			w.vertices[k] = raw
+		}
+	}
+
+	// Remove the old vertices
+	for _, raw := range oldVerts {
+		// Get the vertex info so we can cancel it
+		info, ok := w.vertexMap[raw.Hashcode()]
+		if !ok {
+			// This vertex for some reason was never in our map. This
+			// shouldn't be possible.
+			continue
+		}
+
+		// Cancel the vertex
+		close(info.CancelCh)
+
+		if midWalk {
+			w.changes = append(w.changes, WalkChange{
+				Type:   WalkChangeRemoved,
+				Vertex: raw.Hashcode(),
+				Name:   VertexName(raw),
+				State:  info.state(),
+			})
+		}
+
+		// Delete it out of the map
+		delete(w.vertexMap, raw.Hashcode())
+		w.vertices.Delete(raw)
+	}
+
+	// Add the new edges
+	changedDeps := make(Set[T])
+	for _, raw := range newEdges {
+		waiter, dep := w.edgeParts(raw)
+
+		// Get the info for the waiter
+		waiterInfo, ok := w.vertexMap[waiter.Hashcode()]
+		if !ok {
+			// Vertex doesn't exist... shouldn't be possible but ignore.
+			continue
+		}
+
+		// Get the info for the dep
+		depInfo, ok := w.vertexMap[dep.Hashcode()]
+		if !ok {
+			// Vertex doesn't exist... shouldn't be possible but ignore.
+			continue
+		}
+
+		// Add the dependency to our waiter
+		waiterInfo.deps[dep.Hashcode()] = depInfo.DoneCh
+
+		// Record that the deps changed for this waiter
+		changedDeps.Add(waiter)
+		w.edges.Add(raw)
+	}
+
+	// Process removed edges
+	for _, raw := range oldEdges {
+		waiter, dep := w.edgeParts(raw)
+
+		// Get the info for the waiter
+		waiterInfo, ok := w.vertexMap[waiter.Hashcode()]
+		if !ok {
+			// Vertex doesn't exist... shouldn't be possible but ignore.
+			continue
+		}
+
+		// Delete the dependency from the waiter
+		delete(waiterInfo.deps, dep.Hashcode())
+
+		// Record that the deps changed for this waiter
+		changedDeps.Add(waiter)
+		w.edges.Delete(raw)
+	}
+
+	// For each vertex with changed dependencies, we need to kick off
+	// a new waiter and notify the vertex of the changes.
+	for _, v := range changedDeps {
+		info, ok := w.vertexMap[v.Hashcode()]
+		if !ok {
+			// Vertex doesn't exist... shouldn't be possible but ignore.
+			continue
+		}
+
+		// Create a new done channel
+		doneCh := make(chan bool, 1)
+
+		// Create the channel we close for cancellation
+		cancelCh := make(chan struct{})
+
+		// Build a new deps copy
+		deps := make(map[string]<-chan struct{})
+		for k, v := range info.deps {
+			deps[k] = v
+		}
+
+		// Update the update channel
+		info.DepsLock.Lock()
+		if info.DepsUpdateCh != nil {
+			close(info.DepsUpdateCh)
+		}
+		info.DepsCh = doneCh
+		info.DepsUpdateCh = make(chan struct{})
+		info.DepsLock.Unlock()
+
+		// Cancel the older waiter
+		if info.depsCancelCh != nil {
+			close(info.depsCancelCh)
+		}
+		info.depsCancelCh = cancelCh
+
+		// Start the waiter
+		go w.waitDeps(v, deps, doneCh, cancelCh)
+	}
+
+	w.updateSerial(g)
+	w.computeLatestStarts()
+
+	// Start all the new vertices. We do this at the end so that all
+	// the edge waiters and changes are set up above.
+	for _, v := range newVerts {
+		if midWalk {
+			w.changes = append(w.changes, WalkChange{
+				Type:   WalkChangeAdded,
+				Vertex: v.Hashcode(),
+				Name:   VertexName(v),
+				State:  WalkStatePending,
+			})
+		}
+
+		go w.walkVertex(v, w.vertexMap[v.Hashcode()])
+	}
+}
+
+// edgeParts returns the waiter and the dependency, in that order.
+// The waiter is waiting on the dependency.
+func (w *Walker[T]) edgeParts(e Edge[T]) (T, T) {
+	if w.Reverse {
+		return e.Source(), e.Target()
+	}
+
+	return e.Target(), e.Source()
+}
+
+// walkVertex walks a single vertex, waiting for any dependencies before
+// executing the callback.
+func (w *Walker[T]) walkVertex(v T, info *walkerVertex[T]) {
+	// When we're done executing, lower the waitgroup count
+	defer w.wait.Done()
+
+	// When we're done, always close our done channel
+	defer close(info.DoneCh)
+
+	// Wait for our dependencies. We create a [closed] deps channel so
+	// that we can immediately fall through to load our actual DepsCh.
+	var depsSuccess bool
+	var depsUpdateCh chan struct{}
+	depsCh := make(chan bool, 1)
+	depsCh <- true
+	close(depsCh)
+	for {
+		select {
+		case <-info.CancelCh:
+			// Cancel
+			w.cancelled(v)
+			return
+
+		case depsSuccess = <-depsCh:
+			// Deps complete! Mark as nil to trigger completion handling.
+			depsCh = nil
+
+		case <-depsUpdateCh:
+			// New deps, reloop
+		}
+
+		// Check if we have updated dependencies. This can happen if the
+		// dependencies were satisfied exactly prior to an Update occurring.
+		// In that case, we'd like to take into account new dependencies
+		// if possible.
+		info.DepsLock.Lock()
+		if info.DepsCh != nil {
+			depsCh = info.DepsCh
+			info.DepsCh = nil
+		}
+		if info.DepsUpdateCh != nil {
+			depsUpdateCh = info.DepsUpdateCh
+		}
+		info.DepsLock.Unlock()
+
+		// If we still have no deps channel set, then we're done!
+		if depsCh == nil {
+			break
+		}
+	}
+
+	// Wait while the walk is paused.
+	w.waitPaused(info.CancelCh)
+
+	// If we passed dependencies, we just want to check once more that
+	// we're not cancelled, since this can happen just as dependencies pass.
+	select {
+	case <-info.CancelCh:
+		// Cancelled during an update while dependencies completed.
+		w.cancelled(v)
+		return
+	default:
+	}
+
+	// Mark the vertex as started, unless it was removed while we waited
+	// for the lock. From here on the vertex runs under its current identity
+	// regardless of any updates. Collect our dependencies to apply the
+	// branch failure threshold.
+	w.changeLock.Lock()
+	select {
+	case <-info.CancelCh:
+		w.changeLock.Unlock()
+		w.cancelled(v)
+		return
+	default:
+	}
+	info.started = true
+	if current, ok := w.vertices[v.Hashcode()]; ok {
+		v = current
+	}
+	deps := make([]string, 0, len(info.deps))
+	for dep := range info.deps {
+		deps = append(deps, dep)
+	}
+	w.changeLock.Unlock()
+
+	// Run our callback or note that our upstream failed
+	var err error
+	var upstreamFailed bool
+	if r, ok := w.restoredResult(v); depsSuccess && ok {
+		// finished in a previous run of the walk
+		log.Printf("[TRACE] dagg/walk: %q finished before the checkpoint, so not running it", VertexName(v))
+		err, upstreamFailed = r.err, r.skipped
+		if err == errWalkThreshold || err == errWalkDomain {
+			w.errLock.Lock()
+			w.skipped++
+			w.errLock.Unlock()
+		}
+	} else if depsSuccess && w.thresholdReached(deps) {
+		log.Printf("[TRACE] dagg/walk: failure threshold reached, so skipping %q", VertexName(v))
+		w.logEvent(WalkEventSkipped, v, "", errWalkThreshold)
+		err = errWalkThreshold
+		upstreamFailed = true
+
+		w.errLock.Lock()
+		w.skipped++
+		w.errLock.Unlock()
+	} else if depsSuccess && w.domainThresholdReached(v) {
+		log.Printf("[TRACE] dagg/walk: failure domain %q reached its threshold, so skipping %q",
+			failureDomain(v), VertexName(v))
+		w.logEvent(WalkEventSkipped, v, "", errWalkDomain)
+		err = errWalkDomain
+		upstreamFailed = true
+
+		w.errLock.Lock()
+		w.skipped++
+		w.errLock.Unlock()
+	} else if depsSuccess {
+		w.waitSerial(v)
+		w.logEvent(WalkEventReady, v, "", nil)
+		allow, cancelled := w.admit(v, info.CancelCh)
+		if cancelled {
+			log.Printf("[TRACE] dagg/walk: %q was removed while BeforeStart delayed it", VertexName(v))
+			w.cancelled(v)
+			return
+		}
+		if allow {
+			places := w.takePlaces(v)
+
+			// a preempted vertex gives up its groups along with its place,
+			// and takes them back in the same order
+			yield := func() {
+				places.give()
+				places.take()
+			}
+
+			w.logEvent(WalkEventStarted, v, "", nil)
+			stopDeadline := w.checkDeadline(v)
+			w.startTiming(v, deps)
+			if w.Cache != nil {
+				err = w.Cache.do(contentKey(v), func() error { return w.executePreemptible(v, yield) })
+			} else {
+				err = w.executePreemptible(v, yield)
+			}
+			stopDeadline()
+			w.endTiming(v, err)
+			w.givePlaces(v, places)
+			w.logEvent(WalkEventFinished, v, "", err)
+		} else {
+			log.Printf("[TRACE] dagg/walk: %q was vetoed by BeforeStart", VertexName(v))
+			w.logEvent(WalkEventSkipped, v, "", ErrVetoed)
+			err = ErrVetoed
+		}
+	} else {
+		log.Printf("[TRACE] dagg/walk: upstream of %q errored, so skipping", VertexName(v))
+		w.logEvent(WalkEventSkipped, v, "", errWalkUpstream)
+		// This won't be returned to the user because we'll set
+		// upstreamFailed, but we need to ensure there's an error recorded
+		// so that the failures will cascade downstream.
+		err = errWalkUpstream
+		upstreamFailed = true
+	}
+
+	// Record the result (we must do this after execution because we mustn't
+	// hold errLock while visiting a vertex.)
+	w.errLock.Lock()
+	if w.errMap == nil {
+		w.errMap = make(map[string]error)
+	}
+	w.errMap[v.Hashcode()] = err
+	if w.upstreamFailed == nil {
+		w.upstreamFailed = make(map[string]struct{})
+	}
+	if upstreamFailed {
+		w.upstreamFailed[v.Hashcode()] = struct{}{}
+		w.recordResult(v, WalkStatusSkipped, err)
+	} else if err != nil {
+		w.recordResult(v, WalkStatusFailed, err)
+		w.failures++
+		if w.branchFailures == nil {
+			w.branchFailures = make(map[string]int)
+		}
+		for _, dep := range deps {
+			w.branchFailures[dep]++
+		}
+		if domain := failureDomain(v); domain != "" {
+			if w.domainFailures == nil {
+				w.domainFailures = make(map[string]int)
+			}
+			w.domainFailures[domain]++
+		}
+	} else {
+		w.recordResult(v, WalkStatusSuccess, nil)
+	}
+	w.errLock.Unlock()
+}
+
+// thresholdReached returns true if the walk has reached MaxFailures, or if
+// any of the given dependencies have reached MaxBranchFailures.
+func (w *Walker[T]) thresholdReached(deps []string) bool {
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+
+	if w.MaxFailures > 0 && w.failures >= w.MaxFailures {
+		return true
+	}
+	if w.MaxBranchFailures > 0 {
+		for _, dep := range deps {
+			if w.branchFailures[dep] >= w.MaxBranchFailures {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// executeLabeled executes v, with the goroutine tagged with pprof labels
+// for the vertex if ProfileLabels is set.
+func (w *Walker[T]) executeLabeled(ctx context.Context, v T) error {
+	if !w.ProfileLabels {
+		return w.execute(ctx, v)
+	}
+
+	labels := []string{"dagg.vertex", v.Hashcode()}
+	var raw interface{}
+	raw = v
+	if k, ok := raw.(KindedVertex); ok {
+		labels = append(labels, "dagg.kind", k.Kind())
+	}
+
+	var err error
+	pprof.Do(ctx, pprof.Labels(labels...), func(ctx context.Context) {
+		err = w.execute(ctx, v)
+	})
+	return err
+}
+
+// execute runs the callback for a single vertex through the dispatch hook,
+// if there is one.
+func (w *Walker[T]) execute(ctx context.Context, v T) error {
+	if w.dispatch == nil {
+		return w.run(ctx, v)
+	}
+	return w.dispatch(v, func() error { return w.run(ctx, v) })
+}
+
+// run runs the callback for a single vertex, using the Executors or
+// HeartbeatCallback if they are set. An ExternalVertex is polled instead.
+func (w *Walker[T]) run(ctx context.Context, v T) error {
+	var raw interface{}
+	raw = v
+	if ev, ok := raw.(ExternalVertex); ok {
+		return poll(ctx, v, ev)
+	}
+
+	if len(w.Executors) == 0 {
+		if w.HeartbeatCallback != nil {
+			return w.executeHeartbeat(ctx, v)
+		}
+		return w.Callback(v)
+	}
+
+	e := executorFor(w.Executors, v)
+	if e == nil {
+		return fmt.Errorf("no executor can run %q", VertexName(v))
+	}
+	return e.Callback(v)
+}
+
+func (w *Walker[T]) waitDeps(
+	v T,
+	deps map[string]<-chan struct{},
+	doneCh chan<- bool,
+	cancelCh <-chan struct{}) {
+
+	// For each dependency given to us, wait for it to complete
+	for dep, depCh := range deps {
+	DepSatisfied:
+		for {
+			select {
+			case <-depCh:
+				// Dependency satisfied!
+				w.logEvent(WalkEventDependency, v, dep, nil)
+				break DepSatisfied
+
+			case <-cancelCh:
+				// Wait cancelled. Note that we didn't satisfy dependencies
+				// so that anything waiting on us also doesn't run.
+				doneCh <- false
+				return
+
+			case <-time.After(time.Second * 5):
+				log.Printf("[TRACE] dagg/walk: vertex %q is waiting for %q",
+					VertexName(v), dep)
+			}
+		}
+	}
+
+	// Dependencies satisfied! We need to check if any errored
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+	for dep := range deps {
+		if w.errMap[dep] != nil {
+			// One of our dependencies failed, so return false
+			doneCh <- false
+			return
+		}
+	}
+
+	// All dependencies satisfied and successful
+	doneCh <- true
+}
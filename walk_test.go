@@ -0,0 +1,496 @@
+package dagg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWalker_basic(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+
+	// Run it a bunch of times since it is timing dependent
+	for i := 0; i < 50; i++ {
+		var order []myint
+		w := &Walker[myint]{Callback: walkCbRecord(&order)}
+		w.Update(&g)
+
+		// Wait
+		if err := w.Wait(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		// Check
+		expected := []myint{1, 2}
+		if !reflect.DeepEqual(order, expected) {
+			t.Errorf("wrong order\ngot:  %#v\nwant: %#v", order, expected)
+		}
+	}
+}
+
+func TestWalker_updateNilGraph(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+
+	// Run it a bunch of times since it is timing dependent
+	for i := 0; i < 50; i++ {
+		var order []myint
+		w := &Walker[myint]{Callback: walkCbRecord(&order)}
+		w.Update(&g)
+		w.Update(nil)
+
+		// Wait
+		if err := w.Wait(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+}
+
+func TestWalker_error(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Add(myint(4))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(2), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(4)))
+
+	// Record function
+	var order []myint
+	recordF := walkCbRecord(&order)
+
+	// Build a callback that delays until we close a channel
+	cb := func(v myint) error {
+		if v == 2 {
+			return fmt.Errorf("error")
+		}
+
+		return recordF(v)
+	}
+
+	w := &Walker[myint]{Callback: cb}
+	w.Update(&g)
+
+	// Wait
+	if err := w.Wait(); err == nil {
+		t.Fatal("expect error")
+	}
+
+	// Check
+	expected := []myint{1}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", order, expected)
+	}
+}
+
+func TestWalker_maxFailures(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(3), myint(2)))
+
+	var order []myint
+	recordF := walkCbRecord(&order)
+	started := make(chan struct{})
+	failed := make(chan struct{})
+	cb := func(v myint) error {
+		switch v {
+		case 1:
+			<-started
+			defer close(failed)
+			return fmt.Errorf("error")
+		case 2:
+			// wait for the failure to be recorded before 3 can start
+			close(started)
+			<-failed
+			time.Sleep(10 * time.Millisecond)
+		}
+		return recordF(v)
+	}
+
+	w := &Walker[myint]{Callback: cb, Reverse: true, MaxFailures: 1}
+	w.Update(&g)
+
+	err := w.Wait()
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	if !strings.Contains(err.Error(), "1 vertices skipped") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	expected := []myint{2}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", order, expected)
+	}
+}
+
+func TestWalker_maxBranchFailures(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 0; i <= 5; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(1), myint(0)))
+	g.Connect(BasicEdge(myint(2), myint(0)))
+	g.Connect(BasicEdge(myint(3), myint(0)))
+	g.Connect(BasicEdge(myint(3), myint(5)))
+	g.Connect(BasicEdge(myint(4), myint(5)))
+
+	var order []myint
+	recordF := walkCbRecord(&order)
+	var failures sync.WaitGroup
+	failures.Add(2)
+	cb := func(v myint) error {
+		switch v {
+		case 1, 2:
+			defer failures.Done()
+			return fmt.Errorf("error %d", v)
+		case 5:
+			// wait for the failures to be recorded before 3 and 4 can start
+			failures.Wait()
+			time.Sleep(10 * time.Millisecond)
+		}
+		return recordF(v)
+	}
+
+	w := &Walker[myint]{Callback: cb, Reverse: true, MaxBranchFailures: 2}
+	w.Update(&g)
+
+	err := w.Wait()
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	if !strings.Contains(err.Error(), "1 vertices skipped") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	sort.Sort(byVertexName[myint](order))
+	expected := []myint{0, 4, 5}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", order, expected)
+	}
+}
+
+func TestWalker_beforeStart(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(3), myint(2)))
+
+	var lock sync.Mutex
+	asked := make(map[myint]int)
+	before := func(v myint) (time.Duration, bool) {
+		lock.Lock()
+		defer lock.Unlock()
+		asked[v]++
+
+		switch v {
+		case 1:
+			// delayed once, then allowed
+			if asked[v] == 1 {
+				return time.Millisecond, false
+			}
+			return 0, true
+		case 2:
+			return 0, false
+		}
+		return 0, true
+	}
+
+	var order []myint
+	w := &Walker[myint]{Callback: walkCbRecord(&order), Reverse: true, BeforeStart: before}
+	w.Update(&g)
+
+	err := w.Wait()
+	if !errors.Is(err, ErrVetoed) {
+		t.Fatalf("bad: %v", err)
+	}
+
+	// 2 is vetoed, so 3 is skipped and never asked
+	if !reflect.DeepEqual(order, []myint{1}) {
+		t.Fatalf("bad: %#v", order)
+	}
+	if !reflect.DeepEqual(asked, map[myint]int{1: 2, 2: 1}) {
+		t.Fatalf("bad: %#v", asked)
+	}
+}
+
+func TestWalker_beforeStartRemoved(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+
+	delayed := make(chan struct{})
+	var once sync.Once
+	before := func(v myint) (time.Duration, bool) {
+		if v == 1 {
+			once.Do(func() { close(delayed) })
+			return time.Hour, false
+		}
+		return 0, true
+	}
+
+	var order []myint
+	w := &Walker[myint]{Callback: walkCbRecord(&order), Reverse: true, BeforeStart: before}
+	w.Update(&g)
+
+	// removing 1 ends its delay
+	<-delayed
+	g.Remove(myint(1))
+	w.Update(&g)
+
+	done := make(chan error, 1)
+	go func() { done <- w.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("removed vertex still delayed")
+	}
+
+	if !reflect.DeepEqual(order, []myint{2}) {
+		t.Fatalf("bad: %#v", order)
+	}
+	if status := w.Result().Status(myint(1)); status != WalkStatusCancelled {
+		t.Fatalf("bad: %s", status)
+	}
+}
+
+func TestWalker_profileLabels(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+
+	var label string
+	w := &Walker[myint]{
+		ProfileLabels: true,
+		HeartbeatCallback: func(ctx context.Context, v myint, hb *Heartbeat) error {
+			label, _ = pprof.Label(ctx, "dagg.vertex")
+			return nil
+		},
+	}
+	w.Update(&g)
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if label != "1" {
+		t.Fatalf("bad: %q", label)
+	}
+}
+
+func TestWalker_newVertex(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+
+	// Record function
+	var order []myint
+	recordF := walkCbRecord(&order)
+	done2 := make(chan int)
+
+	// Build a callback that notifies us when 2 has been walked
+	var w *Walker[myint]
+	cb := func(v myint) error {
+		if v == 2 {
+			defer close(done2)
+		}
+		return recordF(v)
+	}
+
+	// Add the initial vertices
+	w = &Walker[myint]{Callback: cb}
+	w.Update(&g)
+
+	// if 2 has been visited, the walk is complete so far
+	<-done2
+
+	// Update the graph
+	g.Add(myint(3))
+	w.Update(&g)
+
+	// Update the graph again but with the same vertex
+	g.Add(myint(3))
+	w.Update(&g)
+
+	// Wait
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Check
+	expected := []myint{1, 2, 3}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", order, expected)
+	}
+}
+
+func TestWalker_removeVertex(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+
+	// Record function
+	var order []myint
+	recordF := walkCbRecord(&order)
+
+	var w *Walker[myint]
+	cb := func(v myint) error {
+		if v == 1 {
+			g.Remove(myint(2))
+			w.Update(&g)
+		}
+
+		return recordF(v)
+	}
+
+	// Add the initial vertices
+	w = &Walker[myint]{Callback: cb}
+	w.Update(&g)
+
+	// Wait
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Check
+	expected := []myint{1}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", order, expected)
+	}
+}
+
+func TestWalker_newEdge(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+
+	// Record function
+	var order []myint
+	recordF := walkCbRecord(&order)
+
+	var w *Walker[myint]
+	cb := func(v myint) error {
+		// record where we are first, otherwise the Updated vertex may get
+		// walked before the first visit.
+		err := recordF(v)
+
+		if v == 1 {
+			g.Add(myint(3))
+			g.Connect(BasicEdge(myint(3), myint(2)))
+			w.Update(&g)
+		}
+		return err
+	}
+
+	// Add the initial vertices
+	w = &Walker[myint]{Callback: cb}
+	w.Update(&g)
+
+	// Wait
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Check
+	expected := []myint{1, 3, 2}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", order, expected)
+	}
+}
+
+func TestWalker_removeEdge(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(1), myint(2)))
+	g.Connect(BasicEdge(myint(1), myint(3)))
+	g.Connect(BasicEdge(myint(3), myint(2)))
+
+	// Record function
+	var order []myint
+	recordF := walkCbRecord(&order)
+
+	// The way this works is that our original graph forces
+	// the order of 1 => 3 => 2. During the execution of 1, we
+	// remove the edge forcing 3 before 2. Then, during the execution
+	// of 3, we wait on a channel that is only closed by 2, implicitly
+	// forcing 2 before 3 via the callback (and not the graph). If
+	// 2 cannot execute before 3 (edge removal is non-functional), then
+	// this test will timeout.
+	var w *Walker[myint]
+	gateCh := make(chan struct{})
+	cb := func(v myint) error {
+		t.Logf("visit vertex %#v", v)
+		switch v {
+		case 1:
+			g.RemoveEdge(BasicEdge(myint(3), myint(2)))
+			w.Update(&g)
+			t.Logf("removed edge from 3 to 2")
+
+		case 2:
+			// this visit isn't completed until we've recorded it
+			// Once the visit is official, we can then close the gate to
+			// let 3 continue.
+			defer close(gateCh)
+			defer t.Logf("2 unblocked 3")
+
+		case 3:
+			select {
+			case <-gateCh:
+				t.Logf("vertex 3 gate channel is now closed")
+			case <-time.After(500 * time.Millisecond):
+				t.Logf("vertex 3 timed out waiting for the gate channel to close")
+				return fmt.Errorf("timeout 3 waiting for 2")
+			}
+		}
+
+		return recordF(v)
+	}
+
+	// Add the initial vertices
+	w = &Walker[myint]{Callback: cb}
+	w.Update(&g)
+
+	// Wait
+	if err := w.Wait(); err != nil {
+		t.Fatalf("unexpected errors: %s", err)
+	}
+
+	// Check
+	expected := []myint{1, 2, 3}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", order, expected)
+	}
+}
+
+// walkCbRecord is a test helper callback that just records the order called.
+func walkCbRecord(order *[]myint) WalkFunc[myint] {
+	var l sync.Mutex
+	return func(v myint) error {
+		l.Lock()
+		defer l.Unlock()
+		*order = append(*order, v)
+		return nil
+	}
+}
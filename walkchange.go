@@ -0,0 +1,56 @@
+package dagg
+
+// The types of WalkChange.
+const (
+	WalkChangeAdded   = "added"
+	WalkChangeRemoved = "removed"
+)
+
+// The states a vertex can be in when it is changed during a walk.
+const (
+	WalkStatePending = "pending"
+	WalkStateRunning = "running"
+	WalkStateDone    = "done"
+)
+
+// WalkChange records a vertex added to or removed from a walk after it
+// started.
+type WalkChange struct {
+	Type string
+
+	// Vertex is the hashcode of the vertex, and Name is its VertexName.
+	Vertex string
+	Name   string
+
+	// State is the state of the vertex at the time of the change. Removed
+	// vertices which were pending are never run, while those which were
+	// running finish and have their results reported.
+	State string
+}
+
+// Changes returns the vertices added or removed by calls to Update after
+// the first, in the order they happened. Replacing a vertex with one with a
+// different hashcode is recorded as a removal and an addition.
+func (w *Walker[T]) Changes() []WalkChange {
+	w.changeLock.Lock()
+	defer w.changeLock.Unlock()
+
+	result := make([]WalkChange, len(w.changes))
+	copy(result, w.changes)
+	return result
+}
+
+// state returns the state of the vertex. The walker's changeLock must be
+// held.
+func (v *walkerVertex[T]) state() string {
+	select {
+	case <-v.DoneCh:
+		return WalkStateDone
+	default:
+	}
+
+	if v.started {
+		return WalkStateRunning
+	}
+	return WalkStatePending
+}
@@ -0,0 +1,73 @@
+package dagg
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestWalker_changesMidWalk(t *testing.T) {
+	a := &hashVertex{code: 1}
+	b := &hashVertex{code: 2}
+	c := &hashVertex{code: 3}
+
+	var g AcyclicGraph[*hashVertex]
+	g.Add(a)
+	g.Add(b)
+	g.Add(c)
+	g.Connect(BasicEdge(b, a))
+	g.Connect(BasicEdge(c, a))
+
+	var lock sync.Mutex
+	ran := make(map[string]*hashVertex)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	w := &Walker[*hashVertex]{
+		Reverse: true,
+		Callback: func(v *hashVertex) error {
+			if v == a {
+				close(started)
+				<-release
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			ran[v.Hashcode()] = v
+			return nil
+		},
+	}
+	w.Update(&g)
+	<-started
+
+	// replace b with an equal vertex, and c with a new vertex, while a is
+	// running, then remove a
+	b2 := &hashVertex{code: 2}
+	d := &hashVertex{code: 4}
+	g.Add(b2)
+	g.Replace(c, d)
+	g.Remove(a)
+	w.Update(&g)
+	close(release)
+
+	if err := w.Wait(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if ran["1"] != a || ran["2"] != b2 || ran["4"] != d {
+		t.Fatalf("bad: %#v", ran)
+	}
+	if _, ok := ran["3"]; ok {
+		t.Fatal("removed vertex should not run")
+	}
+
+	changes := w.Changes()
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Vertex < changes[j].Vertex })
+	expected := []WalkChange{
+		{Type: WalkChangeRemoved, Vertex: "1", Name: "1", State: WalkStateRunning},
+		{Type: WalkChangeRemoved, Vertex: "3", Name: "3", State: WalkStatePending},
+		{Type: WalkChangeAdded, Vertex: "4", Name: "4", State: WalkStatePending},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("bad: %#v", changes)
+	}
+}
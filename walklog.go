@@ -0,0 +1,217 @@
+package dagg
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"time"
+)
+
+// WalkLogVersion is the schema version of the events written to a walker's
+// EventLog.
+const WalkLogVersion = 1
+
+// The types of event written to a walker's EventLog.
+const (
+	WalkEventDependency = "dependency"
+	WalkEventReady      = "ready"
+	WalkEventStarted    = "started"
+	WalkEventFinished   = "finished"
+	WalkEventSkipped    = "skipped"
+	WalkEventPreempted  = "preempted"
+)
+
+// WalkEvent is a single line of a walker's EventLog.
+type WalkEvent struct {
+	Version int       `json:"v"`
+	Time    time.Time `json:"time"`
+	Type    string    `json:"event"`
+
+	// Vertex is the hashcode of the vertex, and Name is its VertexName.
+	Vertex string `json:"vertex"`
+	Name   string `json:"name,omitempty"`
+
+	// Scope is set for events of a SubWalk, and is the path of hashcodes
+	// of the vertices which started it, separated by "/".
+	Scope string `json:"scope,omitempty"`
+
+	// Dependency is the hashcode of the dependency that was satisfied, for
+	// dependency events.
+	Dependency string `json:"dependency,omitempty"`
+
+	// Error is the error a vertex finished with, or the reason it was
+	// skipped.
+	Error string `json:"error,omitempty"`
+
+	// SLO is the SLO of an SLOVertex, for started events.
+	SLO time.Duration `json:"slo,omitempty"`
+}
+
+// logEvent writes an event to the EventLog, if there is one.
+func (w *Walker[T]) logEvent(typ string, v T, dep string, err error) {
+	if w.EventLog == nil {
+		return
+	}
+
+	e := WalkEvent{
+		Version:    WalkLogVersion,
+		Time:       w.clock(),
+		Type:       typ,
+		Vertex:     v.Hashcode(),
+		Name:       VertexName(v),
+		Scope:      w.scope,
+		Dependency: dep,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if typ == WalkEventStarted {
+		e.SLO, _ = vertexSLO(v)
+	}
+
+	line, jErr := json.Marshal(e)
+	if jErr != nil {
+		log.Printf("[WARN] dagg/walk: failed to encode event: %s", jErr)
+		return
+	}
+	line = append(line, '\n')
+
+	// sub-walks share the event log, so they share the lock too
+	root := w
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.eventLock.Lock()
+	defer root.eventLock.Unlock()
+	if _, wErr := w.EventLog.Write(line); wErr != nil {
+		log.Printf("[WARN] dagg/walk: failed to write event: %s", wErr)
+	}
+}
+
+// WalkLogVertex is the history of a single vertex in a walk event log.
+type WalkLogVertex struct {
+	Name     string
+	Ready    time.Time
+	Started  time.Time
+	Finished time.Time
+	Skipped  bool
+	Error    string
+
+	// Preemptions is the number of times the vertex yielded its place to a
+	// vertex with a higher priority.
+	Preemptions int
+
+	// SLO is the SLO of an SLOVertex.
+	SLO time.Duration
+
+	// Dependencies lists the hashcodes of the dependencies, in the order
+	// they were satisfied.
+	Dependencies []string
+}
+
+// Duration returns how long the vertex ran for.
+func (v *WalkLogVertex) Duration() time.Duration {
+	if v.Started.IsZero() || v.Finished.IsZero() {
+		return 0
+	}
+	return v.Finished.Sub(v.Started)
+}
+
+// WalkLog is a walk replayed from its event log.
+type WalkLog struct {
+	// Vertices holds the history of each vertex, by hashcode. Vertices of a
+	// SubWalk are prefixed by their scope and a "/".
+	Vertices map[string]*WalkLogVertex
+
+	// Started lists the hashcodes of the vertices in the order they
+	// started.
+	Started []string
+}
+
+// Failed returns the hashcodes of the vertices which finished with an error,
+// sorted.
+func (l *WalkLog) Failed() []string {
+	var result []string
+	for id, v := range l.Vertices {
+		if !v.Skipped && v.Error != "" {
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Skipped returns the hashcodes of the vertices which were skipped, sorted.
+func (l *WalkLog) Skipped() []string {
+	var result []string
+	for id, v := range l.Vertices {
+		if v.Skipped {
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ReadWalkLog replays an event log written by a walker.
+func ReadWalkLog(r io.Reader) (*WalkLog, error) {
+	result := &WalkLog{Vertices: make(map[string]*WalkLogVertex)}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var e WalkEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		if e.Version != WalkLogVersion {
+			return nil, fmt.Errorf("line %d: unsupported version %d", line, e.Version)
+		}
+
+		id := e.Vertex
+		if e.Scope != "" {
+			id = e.Scope + "/" + e.Vertex
+		}
+
+		v, ok := result.Vertices[id]
+		if !ok {
+			v = &WalkLogVertex{}
+			result.Vertices[id] = v
+		}
+		if e.Name != "" {
+			v.Name = e.Name
+		}
+
+		switch e.Type {
+		case WalkEventDependency:
+			v.Dependencies = append(v.Dependencies, e.Dependency)
+		case WalkEventReady:
+			v.Ready = e.Time
+		case WalkEventStarted:
+			v.Started = e.Time
+			v.SLO = e.SLO
+			result.Started = append(result.Started, id)
+		case WalkEventFinished:
+			v.Finished = e.Time
+			v.Error = e.Error
+		case WalkEventSkipped:
+			v.Skipped = true
+			v.Error = e.Error
+		case WalkEventPreempted:
+			v.Preemptions++
+		default:
+			return nil, fmt.Errorf("line %d: unknown event %q", line, e.Type)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package dagg
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWalker_eventLog(t *testing.T) {
+	var g AcyclicGraph[myint]
+	g.Add(myint(1))
+	g.Add(myint(2))
+	g.Add(myint(3))
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(BasicEdge(myint(3), myint(2)))
+
+	var buf bytes.Buffer
+	start := time.Unix(100, 0)
+	w := &Walker[myint]{
+		Reverse:  true,
+		EventLog: &buf,
+		Callback: func(v myint) error {
+			if v == 2 {
+				return fmt.Errorf("error")
+			}
+			return nil
+		},
+		now: func() time.Time { return start },
+	}
+	w.Update(&g)
+	if err := w.Wait(); err == nil {
+		t.Fatal("expect error")
+	}
+
+	l, err := ReadWalkLog(&buf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(l.Started, []string{"1", "2"}) {
+		t.Fatalf("bad started: %#v", l.Started)
+	}
+	if !reflect.DeepEqual(l.Failed(), []string{"2"}) {
+		t.Fatalf("bad failed: %#v", l.Failed())
+	}
+	if !reflect.DeepEqual(l.Skipped(), []string{"3"}) {
+		t.Fatalf("bad skipped: %#v", l.Skipped())
+	}
+	if deps := l.Vertices["2"].Dependencies; !reflect.DeepEqual(deps, []string{"1"}) {
+		t.Fatalf("bad dependencies: %#v", deps)
+	}
+
+	// events are timed by the walker's clock
+	if v := l.Vertices["1"]; !v.Started.Equal(start) || !v.Finished.Equal(start) {
+		t.Fatalf("bad times: %#v", v)
+	}
+}
+
+func TestReadWalkLog_version(t *testing.T) {
+	_, err := ReadWalkLog(strings.NewReader(`{"v":2,"event":"started","vertex":"1"}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported version 2") {
+		t.Fatalf("bad: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package dagg
+
+import "sort"
+
+// The statuses of a vertex in a WalkResult.
+const (
+	// WalkStatusSuccess is a vertex which ran without error.
+	WalkStatusSuccess = "success"
+
+	// WalkStatusFailed is a vertex which returned an error, or which
+	// BeforeStart vetoed.
+	WalkStatusFailed = "failed"
+
+	// WalkStatusSkipped is a vertex which never ran, because a dependency
+	// failed or a failure threshold was reached.
+	WalkStatusSkipped = "skipped"
+
+	// WalkStatusCancelled is a vertex which was removed by Update before it
+	// started.
+	WalkStatusCancelled = "cancelled"
+)
+
+// VertexResult is the outcome of a single vertex of a walk.
+type VertexResult[T Hashable] struct {
+	Vertex T
+	Status string
+
+	// Err is the error of a failed vertex, or the reason a vertex was
+	// skipped.
+	Err error
+
+	// Preemptions is the number of times the vertex yielded its place to a
+	// vertex with a higher priority. See Walker.Preemption.
+	Preemptions int
+}
+
+// WalkResult is the outcome of every vertex of a walk, as returned by
+// Walker.Result.
+type WalkResult[T Hashable] struct {
+	// Vertices holds the result of each vertex, by hashcode.
+	Vertices map[string]*VertexResult[T]
+}
+
+// Status returns the status of v, or "" if v wasn't part of the walk or
+// hasn't finished.
+func (r *WalkResult[T]) Status(v T) string {
+	if vr, ok := r.Vertices[v.Hashcode()]; ok {
+		return vr.Status
+	}
+	return ""
+}
+
+// WithStatus returns the vertices with the given status, sorted by
+// hashcode.
+func (r *WalkResult[T]) WithStatus(status string) []T {
+	var codes []string
+	for k, vr := range r.Vertices {
+		if vr.Status == status {
+			codes = append(codes, k)
+		}
+	}
+	sort.Strings(codes)
+
+	result := make([]T, len(codes))
+	for i, k := range codes {
+		result[i] = r.Vertices[k].Vertex
+	}
+	return result
+}
+
+// Result returns the outcome of every vertex which has finished, or been
+// cancelled, so far. It is intended to be called after Wait, so callers can
+// tell which vertices failed and which were never attempted.
+func (w *Walker[T]) Result() *WalkResult[T] {
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+
+	result := &WalkResult[T]{Vertices: make(map[string]*VertexResult[T], len(w.results))}
+	for k, vr := range w.results {
+		c := *vr
+		result.Vertices[k] = &c
+	}
+	return result
+}
+
+// recordResult records the outcome of v. The errLock must be held.
+func (w *Walker[T]) recordResult(v T, status string, err error) {
+	if w.results == nil {
+		w.results = make(map[string]*VertexResult[T])
+	}
+	w.results[v.Hashcode()] = &VertexResult[T]{
+		Vertex:      v,
+		Status:      status,
+		Err:         err,
+		Preemptions: w.preemptions[v.Hashcode()],
+	}
+}
+
+// cancelled records that v was removed before it started.
+func (w *Walker[T]) cancelled(v T) {
+	w.errLock.Lock()
+	defer w.errLock.Unlock()
+	w.recordResult(v, WalkStatusCancelled, nil)
+}
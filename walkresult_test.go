@@ -0,0 +1,65 @@
+package dagg
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWalkerResult(t *testing.T) {
+	var g AcyclicGraph[myint]
+	for i := 1; i <= 5; i++ {
+		g.Add(myint(i))
+	}
+	g.Connect(BasicEdge(myint(2), myint(1)))
+	g.Connect(BasicEdge(myint(4), myint(5)))
+
+	release := make(chan struct{})
+	cb := func(v myint) error {
+		switch v {
+		case 1:
+			return fmt.Errorf("error")
+		case 5:
+			<-release
+		}
+		return nil
+	}
+
+	w := &Walker[myint]{Callback: cb, Reverse: true}
+	w.Update(&g)
+
+	// remove 4 while it waits for 5
+	g.Remove(myint(4))
+	w.Update(&g)
+	close(release)
+
+	if err := w.Wait(); err == nil {
+		t.Fatal("expect error")
+	}
+
+	r := w.Result()
+	if len(r.Vertices) != 5 {
+		t.Fatalf("bad: %#v", r.Vertices)
+	}
+	cases := map[string][]myint{
+		WalkStatusSuccess:   {3, 5},
+		WalkStatusFailed:    {1},
+		WalkStatusSkipped:   {2},
+		WalkStatusCancelled: {4},
+	}
+	for status, expected := range cases {
+		if actual := r.WithStatus(status); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("%s: bad: %#v", status, actual)
+		}
+	}
+
+	if r.Status(myint(2)) != WalkStatusSkipped || r.Vertices["2"].Err == nil {
+		t.Fatalf("bad: %#v", r.Vertices["2"])
+	}
+	if r.Vertices["1"].Err.Error() != "error" {
+		t.Fatalf("bad: %s", r.Vertices["1"].Err)
+	}
+	if r.Status(myint(6)) != "" {
+		t.Fatal("expect no status")
+	}
+}
@@ -0,0 +1,158 @@
+package dagg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// WorkflowSpec is a declarative description of a workflow: a list of tasks
+// and their dependencies. LoadWorkflow only reads JSON; other formats, such
+// as YAML, must be decoded into a WorkflowSpec by the caller with a decoder
+// which honors the json struct tags, and then compiled.
+type WorkflowSpec struct {
+	Tasks []TaskSpec `json:"tasks"`
+}
+
+// TaskSpec is the declarative description of a single Task.
+type TaskSpec struct {
+	Name      string   `json:"name"`
+	DependsOn []string `json:"depends_on,omitempty"`
+
+	// Retries is the number of times the task may be retried after failing.
+	// It may not be negative.
+	Retries int `json:"retries,omitempty"`
+
+	// Timeout is a duration string as accepted by time.ParseDuration. It may
+	// not be negative.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Task is a vertex of a compiled workflow graph. Walkers of the graph
+// should use a WorkflowCallback, which applies the Retries and Timeout of
+// each task.
+type Task struct {
+	Name      string
+	DependsOn []string
+	Retries   int
+	Timeout   time.Duration
+}
+
+func (t *Task) Hashcode() string {
+	return t.Name
+}
+
+// TaskFunc runs a single attempt at a task. The context is cancelled once
+// the Timeout of the task has passed.
+type TaskFunc func(ctx context.Context, t *Task) error
+
+// WorkflowCallback returns the callback to walk a compiled workflow with,
+// which runs each task with fn. A task which fails is attempted again up to
+// its Retries, and each attempt fails if it takes longer than the Timeout
+// of the task, whether or not fn returns. The error of the last attempt is
+// the error of the task.
+func WorkflowCallback(fn TaskFunc) WalkFunc[*Task] {
+	return func(t *Task) error {
+		var err error
+		for attempt := 0; attempt <= t.Retries; attempt++ {
+			if err = t.attempt(fn); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+}
+
+// attempt runs fn for the task once, within its Timeout.
+func (t *Task) attempt(fn TaskFunc) error {
+	if t.Timeout <= 0 {
+		return fn(context.Background(), t)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.Timeout)
+	defer cancel()
+
+	// fn may not watch the context, so the attempt doesn't wait for it
+	// once the timeout has passed
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn(ctx, t)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("task %q timed out after %s", t.Name, t.Timeout)
+	}
+}
+
+// LoadWorkflow decodes a JSON WorkflowSpec from r and compiles it.
+func LoadWorkflow(r io.Reader) (*AcyclicGraph[*Task], error) {
+	var spec WorkflowSpec
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid workflow: %w", err)
+	}
+	return spec.Compile()
+}
+
+// Compile builds the workflow graph. Every task depends on the tasks listed
+// in its DependsOn, so each task has an edge to each of its dependencies.
+// The resulting graph is validated before it is returned.
+func (spec *WorkflowSpec) Compile() (*AcyclicGraph[*Task], error) {
+	g := &AcyclicGraph[*Task]{}
+	tasks := make(map[string]*Task, len(spec.Tasks))
+
+	for _, ts := range spec.Tasks {
+		if ts.Name == "" {
+			return nil, fmt.Errorf("task with no name")
+		}
+		if _, ok := tasks[ts.Name]; ok {
+			return nil, fmt.Errorf("duplicate task %q", ts.Name)
+		}
+		if ts.Retries < 0 {
+			return nil, fmt.Errorf("task %q: negative retries %d", ts.Name, ts.Retries)
+		}
+
+		t := &Task{
+			Name:      ts.Name,
+			DependsOn: ts.DependsOn,
+			Retries:   ts.Retries,
+		}
+		if ts.Timeout != "" {
+			timeout, err := time.ParseDuration(ts.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("task %q: invalid timeout: %w", ts.Name, err)
+			}
+			if timeout < 0 {
+				return nil, fmt.Errorf("task %q: negative timeout %s", ts.Name, timeout)
+			}
+			t.Timeout = timeout
+		}
+
+		tasks[t.Name] = t
+		g.Add(t)
+	}
+
+	for _, t := range tasks {
+		for _, name := range t.DependsOn {
+			dep, ok := tasks[name]
+			if !ok {
+				return nil, fmt.Errorf("task %q depends on unknown task %q", t.Name, name)
+			}
+			if err := g.Connect(BasicEdge(t, dep)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
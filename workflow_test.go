@@ -0,0 +1,120 @@
+package dagg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadWorkflow(t *testing.T) {
+	g, err := LoadWorkflow(strings.NewReader(testWorkflowStr))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testWorkflowGraphStr)
+	if actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	for _, v := range g.Vertices() {
+		if v.Name != "test" {
+			continue
+		}
+		if v.Retries != 2 || v.Timeout != 5*time.Minute {
+			t.Fatalf("bad task: %#v", v)
+		}
+	}
+}
+
+func TestLoadWorkflow_invalid(t *testing.T) {
+	cases := map[string]string{
+		"unknown dep":      `{"tasks": [{"name": "a", "depends_on": ["b"]}]}`,
+		"duplicate":        `{"tasks": [{"name": "a"}, {"name": "a"}]}`,
+		"no name":          `{"tasks": [{"depends_on": ["a"]}]}`,
+		"timeout":          `{"tasks": [{"name": "a", "timeout": "soon"}]}`,
+		"negative timeout": `{"tasks": [{"name": "a", "timeout": "-1s"}]}`,
+		"negative retries": `{"tasks": [{"name": "a", "retries": -1}]}`,
+		"cycle":            `{"tasks": [{"name": "a", "depends_on": ["b"]}, {"name": "b", "depends_on": ["a"]}]}`,
+		"field":            `{"tasks": [{"name": "a", "dependson": ["b"]}]}`,
+	}
+
+	for name, spec := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadWorkflow(strings.NewReader(spec)); err == nil {
+				t.Fatal("should error")
+			}
+		})
+	}
+}
+
+func TestWorkflowCallback(t *testing.T) {
+	spec := &WorkflowSpec{Tasks: []TaskSpec{
+		{Name: "build", Retries: 2},
+		{Name: "test", DependsOn: []string{"build"}, Timeout: "10ms"},
+		{Name: "flaky", Retries: 1},
+	}}
+	g, err := spec.Compile()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var lock sync.Mutex
+	attempts := make(map[string]int)
+	err = g.Walk(WorkflowCallback(func(ctx context.Context, task *Task) error {
+		lock.Lock()
+		attempts[task.Name]++
+		n := attempts[task.Name]
+		lock.Unlock()
+
+		switch task.Name {
+		case "build":
+			// succeeds on the last retry
+			if n < 3 {
+				return fmt.Errorf("attempt %d", n)
+			}
+		case "test":
+			// ignores its context, so it must be timed out
+			time.Sleep(time.Second)
+		case "flaky":
+			return fmt.Errorf("attempt %d", n)
+		}
+		return nil
+	}))
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	if !strings.Contains(err.Error(), `task "test" timed out after 10ms`) {
+		t.Fatalf("bad: %s", err)
+	}
+	if !strings.Contains(err.Error(), "attempt 2") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if attempts["build"] != 3 || attempts["test"] != 1 || attempts["flaky"] != 2 {
+		t.Fatalf("bad: %#v", attempts)
+	}
+}
+
+const testWorkflowStr = `{
+	"tasks": [
+		{"name": "build"},
+		{"name": "test", "depends_on": ["build"], "retries": 2, "timeout": "5m"},
+		{"name": "deploy", "depends_on": ["build", "test"]}
+	]
+}`
+
+const testWorkflowGraphStr = `
+build
+deploy
+  build
+  test
+test
+  build
+`